@@ -0,0 +1,147 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+type Format string
+
+const (
+	FormatTar     Format = "tar"
+	FormatTarZstd Format = "tar.zst"
+	FormatZip     Format = "zip"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported archive format")
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatTar, FormatTarZstd, FormatZip:
+		return f, nil
+	default:
+		return "", errors.Wrapf(ErrUnsupportedFormat, "`%s`", s)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatTarZstd:
+		return "application/zstd"
+	case FormatZip:
+		return "application/zip"
+	default:
+		return "application/x-tar"
+	}
+}
+
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+type File struct {
+	Name    string
+	Size    uint64
+	ModTime time.Time
+}
+
+// Writer writes files to the archive one by one so the archive could be
+// streamed without staging it anywhere
+type Writer interface {
+	// WriteFile writes the file with exactly f.Size bytes read from rd
+	WriteFile(f File, rd io.Reader) error
+	// Close finalizes the archive, it doesn't close the underlying writer
+	Close() error
+}
+
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatTar:
+		return &tarWriter{tw: tar.NewWriter(w)}, nil
+	case FormatTarZstd:
+		// single goroutine keeps the output the same regardless of the
+		// number of CPUs and limits memory used per archive
+		enc, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Wrap(err, "error initializing zstd encoder")
+		}
+		return &tarWriter{tw: tar.NewWriter(enc), enc: enc}, nil
+	case FormatZip:
+		return &zipWriter{zw: zip.NewWriter(w)}, nil
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedFormat, "`%s`", format)
+	}
+}
+
+type tarWriter struct {
+	tw  *tar.Writer
+	enc *zstd.Encoder
+}
+
+func (w *tarWriter) WriteFile(f File, rd io.Reader) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     f.Name,
+		Size:     int64(f.Size),
+		Mode:     0o644,
+		ModTime:  f.ModTime.UTC().Truncate(time.Second),
+		Format:   tar.FormatPAX,
+	}); err != nil {
+		return errors.Wrapf(err, "error writing header for `%s`", f.Name)
+	}
+
+	return copyFile(w.tw, f, rd)
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return errors.Wrap(err, "error closing tar writer")
+	}
+
+	if w.enc != nil {
+		if err := w.enc.Close(); err != nil {
+			return errors.Wrap(err, "error closing zstd encoder")
+		}
+	}
+	return nil
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) WriteFile(f File, rd io.Reader) error {
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     f.Name,
+		Method:   zip.Deflate,
+		Modified: f.ModTime.UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error writing header for `%s`", f.Name)
+	}
+
+	return copyFile(fw, f, rd)
+}
+
+func (w *zipWriter) Close() error {
+	if err := w.zw.Close(); err != nil {
+		return errors.Wrap(err, "error closing zip writer")
+	}
+	return nil
+}
+
+func copyFile(w io.Writer, f File, rd io.Reader) error {
+	n, err := io.CopyN(w, rd, int64(f.Size))
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.Errorf("unexpected end of data for `%s`: %d bytes of %d read", f.Name, n, f.Size)
+		}
+		return errors.Wrapf(err, "error writing data for `%s`", f.Name)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+var testFiles = []struct {
+	file File
+	data string
+}{
+	{File{Name: "dir/file1.txt", Size: 9, ModTime: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)}, "test data"},
+	{File{Name: "empty.txt", Size: 0, ModTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, ""},
+}
+
+func TestTar(t *testing.T) {
+	buf := writeArchive(t, FormatTar)
+	checkTar(t, buf)
+}
+
+func TestTarZstd(t *testing.T) {
+	r := require.New(t)
+
+	buf := writeArchive(t, FormatTarZstd)
+
+	dec, err := zstd.NewReader(buf)
+	r.NoError(err)
+	defer dec.Close()
+
+	checkTar(t, dec)
+}
+
+func TestZip(t *testing.T) {
+	r := require.New(t)
+
+	buf := writeArchive(t, FormatZip)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	r.NoError(err)
+	r.Len(zr.File, len(testFiles))
+
+	for i, f := range zr.File {
+		r.Equal(testFiles[i].file.Name, f.Name)
+		r.Equal(testFiles[i].file.ModTime.Truncate(time.Second), f.Modified.UTC())
+
+		rc, err := f.Open()
+		r.NoError(err)
+
+		data, err := io.ReadAll(rc)
+		r.NoError(err)
+		r.NoError(rc.Close())
+		r.Equal(testFiles[i].data, string(data))
+	}
+}
+
+func TestShortData(t *testing.T) {
+	r := require.New(t)
+
+	for _, format := range []Format{FormatTar, FormatTarZstd, FormatZip} {
+		w, err := NewWriter(io.Discard, format)
+		r.NoError(err)
+
+		err = w.WriteFile(File{Name: "file.txt", Size: 10}, strings.NewReader("test"))
+		r.Error(err)
+		r.Equal("unexpected end of data for `file.txt`: 4 bytes of 10 read", err.Error())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	r := require.New(t)
+
+	f, err := ParseFormat("tar.zst")
+	r.NoError(err)
+	r.Equal(FormatTarZstd, f)
+	r.Equal(".tar.zst", f.Extension())
+	r.Equal("application/zstd", f.ContentType())
+
+	_, err = ParseFormat("rar")
+	r.Error(err)
+	r.ErrorIs(err, ErrUnsupportedFormat)
+}
+
+func writeArchive(t *testing.T, format Format) *bytes.Buffer {
+	r := require.New(t)
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, format)
+	r.NoError(err)
+
+	for _, f := range testFiles {
+		err := w.WriteFile(f.file, strings.NewReader(f.data))
+		r.NoError(err)
+	}
+
+	r.NoError(w.Close())
+	return buf
+}
+
+func checkTar(t *testing.T, rd io.Reader) {
+	r := require.New(t)
+
+	tr := tar.NewReader(rd)
+	for _, f := range testFiles {
+		hdr, err := tr.Next()
+		r.NoError(err)
+		r.Equal(f.file.Name, hdr.Name)
+		r.Equal(int64(f.file.Size), hdr.Size)
+		r.Equal(f.file.ModTime.Truncate(time.Second), hdr.ModTime.UTC())
+
+		data, err := io.ReadAll(tr)
+		r.NoError(err)
+		r.Equal(f.data, string(data))
+	}
+
+	_, err := tr.Next()
+	r.Equal(io.EOF, err)
+}
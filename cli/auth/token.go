@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+var _ credentials.PerRPCCredentials = (*token)(nil)
+
+type token struct {
+	token      string
+	requireTLS bool
+}
+
+func NewTokenCredentials(t string, requireTLS bool) credentials.PerRPCCredentials {
+	return &token{
+		token:      t,
+		requireTLS: requireTLS,
+	}
+}
+
+func (t *token) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{
+		"authorization": "Bearer " + t.token,
+	}, nil
+}
+
+func (t *token) RequireTransportSecurity() bool {
+	return t.requireTLS
+}
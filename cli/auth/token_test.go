@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCredentials(t *testing.T) {
+	r := require.New(t)
+
+	creds := NewTokenCredentials("test-token", true)
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	r.NoError(err)
+	r.Equal(map[string]string{
+		"authorization": "Bearer test-token",
+	}, md)
+	r.True(creds.RequireTransportSecurity())
+
+	r.False(NewTokenCredentials("test-token", false).RequireTransportSecurity())
+}
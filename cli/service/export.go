@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/archive"
+	v1proto "github.com/teran/archived/manager/presenter/grpc/proto/v1"
+)
+
+const exportUserAgent = "archived-cli/export (+https://github.com/teran/archived)"
+
+func (s *service) ExportVersion(namespaceName, containerName, versionID, format, output string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		f, err := archive.ParseFormat(format)
+		if err != nil {
+			return err
+		}
+
+		if output == "" {
+			output = containerName + "-" + versionID + f.Extension()
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "-" {
+			fp, err := os.Create(output)
+			if err != nil {
+				return errors.Wrap(err, "error creating output file")
+			}
+			defer func() { _ = fp.Close() }()
+
+			w = fp
+		}
+
+		if err := s.exportVersion(ctx, w, f, namespaceName, containerName, versionID); err != nil {
+			if output != "-" {
+				_ = os.Remove(output)
+			}
+			return err
+		}
+
+		if output != "-" {
+			fmt.Printf("version `%s` exported to `%s`\n", versionID, output)
+		}
+		return nil
+	}
+}
+
+func (s *service) exportVersion(ctx context.Context, w io.Writer, format archive.Format, namespaceName, containerName, versionID string) error {
+	resp, err := s.cli.ListObjects(ctx, &v1proto.ListObjectsRequest{
+		Namespace: namespaceName,
+		Container: containerName,
+		Version:   versionID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error listing objects")
+	}
+
+	aw, err := archive.NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range resp.GetObjectDetails() {
+		if err := s.exportObject(ctx, aw, namespaceName, containerName, versionID, object); err != nil {
+			return errors.Wrapf(err, "error exporting object `%s`", object.GetKey())
+		}
+	}
+
+	return aw.Close()
+}
+
+func (s *service) exportObject(ctx context.Context, aw archive.Writer, namespaceName, containerName, versionID string, object *v1proto.Object) error {
+	urlResp, err := s.cli.GetObjectURL(ctx, &v1proto.GetObjectURLRequest{
+		Namespace: namespaceName,
+		Container: containerName,
+		Version:   versionID,
+		Key:       object.GetKey(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error getting object URL")
+	}
+
+	log.WithFields(log.Fields{
+		"key":    object.GetKey(),
+		"length": object.GetSize(),
+	}).Debug("downloading object ...")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlResp.GetUrl(), nil)
+	if err != nil {
+		return errors.Wrap(err, "error creating request object")
+	}
+
+	req.Header.Set("User-Agent", exportUserAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "error performing HTTP request")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode > 299 {
+		return errors.Errorf("unexpected HTTP response status: %s", resp.Status)
+	}
+
+	h := sha256.New()
+	if err := aw.WriteFile(archive.File{
+		Name:    object.GetKey(),
+		Size:    object.GetSize(),
+		ModTime: object.GetCreatedAt().AsTime(),
+	}, io.TeeReader(resp.Body, h)); err != nil {
+		return err
+	}
+
+	if checksum := hex.EncodeToString(h.Sum(nil)); checksum != object.GetChecksum() {
+		return errors.Errorf("checksum mismatch: expected %s, got %s", object.GetChecksum(), checksum)
+	}
+	return nil
+}
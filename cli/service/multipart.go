@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/cli/service/source"
+	v1proto "github.com/teran/archived/manager/presenter/grpc/proto/v1"
+)
+
+const (
+	// multipartUploadThreshold is the object size starting from which
+	// objects are uploaded in parts
+	multipartUploadThreshold uint64 = 128 << 20
+
+	uploadPartAttempts      = 3
+	uploadPartRetryInterval = time.Second
+)
+
+func (s *service) uploadMultipart(ctx context.Context, namespaceName string, object source.Object) error {
+	resp, err := s.cli.CreateMultipartUpload(ctx, &v1proto.CreateMultipartUploadRequest{
+		Namespace: namespaceName,
+		Checksum:  object.SHA256,
+		Size:      object.Size,
+	})
+	if err != nil {
+		// Error is returned as is to let caller check the status code
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"path":      object.Path,
+		"sha256":    object.SHA256,
+		"length":    object.Size,
+		"upload_id": resp.GetUploadId(),
+		"parts":     len(resp.GetPartUrls()),
+	}).Debug("uploading BLOB in parts ...")
+
+	parts, err := func() ([]*v1proto.UploadPart, error) {
+		rd, err := object.Contents(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting contents reader")
+		}
+
+		if c, ok := rd.(io.Closer); ok {
+			defer func() { _ = c.Close() }()
+		}
+
+		return uploadParts(ctx, rd, object.Size, resp.GetPartSize(), resp.GetPartUrls())
+	}()
+	if err != nil {
+		s.abortMultipartUpload(ctx, namespaceName, object.SHA256, resp.GetUploadId())
+		return err
+	}
+
+	_, err = s.cli.CompleteMultipartUpload(ctx, &v1proto.CompleteMultipartUploadRequest{
+		Namespace: namespaceName,
+		Checksum:  object.SHA256,
+		UploadId:  resp.GetUploadId(),
+		Parts:     parts,
+	})
+	if err != nil {
+		s.abortMultipartUpload(ctx, namespaceName, object.SHA256, resp.GetUploadId())
+		return errors.Wrap(err, "error completing multipart upload")
+	}
+	return nil
+}
+
+func (s *service) abortMultipartUpload(ctx context.Context, namespaceName, checksum, uploadID string) {
+	// Upload is aborted even if the operation was cancelled to not leave
+	// uploaded parts in storage
+	_, err := s.cli.AbortMultipartUpload(context.WithoutCancel(ctx), &v1proto.AbortMultipartUploadRequest{
+		Namespace: namespaceName,
+		Checksum:  checksum,
+		UploadId:  uploadID,
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"sha256":    checksum,
+			"upload_id": uploadID,
+			"error":     err,
+		}).Warn("error aborting multipart upload")
+	}
+}
+
+func uploadParts(ctx context.Context, rd io.Reader, size, partSize uint64, urls []string) ([]*v1proto.UploadPart, error) {
+	if partSize == 0 || uint64(len(urls))*partSize < size {
+		return nil, errors.Errorf("%d part(s) of %d bytes don't fit BLOB of %d bytes", len(urls), partSize, size)
+	}
+
+	// Parts are read right from the file when it's possible, otherwise
+	// they're buffered to be able to retry the upload
+	ra, isReaderAt := rd.(io.ReaderAt)
+	var buf []byte
+	if !isReaderAt {
+		buf = make([]byte, partSize)
+	}
+
+	parts := []*v1proto.UploadPart{}
+	for i, url := range urls {
+		offset := uint64(i) * partSize
+		if offset >= size {
+			break
+		}
+		length := min(partSize, size-offset)
+
+		var part *io.SectionReader
+		if isReaderAt {
+			part = io.NewSectionReader(ra, int64(offset), int64(length))
+		} else {
+			if _, err := io.ReadFull(rd, buf[:length]); err != nil {
+				return nil, errors.Wrap(err, "error reading part data")
+			}
+			part = io.NewSectionReader(bytes.NewReader(buf[:length]), 0, int64(length))
+		}
+
+		etag, err := uploadPart(ctx, url, part)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error uploading part %d", i+1)
+		}
+
+		parts = append(parts, &v1proto.UploadPart{
+			Number: int32(i + 1),
+			Etag:   etag,
+		})
+	}
+	return parts, nil
+}
+
+func uploadPart(ctx context.Context, url string, part *io.SectionReader) (string, error) {
+	for attempt := 1; ; attempt++ {
+		hdr, err := putBLOB(ctx, url, io.NewSectionReader(part, 0, part.Size()), part.Size())
+		if err == nil {
+			etag := hdr.Get("ETag")
+			if etag == "" {
+				return "", errors.New("no ETag returned for uploaded part")
+			}
+			return etag, nil
+		}
+
+		if attempt >= uploadPartAttempts || ctx.Err() != nil {
+			return "", err
+		}
+
+		log.WithFields(log.Fields{
+			"url":     url,
+			"attempt": attempt,
+			"error":   err,
+		}).Warn("error uploading part, retrying ...")
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Duration(attempt) * uploadPartRetryInterval):
+		}
+	}
+}
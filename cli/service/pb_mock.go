@@ -5,6 +5,7 @@ import (
 	"time"
 
 	v1proto "github.com/teran/archived/manager/presenter/grpc/proto/v1"
+	"github.com/teran/archived/models"
 	"github.com/teran/archived/repositories/blob/mock"
 	"google.golang.org/grpc"
 )
@@ -34,6 +35,11 @@ func (m *protoClientMock) DeleteNamespace(ctx context.Context, in *v1proto.Delet
 	return &v1proto.DeleteNamespaceResponse{}, args.Error(0)
 }
 
+func (m *protoClientMock) SetNamespaceParameters(ctx context.Context, in *v1proto.SetNamespaceParametersRequest, opts ...grpc.CallOption) (*v1proto.SetNamespaceParametersResponse, error) {
+	args := m.Called(in.GetName(), in.GetIsPrivate())
+	return &v1proto.SetNamespaceParametersResponse{}, args.Error(0)
+}
+
 func (m *protoClientMock) ListNamespaces(ctx context.Context, in *v1proto.ListNamespacesRequest, opts ...grpc.CallOption) (*v1proto.ListNamespacesResponse, error) {
 	args := m.Called()
 	return &v1proto.ListNamespacesResponse{
@@ -62,7 +68,17 @@ func (m *protoClientMock) DeleteContainer(ctx context.Context, in *v1proto.Delet
 }
 
 func (m *protoClientMock) SetContainerParameters(ctx context.Context, in *v1proto.SetContainerParametersRequest, opts ...grpc.CallOption) (*v1proto.SetContainerParametersResponse, error) {
-	args := m.Called(in.GetNamespace(), in.GetName(), time.Duration(in.GetTtlSeconds())*time.Second)
+	var retention *models.RetentionPolicy
+	if rp := in.GetRetentionPolicy(); rp != nil {
+		retention = &models.RetentionPolicy{
+			KeepLast:    rp.GetKeepLast(),
+			KeepDaily:   rp.GetKeepDaily(),
+			KeepWeekly:  rp.GetKeepWeekly(),
+			KeepMonthly: rp.GetKeepMonthly(),
+		}
+	}
+
+	args := m.Called(in.GetNamespace(), in.GetName(), time.Duration(in.GetTtlSeconds())*time.Second, retention)
 	return &v1proto.SetContainerParametersResponse{}, args.Error(0)
 }
 
@@ -87,6 +103,18 @@ func (m *protoClientMock) ListVersions(ctx context.Context, in *v1proto.ListVers
 	}, args.Error(1)
 }
 
+func (m *protoClientMock) DiffVersions(ctx context.Context, in *v1proto.DiffVersionsRequest, opts ...grpc.CallOption) (*v1proto.DiffVersionsResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetFromVersion(), in.GetToVersion())
+	return args.Get(0).(*v1proto.DiffVersionsResponse), args.Error(1)
+}
+
+func (m *protoClientMock) CloneVersion(ctx context.Context, in *v1proto.CloneVersionRequest, opts ...grpc.CallOption) (*v1proto.CloneVersionResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion(), in.GetDestinationNamespace(), in.GetDestinationContainer())
+	return &v1proto.CloneVersionResponse{
+		Version: args.String(0),
+	}, args.Error(1)
+}
+
 func (m *protoClientMock) DeleteVersion(ctx context.Context, in *v1proto.DeleteVersionRequest, opts ...grpc.CallOption) (*v1proto.DeleteVersionResponse, error) {
 	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion())
 	return &v1proto.DeleteVersionResponse{}, args.Error(0)
@@ -97,6 +125,38 @@ func (m *protoClientMock) PublishVersion(ctx context.Context, in *v1proto.Publis
 	return &v1proto.PublishVersionResponse{}, args.Error(0)
 }
 
+func (m *protoClientMock) ProtectVersion(ctx context.Context, in *v1proto.ProtectVersionRequest, opts ...grpc.CallOption) (*v1proto.ProtectVersionResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion())
+	return &v1proto.ProtectVersionResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) UnprotectVersion(ctx context.Context, in *v1proto.UnprotectVersionRequest, opts ...grpc.CallOption) (*v1proto.UnprotectVersionResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion())
+	return &v1proto.UnprotectVersionResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) CreateAlias(ctx context.Context, in *v1proto.CreateAliasRequest, opts ...grpc.CallOption) (*v1proto.CreateAliasResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetName(), in.GetVersion())
+	return &v1proto.CreateAliasResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) MoveAlias(ctx context.Context, in *v1proto.MoveAliasRequest, opts ...grpc.CallOption) (*v1proto.MoveAliasResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetName(), in.GetVersion())
+	return &v1proto.MoveAliasResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) DeleteAlias(ctx context.Context, in *v1proto.DeleteAliasRequest, opts ...grpc.CallOption) (*v1proto.DeleteAliasResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetName())
+	return &v1proto.DeleteAliasResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) ListAliases(ctx context.Context, in *v1proto.ListAliasesRequest, opts ...grpc.CallOption) (*v1proto.ListAliasesResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer())
+	return &v1proto.ListAliasesResponse{
+		Aliases: args.Get(0).([]*v1proto.Alias),
+	}, args.Error(1)
+}
+
 func (m *protoClientMock) CreateObject(ctx context.Context, in *v1proto.CreateObjectRequest, opts ...grpc.CallOption) (*v1proto.CreateObjectResponse, error) {
 	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion(), in.GetKey(), in.GetChecksum(), in.GetSize())
 	return &v1proto.CreateObjectResponse{
@@ -104,13 +164,74 @@ func (m *protoClientMock) CreateObject(ctx context.Context, in *v1proto.CreateOb
 	}, args.Error(1)
 }
 
-func (m *protoClientMock) ListObjects(ctx context.Context, in *v1proto.ListObjectsRequest, opts ...grpc.CallOption) (*v1proto.ListObjectsResponse, error) {
+func (m *protoClientMock) CreateObjects(ctx context.Context, in *v1proto.CreateObjectsRequest, opts ...grpc.CallOption) (*v1proto.CreateObjectsResponse, error) {
+	keys := []string{}
+	for _, o := range in.GetObjects() {
+		keys = append(keys, o.GetKey())
+	}
+
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion(), keys)
+	return &v1proto.CreateObjectsResponse{
+		UploadUrls: args.Get(0).([]*v1proto.UploadURL),
+	}, args.Error(1)
+}
+
+func (m *protoClientMock) ConfirmBLOB(ctx context.Context, in *v1proto.ConfirmBLOBRequest, opts ...grpc.CallOption) (*v1proto.ConfirmBLOBResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetChecksum())
+	return &v1proto.ConfirmBLOBResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) VerifyBLOBs(ctx context.Context, in *v1proto.VerifyBLOBsRequest, opts ...grpc.CallOption) (*v1proto.VerifyBLOBsResponse, error) {
 	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion())
-	return &v1proto.ListObjectsResponse{
-		Objects: args.Get(0).([]string),
+	return &v1proto.VerifyBLOBsResponse{
+		UploadUrls: args.Get(0).([]*v1proto.UploadURL),
 	}, args.Error(1)
 }
 
+func (m *protoClientMock) CreateMultipartUpload(ctx context.Context, in *v1proto.CreateMultipartUploadRequest, opts ...grpc.CallOption) (*v1proto.CreateMultipartUploadResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetChecksum(), in.GetSize())
+	return &v1proto.CreateMultipartUploadResponse{
+		UploadId: args.String(0),
+		PartSize: args.Get(1).(uint64),
+		PartUrls: args.Get(2).([]string),
+	}, args.Error(3)
+}
+
+func (m *protoClientMock) CompleteMultipartUpload(ctx context.Context, in *v1proto.CompleteMultipartUploadRequest, opts ...grpc.CallOption) (*v1proto.CompleteMultipartUploadResponse, error) {
+	etags := []string{}
+	for _, p := range in.GetParts() {
+		etags = append(etags, p.GetEtag())
+	}
+
+	args := m.Called(in.GetNamespace(), in.GetChecksum(), in.GetUploadId(), etags)
+	return &v1proto.CompleteMultipartUploadResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) AbortMultipartUpload(ctx context.Context, in *v1proto.AbortMultipartUploadRequest, opts ...grpc.CallOption) (*v1proto.AbortMultipartUploadResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetChecksum(), in.GetUploadId())
+	return &v1proto.AbortMultipartUploadResponse{}, args.Error(0)
+}
+
+func (m *protoClientMock) ListObjects(ctx context.Context, in *v1proto.ListObjectsRequest, opts ...grpc.CallOption) (*v1proto.ListObjectsResponse, error) {
+	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion())
+	objects := args.Get(0).([]string)
+
+	resp := &v1proto.ListObjectsResponse{
+		Objects: objects,
+	}
+
+	// Object details could be optionally specified as the third return value
+	if len(args) > 2 {
+		resp.ObjectDetails = args.Get(2).([]*v1proto.Object)
+		return resp, args.Error(1)
+	}
+
+	for _, key := range objects {
+		resp.ObjectDetails = append(resp.ObjectDetails, &v1proto.Object{Key: key})
+	}
+	return resp, args.Error(1)
+}
+
 func (m *protoClientMock) GetObjectURL(ctx context.Context, in *v1proto.GetObjectURLRequest, opts ...grpc.CallOption) (*v1proto.GetObjectURLResponse, error) {
 	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion(), in.GetKey())
 	return &v1proto.GetObjectURLResponse{
@@ -122,3 +243,10 @@ func (m *protoClientMock) DeleteObject(_ context.Context, in *v1proto.DeleteObje
 	args := m.Called(in.GetNamespace(), in.GetContainer(), in.GetVersion(), in.GetKey())
 	return &v1proto.DeleteObjectResponse{}, args.Error(0)
 }
+
+func (m *protoClientMock) ListAuditEvents(ctx context.Context, in *v1proto.ListAuditEventsRequest, opts ...grpc.CallOption) (*v1proto.ListAuditEventsResponse, error) {
+	args := m.Called(in.GetActor(), in.GetOperation(), in.GetNamespace(), in.GetLimit())
+	return &v1proto.ListAuditEventsResponse{
+		Events: args.Get(0).([]*v1proto.AuditEvent),
+	}, args.Error(1)
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,41 +16,66 @@ import (
 	"github.com/teran/archived/cli/service/source"
 	cache "github.com/teran/archived/cli/service/stat_cache"
 	v1proto "github.com/teran/archived/manager/presenter/grpc/proto/v1"
+	"github.com/teran/archived/models"
 	"github.com/teran/go-collection/types/ptr"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// createObjectsBatchSize is the amount of objects registered per request.
+// Objects are retained until their batch is flushed and sources could hold
+// downloaded data for each of them so it's kept moderate.
+const createObjectsBatchSize = 100
+
 type Service interface {
 	CreateNamespace(namespaceName string) func(ctx context.Context) error
 	RenameNamespace(oldName, newName string) func(ctx context.Context) error
 	ListNamespaces() func(ctx context.Context) error
 	DeleteNamespace(namespaceName string) func(ctx context.Context) error
+	SetNamespaceParameters(namespaceName string, isPrivate bool) func(ctx context.Context) error
 
 	CreateContainer(namespaceName, containerName string, ttl time.Duration) func(ctx context.Context) error
 	MoveContainer(namespaceName, containerName, destinationNamespace string) func(ctx context.Context) error
 	RenameContainer(namespaceName, oldName, newName string) func(ctx context.Context) error
 	ListContainers(namespaceName string) func(ctx context.Context) error
 	DeleteContainer(namespaceName, containerName string) func(ctx context.Context) error
-	SetContainerParameters(namespaceName, containerName string, ttl time.Duration) func(ctx context.Context) error
+	SetContainerParameters(namespaceName, containerName string, ttl time.Duration, retention *models.RetentionPolicy) func(ctx context.Context) error
 
-	CreateVersion(namespaceName, containerName string, shouldPublish bool, src source.Source) func(ctx context.Context) error
+	CreateVersion(namespaceName, containerName string, shouldPublish bool, src source.Source, concurrency int, resumeVersionID string) func(ctx context.Context) error
+	CloneVersion(namespaceName, containerName, versionID, destNamespaceName, destContainerName string) func(ctx context.Context) error
 	DeleteVersion(namespaceName, containerName, versionID string) func(ctx context.Context) error
 	ListVersions(namespaceName, containerName string) func(ctx context.Context) error
 	PublishVersion(namespaceName, containerName, versionID string) func(ctx context.Context) error
+	ProtectVersion(namespaceName, containerName, versionID string) func(ctx context.Context) error
+	UnprotectVersion(namespaceName, containerName, versionID string) func(ctx context.Context) error
+	DiffVersions(namespaceName, containerName, fromVersionID, toVersionID string) func(ctx context.Context) error
+	ExportVersion(namespaceName, containerName, versionID, format, output string) func(ctx context.Context) error
+
+	CreateAlias(namespaceName, containerName, aliasName, versionID string) func(ctx context.Context) error
+	MoveAlias(namespaceName, containerName, aliasName, versionID string) func(ctx context.Context) error
+	DeleteAlias(namespaceName, containerName, aliasName string) func(ctx context.Context) error
+	ListAliases(namespaceName, containerName string) func(ctx context.Context) error
 
-	ListObjects(namespaceName, containerName, versionID string) func(ctx context.Context) error
+	ListObjects(namespaceName, containerName, versionID string, long bool) func(ctx context.Context) error
 	GetObjectURL(namespaceName, containerName, versionID, objectKey string) func(ctx context.Context) error
 	DeleteObject(namespaceName, containerName, versionID, objectKey string) func(ctx context.Context) error
+
+	ListAuditEvents(actor, operation, namespaceName string, since time.Duration, limit uint64) func(ctx context.Context) error
 }
 
 type service struct {
-	cache cache.CacheRepository
-	cli   v1proto.ManageServiceClient
+	cache              cache.CacheRepository
+	cli                v1proto.ManageServiceClient
+	multipartThreshold uint64
 }
 
 func New(cli v1proto.ManageServiceClient, cacheRepo cache.CacheRepository) Service {
 	return &service{
-		cache: cacheRepo,
-		cli:   cli,
+		cache:              cacheRepo,
+		cli:                cli,
+		multipartThreshold: multipartUploadThreshold,
 	}
 }
 
@@ -106,6 +134,26 @@ func (s *service) DeleteNamespace(namespaceName string) func(ctx context.Context
 	}
 }
 
+func (s *service) SetNamespaceParameters(namespaceName string, isPrivate bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := s.cli.SetNamespaceParameters(ctx, &v1proto.SetNamespaceParametersRequest{
+			Name:      namespaceName,
+			IsPrivate: isPrivate,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error setting namespace parameters")
+		}
+
+		visibility := "public"
+		if isPrivate {
+			visibility = "private"
+		}
+
+		fmt.Printf("namespace `%s` is now %s\n", namespaceName, visibility)
+		return nil
+	}
+}
+
 func (s *service) CreateContainer(namespaceName, containerName string, ttl time.Duration) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
 		_, err := s.cli.CreateContainer(ctx, &v1proto.CreateContainerRequest{
@@ -182,45 +230,186 @@ func (s *service) DeleteContainer(namespaceName, containerName string) func(ctx
 	}
 }
 
-func (s *service) SetContainerParameters(namespaceName, containerName string, ttl time.Duration) func(ctx context.Context) error {
+func (s *service) SetContainerParameters(namespaceName, containerName string, ttl time.Duration, retention *models.RetentionPolicy) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
-		_, err := s.cli.SetContainerParameters(ctx, &v1proto.SetContainerParametersRequest{
+		req := &v1proto.SetContainerParametersRequest{
 			Namespace:  namespaceName,
 			Name:       containerName,
 			TtlSeconds: ptr.Int64(int64(ttl.Seconds())),
-		})
+		}
+
+		if retention != nil {
+			req.RetentionPolicy = &v1proto.RetentionPolicy{
+				KeepLast:    retention.KeepLast,
+				KeepDaily:   retention.KeepDaily,
+				KeepWeekly:  retention.KeepWeekly,
+				KeepMonthly: retention.KeepMonthly,
+			}
+		}
+
+		_, err := s.cli.SetContainerParameters(ctx, req)
 		if err != nil {
 			return errors.Wrap(err, "error setting container versions TTL")
 		}
 
 		fmt.Printf("container `%s` versions TTL set to %s\n", containerName, ttl)
+		if retention != nil {
+			fmt.Printf(
+				"container `%s` retention policy set to: keep last %d, daily %d, weekly %d, monthly %d\n",
+				containerName, retention.KeepLast, retention.KeepDaily, retention.KeepWeekly, retention.KeepMonthly,
+			)
+		}
 		return nil
 	}
 }
 
-func (s *service) CreateVersion(namespaceName, containerName string, shouldPublish bool, src source.Source) func(ctx context.Context) error {
+func (s *service) CreateVersion(namespaceName, containerName string, shouldPublish bool, src source.Source, concurrency int, resumeVersionID string) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
-		log.Tracef("creating version ...")
-		resp, err := s.cli.CreateVersion(ctx, &v1proto.CreateVersionRequest{
-			Namespace: namespaceName,
-			Container: containerName,
+		if concurrency < 1 {
+			return errors.Errorf("concurrency must be positive: %d", concurrency)
+		}
+
+		// Sources could hold the data shared by all of their objects
+		// (e.g. spooled archive) so it's released once uploads are done
+		if c, ok := src.(io.Closer); ok {
+			defer func() {
+				if err := c.Close(); err != nil {
+					log.Warnf("error closing source: %s", err)
+				}
+			}()
+		}
+
+		versionID := resumeVersionID
+
+		// existing maps keys of the objects already present in the resumed
+		// version to their checksums and missing maps checksums of the BLOBs
+		// referenced by the version but absent in storage to upload URLs
+		existing := map[string]string{}
+		missing := map[string]string{}
+
+		if versionID == "" {
+			log.Tracef("creating version ...")
+			resp, err := s.cli.CreateVersion(ctx, &v1proto.CreateVersionRequest{
+				Namespace: namespaceName,
+				Container: containerName,
+			})
+			if err != nil {
+				return errors.Wrap(err, "error creating version")
+			}
+
+			versionID = resp.GetVersion()
+			log.Tracef("version created: `%s`", versionID)
+		} else {
+			var err error
+			existing, missing, err = s.resumeState(ctx, namespaceName, containerName, versionID)
+			if err != nil {
+				return err
+			}
+
+			log.WithFields(log.Fields{
+				"namespace":     namespaceName,
+				"container":     containerName,
+				"version":       versionID,
+				"objects":       len(existing),
+				"missing_blobs": len(missing),
+			}).Info("resuming version creation ...")
+		}
+
+		// Objects are registered in batches to avoid round-trip per object
+		// so they're retained until the batch is flushed. Sources could call
+		// the handler concurrently so pending objects are guarded by mutex.
+		var mu sync.Mutex
+		pending := []source.Object{}
+		defer func() { closeObjects(pending) }()
+
+		// BLOBs are uploaded by the bounded pool of workers while the source
+		// keeps producing objects; the first failure cancels everything else.
+		uploads, uploadsCtx := errgroup.WithContext(ctx)
+		uploads.SetLimit(concurrency)
+
+		processErr := src.Process(uploadsCtx, func(ctx context.Context, obj source.Object) error {
+			mu.Lock()
+			key := strings.TrimPrefix(obj.Path, "/")
+			if checksum, ok := existing[key]; ok {
+				delete(existing, key)
+				if checksum == obj.SHA256 {
+					url, ok := missing[checksum]
+					delete(missing, checksum)
+					mu.Unlock()
+
+					log.WithFields(log.Fields{
+						"path":   obj.Path,
+						"sha256": obj.SHA256,
+					}).Debug("object is already present in version, skipping ...")
+
+					if !ok {
+						closeObjects([]source.Object{obj})
+						return nil
+					}
+					s.scheduleUpload(uploadsCtx, uploads, namespaceName, obj, url)
+					return nil
+				}
+				mu.Unlock()
+
+				// Object was changed since the interrupted run so it's
+				// recreated since existing objects are never overwritten
+				if err := s.deleteObject(ctx, namespaceName, containerName, versionID, key); err != nil {
+					closeObjects([]source.Object{obj})
+					return err
+				}
+				mu.Lock()
+			}
+
+			pending = append(pending, obj)
+			if len(pending) < createObjectsBatchSize {
+				mu.Unlock()
+				return nil
+			}
+			batch := pending
+			pending = []source.Object{}
+			mu.Unlock()
+
+			// Uploads could outlive the handler's context (i.e. the one of
+			// source's own workers) so they're bound to the uploads group
+			return s.createObjects(uploadsCtx, uploads, namespaceName, containerName, versionID, batch)
 		})
-		if err != nil {
-			return errors.Wrap(err, "error creating version")
+		if processErr == nil && len(pending) > 0 {
+			batch := pending
+			pending = []source.Object{}
+			processErr = s.createObjects(uploadsCtx, uploads, namespaceName, containerName, versionID, batch)
+		}
+
+		// Uploads are always waited for to not leave them running in
+		// background and their error is preferred since it's the one
+		// which caused the source to stop due to context cancellation.
+		if err := uploads.Wait(); err != nil {
+			return errors.Wrap(err, "error uploading objects")
+		}
+
+		if processErr != nil {
+			return errors.Wrap(processErr, "error processing source")
 		}
 
-		versionID := resp.GetVersion()
-		log.Tracef("version created: `%s`", versionID)
+		if resumeVersionID != "" {
+			// The version could still reference the BLOBs which were lost
+			// and are not provided by the source anymore
+			resp, err := s.cli.VerifyBLOBs(ctx, &v1proto.VerifyBLOBsRequest{
+				Namespace: namespaceName,
+				Container: containerName,
+				Version:   versionID,
+			})
+			if err != nil {
+				return errors.Wrap(err, "error verifying BLOBs")
+			}
 
-		if err := src.Process(ctx, func(ctx context.Context, obj source.Object) error {
-			return s.createObject(ctx, namespaceName, containerName, versionID, obj)
-		}); err != nil {
-			return errors.Wrap(err, "error processing source")
+			if n := len(resp.GetUploadUrls()); n > 0 {
+				return errors.Errorf("version `%s` still references %d BLOB(s) missing in storage", versionID, n)
+			}
 		}
 
 		if shouldPublish {
 			log.Tracef("publishing is requested so publishing version ...")
-			_, err = s.cli.PublishVersion(ctx, &v1proto.PublishVersionRequest{
+			_, err := s.cli.PublishVersion(ctx, &v1proto.PublishVersionRequest{
 				Namespace: namespaceName,
 				Container: containerName,
 				Version:   versionID,
@@ -238,6 +427,76 @@ func (s *service) CreateVersion(namespaceName, containerName string, shouldPubli
 	}
 }
 
+// resumeState returns the objects already present in the version and
+// upload URLs for the BLOBs they reference but which are missing in storage
+func (s *service) resumeState(ctx context.Context, namespaceName, containerName, versionID string) (map[string]string, map[string]string, error) {
+	objectsResp, err := s.cli.ListObjects(ctx, &v1proto.ListObjectsRequest{
+		Namespace: namespaceName,
+		Container: containerName,
+		Version:   versionID,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error listing objects of the version to resume")
+	}
+
+	existing := map[string]string{}
+	for _, o := range objectsResp.GetObjectDetails() {
+		existing[o.GetKey()] = o.GetChecksum()
+	}
+
+	blobsResp, err := s.cli.VerifyBLOBs(ctx, &v1proto.VerifyBLOBsRequest{
+		Namespace: namespaceName,
+		Container: containerName,
+		Version:   versionID,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error verifying BLOBs of the version to resume")
+	}
+
+	missing := map[string]string{}
+	for _, u := range blobsResp.GetUploadUrls() {
+		missing[u.GetChecksum()] = u.GetUrl()
+	}
+
+	return existing, missing, nil
+}
+
+func (s *service) deleteObject(ctx context.Context, namespaceName, containerName, versionID, key string) error {
+	log.WithFields(log.Fields{
+		"path":    key,
+		"version": versionID,
+	}).Debug("object was changed, deleting it to recreate ...")
+
+	_, err := s.cli.DeleteObject(ctx, &v1proto.DeleteObjectRequest{
+		Namespace: namespaceName,
+		Container: containerName,
+		Version:   versionID,
+		Key:       key,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error deleting changed object")
+	}
+	return nil
+}
+
+func (s *service) CloneVersion(namespaceName, containerName, versionID, destNamespaceName, destContainerName string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		resp, err := s.cli.CloneVersion(ctx, &v1proto.CloneVersionRequest{
+			Namespace:            namespaceName,
+			Container:            containerName,
+			Version:              versionID,
+			DestinationNamespace: destNamespaceName,
+			DestinationContainer: destContainerName,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error cloning version")
+		}
+
+		fmt.Println(resp.GetVersion())
+		return nil
+	}
+}
+
 func (s *service) DeleteVersion(namespaceName, containerName, versionID string) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
 		_, err := s.cli.DeleteVersion(ctx, &v1proto.DeleteVersionRequest{
@@ -289,7 +548,144 @@ func (s *service) PublishVersion(namespaceName, containerName, versionID string)
 	}
 }
 
-func (s *service) ListObjects(namespaceName, containerName, versionID string) func(ctx context.Context) error {
+func (s *service) ProtectVersion(namespaceName, containerName, versionID string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := s.cli.ProtectVersion(ctx, &v1proto.ProtectVersionRequest{
+			Namespace: namespaceName,
+			Container: containerName,
+			Version:   versionID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error protecting version")
+		}
+
+		fmt.Printf("version `%s` of container `%s/%s` is protected now\n", versionID, namespaceName, containerName)
+		return nil
+	}
+}
+
+func (s *service) UnprotectVersion(namespaceName, containerName, versionID string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := s.cli.UnprotectVersion(ctx, &v1proto.UnprotectVersionRequest{
+			Namespace: namespaceName,
+			Container: containerName,
+			Version:   versionID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error unprotecting version")
+		}
+
+		fmt.Printf("version `%s` of container `%s/%s` is not protected anymore\n", versionID, namespaceName, containerName)
+		return nil
+	}
+}
+
+func (s *service) DiffVersions(namespaceName, containerName, fromVersionID, toVersionID string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		resp, err := s.cli.DiffVersions(ctx, &v1proto.DiffVersionsRequest{
+			Namespace:   namespaceName,
+			Container:   containerName,
+			FromVersion: fromVersionID,
+			ToVersion:   toVersionID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error comparing versions")
+		}
+
+		for _, object := range resp.GetAdded() {
+			fmt.Printf("+ %s %d %s\n", object.GetKey(), object.GetSize(), object.GetChecksum())
+		}
+		for _, object := range resp.GetRemoved() {
+			fmt.Printf("- %s %d %s\n", object.GetKey(), object.GetSize(), object.GetChecksum())
+		}
+		for _, change := range resp.GetChanged() {
+			fmt.Printf(
+				"~ %s %d %s -> %d %s\n",
+				change.GetKey(), change.GetOldSize(), change.GetOldChecksum(), change.GetNewSize(), change.GetNewChecksum(),
+			)
+		}
+
+		fmt.Printf(
+			"%s..%s: %d added (%d bytes), %d removed (%d bytes), %d changed (%d -> %d bytes)\n",
+			resp.GetFromVersion(), resp.GetToVersion(),
+			len(resp.GetAdded()), resp.GetAddedBytes(),
+			len(resp.GetRemoved()), resp.GetRemovedBytes(),
+			len(resp.GetChanged()), resp.GetChangedOldBytes(), resp.GetChangedNewBytes(),
+		)
+
+		return nil
+	}
+}
+
+func (s *service) CreateAlias(namespaceName, containerName, aliasName, versionID string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := s.cli.CreateAlias(ctx, &v1proto.CreateAliasRequest{
+			Namespace: namespaceName,
+			Container: containerName,
+			Name:      aliasName,
+			Version:   versionID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error creating alias")
+		}
+
+		fmt.Printf("alias `%s` of container `%s/%s` created and points to version `%s`\n", aliasName, namespaceName, containerName, versionID)
+		return nil
+	}
+}
+
+func (s *service) MoveAlias(namespaceName, containerName, aliasName, versionID string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := s.cli.MoveAlias(ctx, &v1proto.MoveAliasRequest{
+			Namespace: namespaceName,
+			Container: containerName,
+			Name:      aliasName,
+			Version:   versionID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error moving alias")
+		}
+
+		fmt.Printf("alias `%s` of container `%s/%s` points to version `%s` now\n", aliasName, namespaceName, containerName, versionID)
+		return nil
+	}
+}
+
+func (s *service) DeleteAlias(namespaceName, containerName, aliasName string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := s.cli.DeleteAlias(ctx, &v1proto.DeleteAliasRequest{
+			Namespace: namespaceName,
+			Container: containerName,
+			Name:      aliasName,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error deleting alias")
+		}
+
+		fmt.Printf("alias `%s` of container `%s/%s` deleted\n", aliasName, namespaceName, containerName)
+		return nil
+	}
+}
+
+func (s *service) ListAliases(namespaceName, containerName string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		resp, err := s.cli.ListAliases(ctx, &v1proto.ListAliasesRequest{
+			Namespace: namespaceName,
+			Container: containerName,
+		})
+		if err != nil {
+			return errors.Wrap(err, "error listing aliases")
+		}
+
+		for _, alias := range resp.GetAliases() {
+			fmt.Printf("%s -> %s\n", alias.GetName(), alias.GetVersion())
+		}
+
+		return nil
+	}
+}
+
+func (s *service) ListObjects(namespaceName, containerName, versionID string, long bool) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
 		resp, err := s.cli.ListObjects(ctx, &v1proto.ListObjectsRequest{
 			Namespace: namespaceName,
@@ -300,8 +696,19 @@ func (s *service) ListObjects(namespaceName, containerName, versionID string) fu
 			return errors.Wrap(err, "error listing objects")
 		}
 
-		for _, object := range resp.GetObjects() {
-			fmt.Println(object)
+		if !long {
+			for _, object := range resp.GetObjects() {
+				fmt.Println(object)
+			}
+			return nil
+		}
+
+		for _, object := range resp.GetObjectDetails() {
+			fmt.Printf(
+				"%s %d %s %s %s\n",
+				object.GetCreatedAt().AsTime().Format(time.RFC3339),
+				object.GetSize(), object.GetChecksum(), object.GetMimeType(), object.GetKey(),
+			)
 		}
 
 		return nil
@@ -342,77 +749,210 @@ func (s *service) DeleteObject(namespaceName, containerName, versionID, objectKe
 	}
 }
 
-func (s *service) createObject(ctx context.Context, namespaceName, containerName, versionID string, object source.Object) error {
-	log.WithFields(log.Fields{
-		"path":      object.Path,
-		"sha256":    object.SHA256,
-		"length":    object.Size,
-		"mime_type": object.MimeType,
-	}).Debug("creating object ...")
-
-	resp, err := s.cli.CreateObject(ctx, &v1proto.CreateObjectRequest{
+// createObjects registers objects in the manager and schedules upload of
+// the BLOBs missing on the server side to the uploads group. The objects
+// are owned by createObjects so they're closed either right away or once
+// the upload is done.
+func (s *service) createObjects(ctx context.Context, uploads *errgroup.Group, namespaceName, containerName, versionID string, objects []source.Object) error {
+	req := &v1proto.CreateObjectsRequest{
 		Namespace: namespaceName,
 		Container: containerName,
 		Version:   versionID,
-		Key:       object.Path,
-		Checksum:  object.SHA256,
-		Size:      object.Size,
-		MimeType:  object.MimeType,
-	})
+	}
+	for _, object := range objects {
+		log.WithFields(log.Fields{
+			"path":      object.Path,
+			"sha256":    object.SHA256,
+			"length":    object.Size,
+			"mime_type": object.MimeType,
+		}).Debug("creating object ...")
+
+		req.Objects = append(req.Objects, &v1proto.Object{
+			Key:      object.Path,
+			Checksum: object.SHA256,
+			Size:     object.Size,
+			MimeType: object.MimeType,
+		})
+	}
+
+	resp, err := s.cli.CreateObjects(ctx, req)
 	if err != nil {
-		return errors.Wrap(err, "error creating object")
+		closeObjects(objects)
+		return errors.Wrap(err, "error creating objects")
+	}
+
+	uploadURLs := map[string]string{}
+	for _, u := range resp.GetUploadUrls() {
+		uploadURLs[u.GetChecksum()] = u.GetUrl()
+	}
+
+	for _, object := range objects {
+		url, ok := uploadURLs[object.SHA256]
+		if !ok {
+			closeObjects([]source.Object{object})
+			continue
+		}
+		// The same blob could be referenced by several objects in the batch
+		delete(uploadURLs, object.SHA256)
+
+		s.scheduleUpload(ctx, uploads, namespaceName, object, url)
 	}
+	return nil
+}
+
+// scheduleUpload uploads object contents to the URL using the uploads group
+// and closes the object afterwards
+func (s *service) scheduleUpload(ctx context.Context, uploads *errgroup.Group, namespaceName string, object source.Object, url string) {
+	uploads.Go(func() error {
+		defer closeObjects([]source.Object{object})
+
+		if err := s.uploadObject(ctx, namespaceName, object, url); err != nil {
+			return errors.Wrapf(err, "error uploading BLOB for `%s`", object.Path)
+		}
+		return nil
+	})
+}
+
+// uploadObject uploads large objects in parts if the server supports it
+// and with the single request to the URL given otherwise
+func (s *service) uploadObject(ctx context.Context, namespaceName string, object source.Object, url string) error {
+	if object.Size >= s.multipartThreshold {
+		err := s.uploadMultipart(ctx, namespaceName, object)
+		if status.Code(err) != codes.Unimplemented {
+			return err
+		}
 
-	if url := resp.GetUploadUrl(); url != "" {
 		log.WithFields(log.Fields{
 			"path":   object.Path,
 			"sha256": object.SHA256,
-			"length": object.Size,
-			"url":    url,
-		}).Debug("uploading BLOB ...")
+		}).Debug("multipart upload is not supported by server, uploading with single request ...")
+	}
 
-		rd, err := object.Contents(ctx)
-		if err != nil {
-			return errors.Wrap(err, "error getting contents reader")
+	log.WithFields(log.Fields{
+		"path":   object.Path,
+		"sha256": object.SHA256,
+		"length": object.Size,
+		"url":    url,
+	}).Debug("uploading BLOB ...")
+
+	rd, err := object.Contents(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error getting contents reader")
+	}
+
+	if err := uploadBlob(ctx, url, rd, object.Size); err != nil {
+		return err
+	}
+
+	// Servers without BLOB confirmation support treat BLOBs as stored right
+	// away
+	_, err = s.cli.ConfirmBLOB(ctx, &v1proto.ConfirmBLOBRequest{
+		Namespace: namespaceName,
+		Checksum:  object.SHA256,
+	})
+	if err != nil && status.Code(err) != codes.Unimplemented {
+		return errors.Wrap(err, "error confirming BLOB upload")
+	}
+	return nil
+}
+
+func closeObjects(objects []source.Object) {
+	for _, object := range objects {
+		if object.Close == nil {
+			continue
 		}
 
-		if err := uploadBlob(ctx, url, rd, object.Size); err != nil {
-			return errors.Wrap(err, "error uploading BLOB")
+		if err := object.Close(); err != nil {
+			log.WithFields(log.Fields{
+				"path":  object.Path,
+				"error": err,
+			}).Warn("error releasing object resources")
 		}
 	}
-	return nil
 }
 
 func uploadBlob(ctx context.Context, url string, rd io.Reader, size uint64) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, io.NopCloser(rd))
-	if err != nil {
-		return errors.Wrap(err, "error constructing request")
+	// Readers which have to be closed (i.e. files) are closed here since
+	// HTTP client doesn't take ownership of the body with known length
+	if c, ok := rd.(io.Closer); ok {
+		defer func() { _ = c.Close() }()
 	}
 
-	req.Header.Set("Content-Type", "multipart/form-data")
-	// if req.ContentLength <= 0 {
-	// 	log.WithFields(log.Fields{
-	// 		"url":    url,
-	// 		"length": size,
-	// 	}).Tracef("Setting Content-Length since it's not set properly ...")
+	_, err := putBLOB(ctx, url, rd, int64(size))
+	return err
+}
+
+// putBLOB performs HTTP PUT request with the body of exact length given
+// since presigned upload URLs don't support chunked transfer encoding
+func putBLOB(ctx context.Context, url string, rd io.Reader, length int64) (http.Header, error) {
+	var body io.Reader = http.NoBody
+	if length > 0 {
+		body = io.NopCloser(io.LimitReader(rd, length))
+	}
 
-	// 	req.ContentLength = int64(size)
-	// }
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "error constructing request")
+	}
+	req.ContentLength = length
+	req.Header.Set("Content-Type", "multipart/form-data")
 
 	log.WithFields(log.Fields{
 		"url":    url,
 		"length": req.ContentLength,
 	}).Tracef("running HTTP PUT request ...")
 
-	uploadResp, err := http.DefaultClient.Do(req)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return errors.Wrap(err, "error uploading object")
+		return nil, errors.Wrap(err, "error uploading object")
 	}
-	defer func() { _ = uploadResp.Body.Close() }()
+	defer func() { _ = resp.Body.Close() }()
 
-	if uploadResp.StatusCode > 299 {
-		return errors.Errorf("unexpected status code on upload: %s", uploadResp.Status)
+	if resp.StatusCode > 299 {
+		return nil, errors.Errorf("unexpected status code on upload: %s", resp.Status)
 	}
 
-	return nil
+	return resp.Header, nil
+}
+
+func (s *service) ListAuditEvents(actor, operation, namespaceName string, since time.Duration, limit uint64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req := &v1proto.ListAuditEventsRequest{
+			Actor:     actor,
+			Operation: operation,
+			Namespace: namespaceName,
+			Limit:     limit,
+		}
+
+		if since > 0 {
+			req.Since = timestamppb.New(time.Now().Add(-since))
+		}
+
+		resp, err := s.cli.ListAuditEvents(ctx, req)
+		if err != nil {
+			return errors.Wrap(err, "error listing audit events")
+		}
+
+		for _, event := range resp.GetEvents() {
+			parameters := []string{}
+			for k, v := range event.GetParameters() {
+				parameters = append(parameters, k+"="+v)
+			}
+			sort.Strings(parameters)
+
+			result := event.GetResult()
+			if event.GetError() != "" {
+				result += ": " + event.GetError()
+			}
+
+			fmt.Printf(
+				"%s %s %s namespace=%s %s (%s)\n",
+				event.GetCreatedAt().AsTime().Format(time.RFC3339),
+				event.GetActor(), event.GetOperation(), event.GetNamespace(),
+				strings.Join(parameters, " "), result,
+			)
+		}
+
+		return nil
+	}
 }
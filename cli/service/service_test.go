@@ -1,15 +1,34 @@
 package service
 
 import (
+	"archive/tar"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/teran/archived/cli/service/source"
 	sourceMock "github.com/teran/archived/cli/service/source/mock"
 	cacheMock "github.com/teran/archived/cli/service/stat_cache/mock"
+	v1proto "github.com/teran/archived/manager/presenter/grpc/proto/v1"
+	"github.com/teran/archived/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -48,6 +67,13 @@ func (s *serviceTestSuite) TestDeleteNamespace() {
 	s.Require().NoError(fn(s.ctx))
 }
 
+func (s *serviceTestSuite) TestSetNamespaceParameters() {
+	s.cliMock.On("SetNamespaceParameters", "test-namespace", true).Return(nil).Once()
+
+	fn := s.svc.SetNamespaceParameters("test-namespace", true)
+	s.Require().NoError(fn(s.ctx))
+}
+
 func (s *serviceTestSuite) TestCreateContainer() {
 	s.cliMock.On("CreateContainer", defaultNamespace, "test-container").Return(nil).Once()
 
@@ -84,9 +110,22 @@ func (s *serviceTestSuite) TestDeleteContainer() {
 }
 
 func (s *serviceTestSuite) TestSetContainerParameters() {
-	s.cliMock.On("SetContainerParameters", defaultNamespace, "test-container1", 3600*time.Second).Return(nil).Once()
+	s.cliMock.On("SetContainerParameters", defaultNamespace, "test-container1", 3600*time.Second, &models.RetentionPolicy{
+		KeepLast:    10,
+		KeepMonthly: 12,
+	}).Return(nil).Once()
+
+	fn := s.svc.SetContainerParameters(defaultNamespace, "test-container1", 3600*time.Second, &models.RetentionPolicy{
+		KeepLast:    10,
+		KeepMonthly: 12,
+	})
+	s.Require().NoError(fn(s.ctx))
+}
 
-	fn := s.svc.SetContainerParameters(defaultNamespace, "test-container1", 3600*time.Second)
+func (s *serviceTestSuite) TestSetContainerParametersWithoutRetentionPolicy() {
+	s.cliMock.On("SetContainerParameters", defaultNamespace, "test-container1", 3600*time.Second, (*models.RetentionPolicy)(nil)).Return(nil).Once()
+
+	fn := s.svc.SetContainerParameters(defaultNamespace, "test-container1", 3600*time.Second, nil)
 	s.Require().NoError(fn(s.ctx))
 }
 
@@ -95,7 +134,7 @@ func (s *serviceTestSuite) TestCreateVersion() {
 
 	s.sourceMock.On("Process").Return(nil).Once()
 
-	fn := s.svc.CreateVersion(defaultNamespace, "container1", false, s.sourceMock)
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", false, s.sourceMock, 4, "")
 	s.Require().NoError(fn(s.ctx))
 }
 
@@ -105,8 +144,383 @@ func (s *serviceTestSuite) TestCreateVersionAndPublish() {
 
 	s.sourceMock.On("Process").Return(nil).Once()
 
-	fn := s.svc.CreateVersion(defaultNamespace, "container1", true, s.sourceMock)
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", true, s.sourceMock, 4, "")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestCreateVersionWithObjects() {
+	var mu sync.Mutex
+	uploads := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		s.Require().NoError(err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		uploads[r.URL.Path] = string(data)
+	}))
+	defer srv.Close()
+
+	var closed atomic.Int64
+	objects := []source.Object{}
+	firstBatch := []string{}
+	secondBatch := []string{}
+	for i := 0; i < createObjectsBatchSize+2; i++ {
+		key := fmt.Sprintf("file%03d.txt", i)
+		objects = append(objects, source.Object{
+			Path: key,
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				return strings.NewReader("data of " + key), nil
+			},
+			SHA256:   fmt.Sprintf("checksum%03d", i%(createObjectsBatchSize+1)),
+			Size:     uint64(len("data of " + key)),
+			MimeType: "text/plain",
+			Close: func() error {
+				closed.Add(1)
+				return nil
+			},
+		})
+
+		if i < createObjectsBatchSize {
+			firstBatch = append(firstBatch, key)
+		} else {
+			secondBatch = append(secondBatch, key)
+		}
+	}
+
+	s.cliMock.On("CreateVersion", defaultNamespace, "container1").Return("version_id", nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", firstBatch).Return([]*v1proto.UploadURL{
+		{Checksum: "checksum000", Url: srv.URL + "/checksum000"},
+	}, nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", secondBatch).Return([]*v1proto.UploadURL{
+		{Checksum: "checksum100", Url: srv.URL + "/checksum100"},
+	}, nil).Once()
+	s.cliMock.On("ConfirmBLOB", defaultNamespace, "checksum000").Return(nil).Once()
+	s.cliMock.On("ConfirmBLOB", defaultNamespace, "checksum100").Return(nil).Once()
+	s.sourceMock.On("Process").Return(nil, objects).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", false, s.sourceMock, 4, "")
 	s.Require().NoError(fn(s.ctx))
+
+	s.Require().Equal(map[string]string{
+		"/checksum000": "data of file000.txt",
+		"/checksum100": "data of file100.txt",
+	}, uploads)
+	s.Require().Equal(int64(len(objects)), closed.Load())
+}
+
+func (s *serviceTestSuite) TestCreateVersionWithObjectsError() {
+	var closed atomic.Int64
+	objects := []source.Object{}
+	for i := 0; i < 3; i++ {
+		objects = append(objects, source.Object{
+			Path:   fmt.Sprintf("file%d.txt", i),
+			SHA256: "checksum",
+			Close: func() error {
+				closed.Add(1)
+				return nil
+			},
+		})
+	}
+
+	s.cliMock.On("CreateVersion", defaultNamespace, "container1").Return("version_id", nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", []string{"file0.txt", "file1.txt", "file2.txt"}).
+		Return([]*v1proto.UploadURL{}, errors.New("some error")).Once()
+	s.sourceMock.On("Process").Return(nil, objects).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", false, s.sourceMock, 4, "")
+	s.Require().Error(fn(s.ctx))
+	s.Require().Equal(int64(len(objects)), closed.Load())
+}
+
+func (s *serviceTestSuite) TestCreateVersionUploadError() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	var closed atomic.Int64
+	objects := []source.Object{}
+	uploadURLs := []*v1proto.UploadURL{}
+	for i := 0; i < 3; i++ {
+		checksum := fmt.Sprintf("checksum%d", i)
+		objects = append(objects, source.Object{
+			Path: fmt.Sprintf("file%d.txt", i),
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				return strings.NewReader("data"), nil
+			},
+			SHA256: checksum,
+			Size:   4,
+			Close: func() error {
+				closed.Add(1)
+				return nil
+			},
+		})
+		uploadURLs = append(uploadURLs, &v1proto.UploadURL{Checksum: checksum, Url: srv.URL + "/" + checksum})
+	}
+
+	s.cliMock.On("CreateVersion", defaultNamespace, "container1").Return("version_id", nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", []string{"file0.txt", "file1.txt", "file2.txt"}).
+		Return(uploadURLs, nil).Once()
+	s.sourceMock.On("Process").Return(nil, objects).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", true, s.sourceMock, 2, "")
+	err := fn(s.ctx)
+	s.Require().Error(err)
+	s.Require().Contains(err.Error(), "unexpected status code on upload: 500 Internal Server Error")
+	s.Require().Equal(int64(len(objects)), closed.Load())
+}
+
+func (s *serviceTestSuite) TestCreateVersionConfirmError() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		s.Require().NoError(err)
+	}))
+	defer srv.Close()
+
+	s.cliMock.On("CreateVersion", defaultNamespace, "container1").Return("version_id", nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", []string{"file0.txt"}).Return([]*v1proto.UploadURL{
+		{Checksum: "checksum0", Url: srv.URL + "/checksum0"},
+	}, nil).Once()
+	s.cliMock.On("ConfirmBLOB", defaultNamespace, "checksum0").Return(status.Error(codes.AlreadyExists, "entity already exists")).Once()
+	s.sourceMock.On("Process").Return(nil, []source.Object{
+		{
+			Path: "file0.txt",
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				return strings.NewReader("data"), nil
+			},
+			SHA256: "checksum0",
+			Size:   4,
+		},
+	}).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", true, s.sourceMock, 4, "")
+	err := fn(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("error uploading objects: error uploading BLOB for `file0.txt`: error confirming BLOB upload: rpc error: code = AlreadyExists desc = entity already exists", err.Error())
+}
+
+func (s *serviceTestSuite) TestCreateVersionResume() {
+	var mu sync.Mutex
+	uploads := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		s.Require().NoError(err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		uploads[r.URL.Path] = string(data)
+	}))
+	defer srv.Close()
+
+	var closed atomic.Int64
+	objects := []source.Object{}
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprintf("file%d.txt", i)
+		objects = append(objects, source.Object{
+			Path: key,
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				return strings.NewReader("data of " + key), nil
+			},
+			SHA256: fmt.Sprintf("checksum%d", i),
+			Size:   uint64(len("data of " + key)),
+			Close: func() error {
+				closed.Add(1)
+				return nil
+			},
+		})
+	}
+
+	s.cliMock.On("ListObjects", defaultNamespace, "container1", "20240102030405").Return([]string{"file0.txt", "file1.txt", "file2.txt"}, nil, []*v1proto.Object{
+		{Key: "file0.txt", Checksum: "checksum0"},
+		{Key: "file1.txt", Checksum: "checksum1"},
+		{Key: "file2.txt", Checksum: "old-checksum"},
+	}).Once()
+	s.cliMock.On("VerifyBLOBs", defaultNamespace, "container1", "20240102030405").Return([]*v1proto.UploadURL{
+		{Checksum: "checksum0", Url: srv.URL + "/checksum0"},
+	}, nil).Once()
+	s.cliMock.On("DeleteObject", defaultNamespace, "container1", "20240102030405", "file2.txt").Return(nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "20240102030405", []string{"file2.txt", "file3.txt"}).Return([]*v1proto.UploadURL{
+		{Checksum: "checksum3", Url: srv.URL + "/checksum3"},
+	}, nil).Once()
+	s.cliMock.On("ConfirmBLOB", defaultNamespace, "checksum0").Return(nil).Once()
+	s.cliMock.On("ConfirmBLOB", defaultNamespace, "checksum3").Return(nil).Once()
+	s.cliMock.On("VerifyBLOBs", defaultNamespace, "container1", "20240102030405").Return([]*v1proto.UploadURL{}, nil).Once()
+	s.cliMock.On("PublishVersion", defaultNamespace, "container1", "20240102030405").Return(nil).Once()
+	s.sourceMock.On("Process").Return(nil, objects).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", true, s.sourceMock, 4, "20240102030405")
+	s.Require().NoError(fn(s.ctx))
+
+	s.Require().Equal(map[string]string{
+		"/checksum0": "data of file0.txt",
+		"/checksum3": "data of file3.txt",
+	}, uploads)
+	s.Require().Equal(int64(len(objects)), closed.Load())
+}
+
+func (s *serviceTestSuite) TestCreateVersionResumeMissingBLOBs() {
+	s.cliMock.On("ListObjects", defaultNamespace, "container1", "20240102030405").Return([]string{"file0.txt"}, nil, []*v1proto.Object{
+		{Key: "file0.txt", Checksum: "checksum0"},
+	}).Once()
+	s.cliMock.On("VerifyBLOBs", defaultNamespace, "container1", "20240102030405").Return([]*v1proto.UploadURL{
+		{Checksum: "checksum0", Url: "http://example.com/checksum0"},
+	}, nil).Twice()
+	s.sourceMock.On("Process").Return(nil, []source.Object{}).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", true, s.sourceMock, 4, "20240102030405")
+	err := fn(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("version `20240102030405` still references 1 BLOB(s) missing in storage", err.Error())
+}
+
+func (s *serviceTestSuite) TestCreateVersionMultipartUpload() {
+	s.svc.(*service).multipartThreshold = 10
+
+	var mu sync.Mutex
+	uploads := map[string]string{}
+	failed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		s.Require().NoError(err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// the first attempt to upload the part is failed to check retries
+		if r.URL.Path == "/checksum1/part2" && !failed {
+			failed = true
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		uploads[r.URL.Path] = string(data)
+		w.Header().Set("ETag", "etag-"+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	objects := []source.Object{
+		{
+			Path: "file0.txt",
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				return strings.NewReader("0123456789abcdefghijklmno"), nil
+			},
+			SHA256: "checksum0",
+			Size:   25,
+		},
+		{
+			Path: "file1.txt",
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				// reader without io.ReaderAt to check parts buffering
+				return struct{ io.Reader }{strings.NewReader("ABCDEFGHIJKLMNOPQRSTUVWXY")}, nil
+			},
+			SHA256: "checksum1",
+			Size:   25,
+		},
+	}
+
+	s.cliMock.On("CreateVersion", defaultNamespace, "container1").Return("version_id", nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", []string{"file0.txt", "file1.txt"}).Return([]*v1proto.UploadURL{
+		{Checksum: "checksum0", Url: srv.URL + "/checksum0"},
+		{Checksum: "checksum1", Url: srv.URL + "/checksum1"},
+	}, nil).Once()
+	for _, checksum := range []string{"checksum0", "checksum1"} {
+		s.cliMock.On("CreateMultipartUpload", defaultNamespace, checksum, uint64(25)).Return("upload-"+checksum, uint64(10), []string{
+			srv.URL + "/" + checksum + "/part1",
+			srv.URL + "/" + checksum + "/part2",
+			srv.URL + "/" + checksum + "/part3",
+		}, nil).Once()
+		s.cliMock.On("CompleteMultipartUpload", defaultNamespace, checksum, "upload-"+checksum, []string{
+			"etag-/" + checksum + "/part1",
+			"etag-/" + checksum + "/part2",
+			"etag-/" + checksum + "/part3",
+		}).Return(nil).Once()
+	}
+	s.sourceMock.On("Process").Return(nil, objects).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", false, s.sourceMock, 4, "")
+	s.Require().NoError(fn(s.ctx))
+
+	s.Require().Equal(map[string]string{
+		"/checksum0/part1": "0123456789",
+		"/checksum0/part2": "abcdefghij",
+		"/checksum0/part3": "klmno",
+		"/checksum1/part1": "ABCDEFGHIJ",
+		"/checksum1/part2": "KLMNOPQRST",
+		"/checksum1/part3": "UVWXY",
+	}, uploads)
+}
+
+func (s *serviceTestSuite) TestCreateVersionMultipartUploadNotSupported() {
+	s.svc.(*service).multipartThreshold = 10
+
+	uploads := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		s.Require().NoError(err)
+
+		uploads[r.URL.Path] = string(data)
+	}))
+	defer srv.Close()
+
+	s.cliMock.On("CreateVersion", defaultNamespace, "container1").Return("version_id", nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", []string{"file0.txt"}).Return([]*v1proto.UploadURL{
+		{Checksum: "checksum0", Url: srv.URL + "/checksum0"},
+	}, nil).Once()
+	s.cliMock.On("CreateMultipartUpload", defaultNamespace, "checksum0", uint64(25)).
+		Return("", uint64(0), []string(nil), status.Error(codes.Unimplemented, "operation is not supported")).Once()
+	s.cliMock.On("ConfirmBLOB", defaultNamespace, "checksum0").Return(status.Error(codes.Unimplemented, "unknown method ConfirmBLOB")).Once()
+	s.sourceMock.On("Process").Return(nil, []source.Object{
+		{
+			Path: "file0.txt",
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				return strings.NewReader("0123456789abcdefghijklmno"), nil
+			},
+			SHA256: "checksum0",
+			Size:   25,
+		},
+	}).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", false, s.sourceMock, 4, "")
+	s.Require().NoError(fn(s.ctx))
+
+	s.Require().Equal(map[string]string{
+		"/checksum0": "0123456789abcdefghijklmno",
+	}, uploads)
+}
+
+func (s *serviceTestSuite) TestCreateVersionMultipartUploadAbort() {
+	s.svc.(*service).multipartThreshold = 10
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", "etag-"+r.URL.Path)
+	}))
+	defer srv.Close()
+
+	s.cliMock.On("CreateVersion", defaultNamespace, "container1").Return("version_id", nil).Once()
+	s.cliMock.On("CreateObjects", defaultNamespace, "container1", "version_id", []string{"file0.txt"}).Return([]*v1proto.UploadURL{
+		{Checksum: "checksum0", Url: srv.URL + "/checksum0"},
+	}, nil).Once()
+	s.cliMock.On("CreateMultipartUpload", defaultNamespace, "checksum0", uint64(15)).
+		Return("upload-id", uint64(10), []string{srv.URL + "/part1", srv.URL + "/part2"}, nil).Once()
+	s.cliMock.On("CompleteMultipartUpload", defaultNamespace, "checksum0", "upload-id", []string{"etag-/part1", "etag-/part2"}).
+		Return(errors.New("some error")).Once()
+	s.cliMock.On("AbortMultipartUpload", defaultNamespace, "checksum0", "upload-id").Return(nil).Once()
+	s.sourceMock.On("Process").Return(nil, []source.Object{
+		{
+			Path: "file0.txt",
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				return strings.NewReader("0123456789abcde"), nil
+			},
+			SHA256: "checksum0",
+			Size:   15,
+		},
+	}).Once()
+
+	fn := s.svc.CreateVersion(defaultNamespace, "container1", true, s.sourceMock, 4, "")
+	s.Require().Error(fn(s.ctx))
 }
 
 func (s *serviceTestSuite) TestDeleteVersion() {
@@ -130,6 +544,94 @@ func (s *serviceTestSuite) TestPublishVersion() {
 	s.Require().NoError(fn(s.ctx))
 }
 
+func (s *serviceTestSuite) TestProtectVersion() {
+	s.cliMock.On("ProtectVersion", defaultNamespace, "container1", "version1").Return(nil).Once()
+
+	fn := s.svc.ProtectVersion(defaultNamespace, "container1", "version1")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestUnprotectVersion() {
+	s.cliMock.On("UnprotectVersion", defaultNamespace, "container1", "version1").Return(nil).Once()
+
+	fn := s.svc.UnprotectVersion(defaultNamespace, "container1", "version1")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestCloneVersion() {
+	s.cliMock.On("CloneVersion", defaultNamespace, "container1", "version1", "", "container2").Return("version2", nil).Once()
+
+	fn := s.svc.CloneVersion(defaultNamespace, "container1", "version1", "", "container2")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestDiffVersions() {
+	s.cliMock.On("DiffVersions", defaultNamespace, "container1", "version1", "version2").Return(&v1proto.DiffVersionsResponse{
+		FromVersion: "version1",
+		ToVersion:   "version2",
+		Added: []*v1proto.Object{
+			{Key: "obj2", Size: 10, Checksum: "deadbeef"},
+		},
+		Changed: []*v1proto.ObjectChange{
+			{Key: "obj1", OldSize: 5, OldChecksum: "cafebabe", NewSize: 7, NewChecksum: "abcdef01"},
+		},
+		AddedBytes:      10,
+		ChangedOldBytes: 5,
+		ChangedNewBytes: 7,
+	}, nil).Once()
+
+	fn := s.svc.DiffVersions(defaultNamespace, "container1", "version1", "version2")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestCreateAlias() {
+	s.cliMock.On("CreateAlias", defaultNamespace, "container1", "stable", "version1").Return(nil).Once()
+
+	fn := s.svc.CreateAlias(defaultNamespace, "container1", "stable", "version1")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestMoveAlias() {
+	s.cliMock.On("MoveAlias", defaultNamespace, "container1", "stable", "version2").Return(nil).Once()
+
+	fn := s.svc.MoveAlias(defaultNamespace, "container1", "stable", "version2")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestDeleteAlias() {
+	s.cliMock.On("DeleteAlias", defaultNamespace, "container1", "stable").Return(nil).Once()
+
+	fn := s.svc.DeleteAlias(defaultNamespace, "container1", "stable")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestListAliases() {
+	s.cliMock.On("ListAliases", defaultNamespace, "container1").Return([]*v1proto.Alias{
+		{Name: "stable", Version: "version1"},
+	}, nil).Once()
+
+	fn := s.svc.ListAliases(defaultNamespace, "container1")
+	s.Require().NoError(fn(s.ctx))
+}
+
+func (s *serviceTestSuite) TestListAuditEvents() {
+	s.cliMock.On("ListAuditEvents", "john", "", "", uint64(100)).Return([]*v1proto.AuditEvent{
+		{
+			Id:        1,
+			Actor:     "john",
+			Operation: "DeleteContainer",
+			Namespace: defaultNamespace,
+			Parameters: map[string]string{
+				"container": "container1",
+			},
+			Result: "success",
+		},
+	}, nil).Once()
+
+	fn := s.svc.ListAuditEvents("john", "", "", 24*time.Hour, 100)
+	s.Require().NoError(fn(s.ctx))
+}
+
 func (s *serviceTestSuite) TestDeleteObject() {
 	s.cliMock.On("DeleteObject", defaultNamespace, "container1", "version1", "key1").Return(nil).Once()
 
@@ -138,9 +640,12 @@ func (s *serviceTestSuite) TestDeleteObject() {
 }
 
 func (s *serviceTestSuite) TestListObjects() {
-	s.cliMock.On("ListObjects", defaultNamespace, "container1", "version1").Return([]string{"obj1", "obj2", "obj3"}, nil).Once()
+	s.cliMock.On("ListObjects", defaultNamespace, "container1", "version1").Return([]string{"obj1", "obj2", "obj3"}, nil).Twice()
 
-	fn := s.svc.ListObjects(defaultNamespace, "container1", "version1")
+	fn := s.svc.ListObjects(defaultNamespace, "container1", "version1", false)
+	s.Require().NoError(fn(s.ctx))
+
+	fn = s.svc.ListObjects(defaultNamespace, "container1", "version1", true)
 	s.Require().NoError(fn(s.ctx))
 }
 
@@ -151,6 +656,79 @@ func (s *serviceTestSuite) TestGetObjectURL() {
 	s.Require().NoError(fn(s.ctx))
 }
 
+func (s *serviceTestSuite) TestExportVersion() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data of " + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	sum := func(data string) string {
+		h := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(h[:])
+	}
+
+	s.cliMock.On("ListObjects", defaultNamespace, "container1", "20240102030405").Return([]string{"dir/file1.txt", "file2.txt"}, nil, []*v1proto.Object{
+		{Key: "dir/file1.txt", Size: 14, Checksum: sum("data of /blob1"), CreatedAt: timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))},
+		{Key: "file2.txt", Size: 14, Checksum: sum("data of /blob2"), CreatedAt: timestamppb.New(time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC))},
+	}).Once()
+	s.cliMock.On("GetObjectURL", defaultNamespace, "container1", "20240102030405", "dir/file1.txt").Return(srv.URL+"/blob1", nil).Once()
+	s.cliMock.On("GetObjectURL", defaultNamespace, "container1", "20240102030405", "file2.txt").Return(srv.URL+"/blob2", nil).Once()
+
+	output := filepath.Join(s.T().TempDir(), "export.tar")
+	fn := s.svc.ExportVersion(defaultNamespace, "container1", "20240102030405", "tar", output)
+	s.Require().NoError(fn(s.ctx))
+
+	fp, err := os.Open(output)
+	s.Require().NoError(err)
+	defer func() { _ = fp.Close() }()
+
+	tr := tar.NewReader(fp)
+	for _, expected := range []struct {
+		name string
+		data string
+	}{
+		{"dir/file1.txt", "data of /blob1"},
+		{"file2.txt", "data of /blob2"},
+	} {
+		hdr, err := tr.Next()
+		s.Require().NoError(err)
+		s.Require().Equal(expected.name, hdr.Name)
+
+		data, err := io.ReadAll(tr)
+		s.Require().NoError(err)
+		s.Require().Equal(expected.data, string(data))
+	}
+
+	_, err = tr.Next()
+	s.Require().Equal(io.EOF, err)
+}
+
+func (s *serviceTestSuite) TestExportVersionChecksumMismatch() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("corrupted"))
+	}))
+	defer srv.Close()
+
+	s.cliMock.On("ListObjects", defaultNamespace, "container1", "20240102030405").Return([]string{"file1.txt"}, nil, []*v1proto.Object{
+		{Key: "file1.txt", Size: 9, Checksum: "deadbeef"},
+	}).Once()
+	s.cliMock.On("GetObjectURL", defaultNamespace, "container1", "20240102030405", "file1.txt").Return(srv.URL+"/blob1", nil).Once()
+
+	output := filepath.Join(s.T().TempDir(), "export.zip")
+	fn := s.svc.ExportVersion(defaultNamespace, "container1", "20240102030405", "zip", output)
+	err := fn(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal(
+		"error exporting object `file1.txt`: checksum mismatch: expected deadbeef, got "+
+			"3dbb3963d11aa418de8b61f846c3dbd5af43b40d252842adb823f90936fe6920",
+		err.Error(),
+	)
+
+	// incomplete archive is removed
+	_, err = os.Stat(output)
+	s.Require().True(os.IsNotExist(err))
+}
+
 // Definitions ...
 type serviceTestSuite struct {
 	suite.Suite
@@ -10,6 +10,7 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/teran/archived/cli/lazyblob"
 	"github.com/teran/archived/cli/service/source"
@@ -26,11 +27,13 @@ type repository struct {
 	suites        []string
 	components    []string
 	architectures []string
+	concurrency   int
 }
 
-func New(repoURL string, suites, components, architectures []string) source.Source {
+func New(repoURL string, suites, components, architectures []string, concurrency int) source.Source {
 	log.WithFields(log.Fields{
-		"url": repoURL,
+		"url":         repoURL,
+		"concurrency": concurrency,
 	}).Trace("initializing APT source ...")
 
 	return &repository{
@@ -38,6 +41,7 @@ func New(repoURL string, suites, components, architectures []string) source.Sour
 		suites:        suites,
 		components:    components,
 		architectures: architectures,
+		concurrency:   concurrency,
 	}
 }
 
@@ -172,29 +176,32 @@ func (r *repository) Process(ctx context.Context, handler source.ObjectHandler)
 							}
 						}
 
+						// Packages are handed to the handler by the bounded pool
+						// of workers to let the consumer handle them concurrently
+						g, gCtx := errgroup.WithContext(ctx)
+						g.SetLimit(r.concurrency)
+
 						for _, pkg := range pkgs {
-							if err := func(pkg Package) error {
+							if gCtx.Err() != nil {
+								break
+							}
+
+							g.Go(func() error {
 								lb := lazyblob.New(r.repoURL+"/"+pkg.Filename, os.TempDir(), uint64(pkg.Size))
-								defer func() {
-									if err := lb.Close(); err != nil {
-										log.Warnf("error removing scratch data: %s", err)
-									}
-								}()
 
-								if err := handler(ctx, source.Object{
+								return handler(gCtx, source.Object{
 									Path:     pkg.Filename,
 									Contents: lb.Reader,
 									SHA256:   pkg.SHA256Sum,
 									Size:     uint64(pkg.Size),
 									MimeType: detectMimeTypeByFilename(pkg.Filename),
-								}); err != nil {
-									return err
-								}
+									Close:    lb.Close,
+								})
+							})
+						}
 
-								return nil
-							}(pkg); err != nil {
-								return err
-							}
+						if err := g.Wait(); err != nil {
+							return err
 						}
 					}
 					return nil
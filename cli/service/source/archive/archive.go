@@ -0,0 +1,414 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/cli/service/source"
+)
+
+const (
+	sourceType = "archive"
+
+	processStatusInterval = 100
+	magicLength           = 4
+
+	userAgent = "Mozilla/5.0 (compatible; archived-cli/archive; +https://github.com/teran/archived)"
+)
+
+var (
+	_ source.Source = (*archive)(nil)
+	_ io.Closer     = (*archive)(nil)
+
+	ErrMalformedPath = errors.New("malformed path")
+
+	zipMagic      = []byte("PK\x03\x04")
+	emptyZipMagic = []byte("PK\x05\x06")
+	gzipMagic     = []byte{0x1f, 0x8b}
+	zstdMagic     = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// archive reads objects from tar, tar.gz, tar.zst or zip archive stored
+// locally or available via HTTP(S). Tar archives are read in a single pass
+// with the data of each entry spooled to the temporary file owned by the
+// object while zip archives are read in place (downloaded ones are spooled
+// as a whole) so the source has to be closed once the objects are not
+// needed anymore.
+type archive struct {
+	location string
+	tempDir  string
+
+	mutex   sync.Mutex
+	zipFile *os.File
+	spooled bool
+}
+
+func New(location string) source.Source {
+	log.WithFields(log.Fields{
+		"location": location,
+	}).Trace("initializing archive source ...")
+
+	return &archive{
+		location: location,
+		tempDir:  os.TempDir(),
+	}
+}
+
+func (a *archive) Process(ctx context.Context, handler source.ObjectHandler) error {
+	log.WithFields(log.Fields{
+		"source_type": sourceType,
+		"location":    a.location,
+	}).Info("reading archive ...")
+
+	rc, err := a.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	br := bufio.NewReader(rc)
+	magic, err := br.Peek(magicLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return errors.Wrap(err, "error reading archive")
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, zipMagic), bytes.HasPrefix(magic, emptyZipMagic):
+		return a.processZip(ctx, rc, br, handler)
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return errors.Wrap(err, "error initializing gzip decoder")
+		}
+		defer func() { _ = gr.Close() }()
+
+		return a.processTar(ctx, gr, handler)
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return errors.Wrap(err, "error initializing zstd decoder")
+		}
+		defer zr.Close()
+
+		return a.processTar(ctx, zr, handler)
+	default:
+		return a.processTar(ctx, br, handler)
+	}
+}
+
+// Close removes the archive spooled to the temporary file (if any)
+func (a *archive) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.zipFile == nil {
+		return nil
+	}
+
+	if a.spooled {
+		if err := os.Remove(a.zipFile.Name()); err != nil {
+			log.WithFields(log.Fields{
+				"filename": a.zipFile.Name(),
+			}).Debug("error removing temporary file")
+		}
+	}
+
+	err := a.zipFile.Close()
+	a.zipFile = nil
+	return err
+}
+
+func (a *archive) open(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(a.location, "http://") && !strings.HasPrefix(a.location, "https://") {
+		fp, err := os.Open(a.location)
+		if err != nil {
+			return nil, errors.Wrap(err, "error opening archive")
+		}
+		return fp, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.location, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating request object")
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error performing HTTP request")
+	}
+
+	if resp.StatusCode > 299 {
+		_ = resp.Body.Close()
+		return nil, errors.Errorf("%s: unexpected HTTP response status: %s", a.location, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (a *archive) processTar(ctx context.Context, rd io.Reader, handler source.ObjectHandler) error {
+	tr := tar.NewReader(rd)
+
+	cnt := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return errors.Wrap(err, "error reading tar archive")
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			log.WithFields(log.Fields{
+				"name": hdr.Name,
+				"type": string(hdr.Typeflag),
+			}).Debug("skipping non-regular file ...")
+			continue
+		}
+
+		name, err := normalizePath(hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := a.processTarEntry(ctx, name, uint64(hdr.Size), tr, handler); err != nil {
+			return err
+		}
+
+		cnt++
+		if cnt%processStatusInterval == 0 {
+			log.WithFields(log.Fields{
+				"location": a.location,
+			}).Infof("%d files processed ...", cnt)
+		}
+	}
+	return nil
+}
+
+func (a *archive) processTarEntry(ctx context.Context, name string, size uint64, rd io.Reader, handler source.ObjectHandler) error {
+	tmp, err := os.CreateTemp(a.tempDir, "archive_*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "error creating temporary file")
+	}
+
+	filename := tmp.Name()
+	release := func() error {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "error removing temporary file")
+		}
+		return nil
+	}
+
+	// Ownership of the spooled data is passed to the handler along
+	// with the object so it's only removed here on early return
+	handedOff := false
+	defer func() {
+		if handedOff {
+			return
+		}
+
+		if err := release(); err != nil {
+			log.Warnf("error removing scratch data: %s", err)
+		}
+	}()
+
+	h := sha256.New()
+	head := &prefixBuffer{limit: 512}
+	n, err := io.Copy(io.MultiWriter(tmp, h, head), rd)
+	if err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "error reading `%s` from archive", name)
+	}
+
+	// the file is reopened on demand to avoid holding descriptor for
+	// each retained object
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "error closing temporary file")
+	}
+
+	if uint64(n) != size {
+		return errors.Errorf("`%s` size is %d bytes while only %d was read: early EOF", name, size, n)
+	}
+
+	handedOff = true
+	if err := handler(ctx, source.Object{
+		Path: name,
+		Contents: func(ctx context.Context) (io.Reader, error) {
+			// the reader is closed by the consumer
+			fp, err := os.Open(filename)
+			if err != nil {
+				return nil, errors.Wrap(err, "error opening temporary file")
+			}
+			return fp, nil
+		},
+		SHA256:   hex.EncodeToString(h.Sum(nil)),
+		Size:     size,
+		MimeType: mimetype.Detect(head.Bytes()).String(),
+		Close:    release,
+	}); err != nil {
+		return errors.Wrap(err, "error calling object handler")
+	}
+	return nil
+}
+
+func (a *archive) processZip(ctx context.Context, rc io.ReadCloser, br *bufio.Reader, handler source.ObjectHandler) error {
+	fp, err := a.zipReaderAt(rc, br)
+	if err != nil {
+		return err
+	}
+
+	st, err := fp.Stat()
+	if err != nil {
+		return errors.Wrap(err, "error performing stat on archive")
+	}
+
+	zr, err := zip.NewReader(fp, st.Size())
+	if err != nil {
+		return errors.Wrap(err, "error reading zip archive")
+	}
+
+	for i, f := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !f.Mode().IsRegular() {
+			log.WithFields(log.Fields{
+				"name": f.Name,
+				"mode": f.Mode().String(),
+			}).Debug("skipping non-regular file ...")
+			continue
+		}
+
+		name, err := normalizePath(f.Name)
+		if err != nil {
+			return err
+		}
+
+		checksum, mimeType, err := checksumZipEntry(f)
+		if err != nil {
+			return errors.Wrapf(err, "error reading `%s` from archive", name)
+		}
+
+		if err := handler(ctx, source.Object{
+			Path: name,
+			Contents: func(ctx context.Context) (io.Reader, error) {
+				// the reader is closed by the consumer
+				rc, err := f.Open()
+				if err != nil {
+					return nil, errors.Wrap(err, "error opening archive entry")
+				}
+				return rc, nil
+			},
+			SHA256:   checksum,
+			Size:     f.UncompressedSize64,
+			MimeType: mimeType,
+		}); err != nil {
+			return errors.Wrap(err, "error calling object handler")
+		}
+
+		if (i+1)%processStatusInterval == 0 {
+			log.WithFields(log.Fields{
+				"location": a.location,
+			}).Infof("%d files processed ...", i+1)
+		}
+	}
+	return nil
+}
+
+// zipReaderAt returns the archive file kept open until the source is
+// closed since zip entries are read in place. The downloaded archive is
+// spooled to the temporary file first to allow random access.
+func (a *archive) zipReaderAt(rc io.ReadCloser, br *bufio.Reader) (*os.File, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if fp, ok := rc.(*os.File); ok {
+		zf, err := os.Open(fp.Name())
+		if err != nil {
+			return nil, errors.Wrap(err, "error opening archive")
+		}
+		a.zipFile = zf
+		return zf, nil
+	}
+
+	tmp, err := os.CreateTemp(a.tempDir, "archive_*.zip")
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating temporary file")
+	}
+	a.zipFile = tmp
+	a.spooled = true
+
+	if _, err := io.Copy(tmp, br); err != nil {
+		return nil, errors.Wrap(err, "error downloading archive")
+	}
+	return tmp, nil
+}
+
+func checksumZipEntry(f *zip.File) (string, string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", "", errors.Wrap(err, "error opening archive entry")
+	}
+	defer func() { _ = rc.Close() }()
+
+	// zip reader verifies the entry size and CRC32 on EOF
+	h := sha256.New()
+	head := &prefixBuffer{limit: 512}
+	if _, err := io.Copy(io.MultiWriter(h, head), rc); err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), mimetype.Detect(head.Bytes()).String(), nil
+}
+
+// prefixBuffer keeps the first limit bytes written to detect MIME type
+type prefixBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *prefixBuffer) Write(p []byte) (int, error) {
+	if rest := b.limit - b.Len(); rest > 0 {
+		_, _ = b.Buffer.Write(p[:min(rest, len(p))])
+	}
+	return len(p), nil
+}
+
+// normalizePath returns the path relative to the archive root refusing
+// the ones pointing outside of it
+func normalizePath(name string) (string, error) {
+	p := path.Clean("/" + name)
+	if p == "/" {
+		return "", errors.Wrapf(ErrMalformedPath, "`%s`", name)
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", errors.Wrapf(ErrMalformedPath, "`%s`", name)
+		}
+	}
+	return strings.TrimPrefix(p, "/"), nil
+}
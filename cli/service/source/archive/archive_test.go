@@ -0,0 +1,334 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/teran/archived/cli/service/source"
+)
+
+func init() {
+	log.SetLevel(log.TraceLevel)
+}
+
+type testEntry struct {
+	name     string
+	data     string
+	typeflag byte
+}
+
+var testEntries = []testEntry{
+	{name: "./dir/", typeflag: tar.TypeDir},
+	{name: "./dir/file1.txt", data: "test data 1", typeflag: tar.TypeReg},
+	{name: "dir/link", data: "file1.txt", typeflag: tar.TypeSymlink},
+	{name: "file2.txt", data: "test data 2", typeflag: tar.TypeReg},
+	{name: "empty.txt", data: "", typeflag: tar.TypeReg},
+}
+
+var expectedObjects = []objectData{
+	{
+		Path:     "dir/file1.txt",
+		SHA256:   "05e8fdb3598f91bcc3ce41a196e587b4592c8cdfc371c217274bfda2d24b1b4e",
+		Size:     11,
+		MimeType: "text/plain; charset=utf-8",
+		Data:     "test data 1",
+	},
+	{
+		Path:     "empty.txt",
+		SHA256:   "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		Size:     0,
+		MimeType: "text/plain",
+		Data:     "",
+	},
+	{
+		Path:     "file2.txt",
+		SHA256:   "26637da1bd793f9011a3d304372a9ec44e36cc677d2bbfba32a2f31f912358fe",
+		Size:     11,
+		MimeType: "text/plain; charset=utf-8",
+		Data:     "test data 2",
+	},
+}
+
+func TestTar(t *testing.T) {
+	r := require.New(t)
+
+	buf := newTar(t, testEntries)
+	objects := processArchive(t, writeFile(t, "test.tar", buf))
+	r.Equal(expectedObjects, objects)
+}
+
+func TestTarTemporaryFilesRemoved(t *testing.T) {
+	r := require.New(t)
+
+	tempDir := t.TempDir()
+	src := New(writeFile(t, "test.tar", newTar(t, testEntries))).(*archive)
+	src.tempDir = tempDir
+
+	objects := collectObjects(t, src)
+	r.Equal(expectedObjects, objects)
+	r.NoError(src.Close())
+
+	entries, err := os.ReadDir(tempDir)
+	r.NoError(err)
+	r.Empty(entries)
+}
+
+func TestTarGzip(t *testing.T) {
+	r := require.New(t)
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write(newTar(t, testEntries))
+	r.NoError(err)
+	r.NoError(gw.Close())
+
+	objects := processArchive(t, writeFile(t, "test.tar.gz", buf.Bytes()))
+	r.Equal(expectedObjects, objects)
+}
+
+func TestTarZstd(t *testing.T) {
+	r := require.New(t)
+
+	buf := &bytes.Buffer{}
+	zw, err := zstd.NewWriter(buf)
+	r.NoError(err)
+	_, err = zw.Write(newTar(t, testEntries))
+	r.NoError(err)
+	r.NoError(zw.Close())
+
+	objects := processArchive(t, writeFile(t, "test.tar.zst", buf.Bytes()))
+	r.Equal(expectedObjects, objects)
+}
+
+func TestZip(t *testing.T) {
+	r := require.New(t)
+
+	objects := processArchive(t, writeFile(t, "test.zip", newZip(t, testEntries)))
+	r.Equal(expectedObjects, objects)
+}
+
+func TestZipFromURL(t *testing.T) {
+	r := require.New(t)
+
+	data := newZip(t, testEntries)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	tempDir := t.TempDir()
+	src := New(srv.URL + "/test.zip").(*archive)
+	src.tempDir = tempDir
+
+	objects := collectObjects(t, src)
+	r.Equal(expectedObjects, objects)
+
+	// spooled archive is removed on close
+	r.NoError(src.Close())
+	entries, err := os.ReadDir(tempDir)
+	r.NoError(err)
+	r.Empty(entries)
+}
+
+func TestTarFromURL(t *testing.T) {
+	r := require.New(t)
+
+	data := newTar(t, testEntries)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	objects := processArchive(t, srv.URL+"/test.tar")
+	r.Equal(expectedObjects, objects)
+}
+
+func TestMalformedPath(t *testing.T) {
+	r := require.New(t)
+
+	for _, name := range []string{"../file.txt", "dir/../../file.txt"} {
+		src := New(writeFile(t, "test.tar", newTar(t, []testEntry{
+			{name: name, data: "data", typeflag: tar.TypeReg},
+		})))
+
+		err := src.Process(context.Background(), func(ctx context.Context, obj source.Object) error {
+			return nil
+		})
+		r.Error(err)
+		r.ErrorIs(err, ErrMalformedPath)
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	r := require.New(t)
+
+	for in, out := range map[string]string{
+		"file.txt":           "file.txt",
+		"./dir/file.txt":     "dir/file.txt",
+		"/dir//file.txt":     "dir/file.txt",
+		"dir/./sub/file.txt": "dir/sub/file.txt",
+	} {
+		p, err := normalizePath(in)
+		r.NoError(err)
+		r.Equal(out, p)
+	}
+
+	for _, in := range []string{"", "/", "./", "../file.txt", "dir/../file.txt"} {
+		_, err := normalizePath(in)
+		r.ErrorIs(err, ErrMalformedPath)
+	}
+}
+
+// Definitions ...
+type objectData struct {
+	Path     string
+	SHA256   string
+	Size     uint64
+	MimeType string
+	Data     string
+}
+
+func processArchive(t *testing.T, location string) []objectData {
+	r := require.New(t)
+
+	src := New(location)
+	objects := collectObjects(t, src)
+	r.NoError(src.(io.Closer).Close())
+
+	return objects
+}
+
+// collectObjects retains the objects until the processing is done and reads
+// their contents afterwards like the version creation does
+func collectObjects(t *testing.T, src source.Source) []objectData {
+	r := require.New(t)
+
+	var mu sync.Mutex
+	retained := []source.Object{}
+
+	err := src.Process(context.Background(), func(ctx context.Context, obj source.Object) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		retained = append(retained, obj)
+		return nil
+	})
+	r.NoError(err)
+
+	objects := []objectData{}
+	for _, obj := range retained {
+		rd, err := obj.Contents(context.Background())
+		r.NoError(err)
+
+		data, err := io.ReadAll(rd)
+		r.NoError(err)
+
+		if c, ok := rd.(io.Closer); ok {
+			r.NoError(c.Close())
+		}
+
+		if obj.Close != nil {
+			r.NoError(obj.Close())
+		}
+
+		objects = append(objects, objectData{
+			Path:     obj.Path,
+			SHA256:   obj.SHA256,
+			Size:     obj.Size,
+			MimeType: obj.MimeType,
+			Data:     string(data),
+		})
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Path < objects[j].Path
+	})
+	return objects
+}
+
+func newTar(t *testing.T, entries []testEntry) []byte {
+	r := require.New(t)
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Mode:     0o644,
+		}
+
+		switch e.typeflag {
+		case tar.TypeReg:
+			hdr.Size = int64(len(e.data))
+		case tar.TypeSymlink:
+			hdr.Linkname = e.data
+		}
+
+		r.NoError(tw.WriteHeader(hdr))
+
+		if e.typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(e.data))
+			r.NoError(err)
+		}
+	}
+	r.NoError(tw.Close())
+
+	return buf.Bytes()
+}
+
+func newZip(t *testing.T, entries []testEntry) []byte {
+	r := require.New(t)
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		fh := &zip.FileHeader{
+			Name:   e.name,
+			Method: zip.Deflate,
+		}
+
+		switch e.typeflag {
+		case tar.TypeDir:
+			fh.SetMode(os.ModeDir | 0o755)
+		case tar.TypeSymlink:
+			fh.SetMode(os.ModeSymlink | 0o777)
+		default:
+			fh.SetMode(0o644)
+		}
+
+		w, err := zw.CreateHeader(fh)
+		r.NoError(err)
+
+		if e.typeflag != tar.TypeDir {
+			_, err = w.Write([]byte(e.data))
+			r.NoError(err)
+		}
+	}
+	r.NoError(zw.Close())
+
+	return buf.Bytes()
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	r := require.New(t)
+
+	filename := filepath.Join(t.TempDir(), name)
+	r.NoError(os.WriteFile(filename, data, 0o600))
+	return filename
+}
@@ -9,10 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/teran/archived/cli/service/source"
 	cache "github.com/teran/archived/cli/service/stat_cache"
@@ -27,18 +29,21 @@ const (
 var _ source.Source = (*local)(nil)
 
 type local struct {
-	dir   string
-	cache cache.CacheRepository
+	dir         string
+	cache       cache.CacheRepository
+	concurrency int
 }
 
-func New(dir string, c cache.CacheRepository) source.Source {
+func New(dir string, c cache.CacheRepository, concurrency int) source.Source {
 	log.WithFields(log.Fields{
-		"dir": dir,
+		"dir":         dir,
+		"concurrency": concurrency,
 	}).Trace("initializing local source ...")
 
 	return &local{
-		dir:   dir,
-		cache: c,
+		dir:         dir,
+		cache:       c,
+		concurrency: concurrency,
 	}
 }
 
@@ -48,90 +53,109 @@ func (l *local) Process(ctx context.Context, handler source.ObjectHandler) error
 		"directory":   l.dir,
 	}).Info("scanning directory ...")
 
-	var cnt int
+	var cnt atomic.Int64
 
-	return filepath.Walk(l.dir, func(path string, info fs.FileInfo, err error) error {
-		defer func() { cnt++ }()
+	// Files are checksummed by the bounded pool of workers while directory
+	// is being walked
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(l.concurrency)
 
+	err := filepath.Walk(l.dir, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return errors.Wrap(err, "walk: internal error")
 		}
 
+		if err := gCtx.Err(); err != nil {
+			return err
+		}
+
 		if info.IsDir() {
 			return nil
 		}
 
-		shortPath := strings.TrimPrefix(path, l.dir)
-		shortPath = strings.TrimPrefix(shortPath, "/")
-		size := info.Size()
+		g.Go(func() error {
+			if err := l.processFile(gCtx, path, info, handler); err != nil {
+				return err
+			}
 
-		log.WithFields(log.Fields{
-			"filename": shortPath,
-			"size":     size,
-		}).Debug("file found")
+			if n := cnt.Add(1); n%int64(processStatusInterval) == 0 {
+				log.WithFields(log.Fields{
+					"directory": l.dir,
+				}).Infof("%d files processed ...", n)
+			}
+			return nil
+		})
+		return nil
+	})
 
-		log.WithFields(log.Fields{
-			"filename": shortPath,
-			"size":     size,
-		}).Tracef("attempting to retrieve checksum from cache")
+	// Workers error is preferred since it's the cause of walk cancellation
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return err
+}
 
-		checksum, err := l.cache.Get(ctx, path, info)
-		if err != nil {
-			return errors.Wrap(err, "error retrieving checksum from cache")
-		}
+func (l *local) processFile(ctx context.Context, path string, info fs.FileInfo, handler source.ObjectHandler) error {
+	shortPath := strings.TrimPrefix(path, l.dir)
+	shortPath = strings.TrimPrefix(shortPath, "/")
+	size := info.Size()
 
-		if checksum == "" {
-			log.WithFields(log.Fields{
-				"filename": shortPath,
-				"size":     size,
-			}).Debug("generating checksum")
-			checksum, err = checksumFile(path)
-			if err != nil {
-				return errors.Wrap(err, "error calculating file checksum")
-			}
+	log.WithFields(log.Fields{
+		"filename": shortPath,
+		"size":     size,
+	}).Debug("file found")
 
-			err := l.cache.Put(ctx, path, info, checksum)
-			if err != nil {
-				log.Warnf("error putting checksum calculation result into cache: %s", err)
-			}
-		}
+	log.WithFields(log.Fields{
+		"filename": shortPath,
+		"size":     size,
+	}).Tracef("attempting to retrieve checksum from cache")
 
+	checksum, err := l.cache.Get(ctx, path, info)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving checksum from cache")
+	}
+
+	if checksum == "" {
 		log.WithFields(log.Fields{
 			"filename": shortPath,
 			"size":     size,
-			"checksum": checksum,
-		}).Debug("checksum")
-
-		mimeType, err := detectMimeType(path)
+		}).Debug("generating checksum")
+		checksum, err = checksumFile(path)
 		if err != nil {
-			return errors.Wrap(err, "error detecting MIME type")
+			return errors.Wrap(err, "error calculating file checksum")
 		}
 
-		fp, err := os.Open(path)
+		err := l.cache.Put(ctx, path, info, checksum)
 		if err != nil {
-			return errors.Wrap(err, "error opening file")
-		}
-		defer func() { _ = fp.Close() }()
-
-		if err := handler(ctx, source.Object{
-			Path: shortPath,
-			Contents: func(ctx context.Context) (io.Reader, error) {
-				return fp, nil
-			},
-			SHA256:   checksum,
-			Size:     uint64(size),
-			MimeType: mimeType,
-		}); err != nil {
-			return err
+			log.Warnf("error putting checksum calculation result into cache: %s", err)
 		}
+	}
 
-		if cnt%processStatusInterval == 0 {
-			log.WithFields(log.Fields{
-				"directory": l.dir,
-			}).Infof("%d files processed ...", cnt+1)
-		}
+	log.WithFields(log.Fields{
+		"filename": shortPath,
+		"size":     size,
+		"checksum": checksum,
+	}).Debug("checksum")
 
-		return nil
+	mimeType, err := detectMimeType(path)
+	if err != nil {
+		return errors.Wrap(err, "error detecting MIME type")
+	}
+
+	return handler(ctx, source.Object{
+		Path: shortPath,
+		Contents: func(ctx context.Context) (io.Reader, error) {
+			// File is opened on demand since the object could outlive
+			// the handler call; the reader is closed by the consumer
+			fp, err := os.Open(path)
+			if err != nil {
+				return nil, errors.Wrap(err, "error opening file")
+			}
+			return fp, nil
+		},
+		SHA256:   checksum,
+		Size:     uint64(size),
+		MimeType: mimeType,
 	})
 }
 
@@ -47,7 +47,7 @@ func TestSource(t *testing.T) {
 	handle.On("Handle", "some_file2.txt", uint64(18), "e45fbded5effe3178f7ca393f0228fb6799ead901c8a5b1354d6f1c44c2a8fa7", "application/octet-stream").Return(nil).Once()
 	handle.On("Handle", "some_dir/some_file3.txt", uint64(18), "94630c0572bba1a7dcc8e69a70ffbaaf132fc7f67f0c40416b73d53c10d9aa7a", "application/octet-stream").Return(nil).Once()
 
-	s := New("testdata/dir", statCache)
+	s := New("testdata/dir", statCache, 2)
 
 	err := s.Process(ctx, func(ctx context.Context, obj source.Object) error {
 		return handle.Handle(obj)
@@ -19,5 +19,15 @@ func New() *Mock {
 
 func (m *Mock) Process(ctx context.Context, handler source.ObjectHandler) error {
 	args := m.Called()
+
+	// Objects to pass to the handler could be optionally specified as
+	// the second return value
+	if len(args) > 1 {
+		for _, obj := range args.Get(1).([]source.Object) {
+			if err := handler(ctx, obj); err != nil {
+				return err
+			}
+		}
+	}
 	return args.Error(0)
 }
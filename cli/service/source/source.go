@@ -11,8 +11,16 @@ type Object struct {
 	SHA256   string
 	Size     uint64
 	MimeType string
+
+	// Close releases resources held by the object (if any) and is called by
+	// the handler's owner once the object is not needed anymore. Objects
+	// could be retained by the handler so Contents must remain usable after
+	// the handler returns until Close is called.
+	Close func() error
 }
 
+// ObjectHandler is called by source for each object found. Sources could
+// process objects concurrently so handler must be safe for concurrent use.
 type ObjectHandler func(ctx context.Context, obj Object) error
 
 type Source interface {
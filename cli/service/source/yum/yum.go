@@ -15,10 +15,12 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sassoftware/go-rpmutils"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/teran/archived/cli/lazyblob"
 	"github.com/teran/archived/cli/service/source"
 	yum "github.com/teran/archived/cli/service/source/yum/yum_repo"
+	"github.com/teran/archived/cli/service/source/yum/yum_repo/models"
 )
 
 const processStatusInterval = 100
@@ -34,13 +36,15 @@ type repository struct {
 	repoURL         string
 	rpmGPGKeyURL    *string
 	rpmGPGKeySHA256 *string
+	concurrency     int
 }
 
-func New(repoURL string, rpmGPGKeyURL, rpmGPGKeySHA256 *string) source.Source {
+func New(repoURL string, rpmGPGKeyURL, rpmGPGKeySHA256 *string, concurrency int) source.Source {
 	log.WithFields(log.Fields{
 		"url":            repoURL,
 		"gpg_key_url":    rpmGPGKeyURL,
 		"gpg_key_sha256": rpmGPGKeySHA256,
+		"concurrency":    concurrency,
 	}).Trace("initializing YUM source ...")
 
 	return &repository{
@@ -48,6 +52,7 @@ func New(repoURL string, rpmGPGKeyURL, rpmGPGKeySHA256 *string) source.Source {
 		repoURL:         repoURL,
 		rpmGPGKeyURL:    rpmGPGKeyURL,
 		rpmGPGKeySHA256: rpmGPGKeySHA256,
+		concurrency:     concurrency,
 	}
 }
 
@@ -109,7 +114,15 @@ func (r *repository) Process(ctx context.Context, handler source.ObjectHandler)
 		"packages_count": len(packages),
 	}).Info("handling package files ...")
 
+	// Packages are downloaded and verified by the bounded pool of workers
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(r.concurrency)
+
 	for cnt, pkg := range packages {
+		if gCtx.Err() != nil {
+			break
+		}
+
 		log.WithFields(log.Fields{
 			"name":     pkg.Name,
 			"checksum": pkg.Checksum,
@@ -117,64 +130,79 @@ func (r *repository) Process(ctx context.Context, handler source.ObjectHandler)
 			"size":     pkg.Size,
 		}).Trace("processing package ...")
 
-		err := func(name, checksum, sourceURL string, size uint64) error {
-			lb := lazyblob.New(sourceURL, os.TempDir(), size)
-			defer func() {
-				if err := lb.Close(); err != nil {
-					log.Warnf("error removing scratch data: %s", err)
-				}
-			}()
-
-			if pkg.ChecksumType != "sha256" {
-				filename, err := lb.Filename(ctx)
-				if err != nil {
-					return errors.Wrap(err, "error getting package filename")
-				}
-
-				checksum, err = checksumFile(filename)
-				if err != nil {
-					return errors.Wrap(err, "error calculating checksum")
-				}
-			}
-
-			if len(gpgKeyring) > 0 {
-				fp, err := lb.Reader(ctx)
-				if err != nil {
-					return errors.Wrap(err, "error getting object reader")
-				}
-
-				_, sigs, err := rpmutils.Verify(fp, gpgKeyring)
-				if err != nil {
-					return errors.Wrapf(err, "error verifying package signature: %s", name)
-				}
-
-				if len(sigs) == 0 {
-					log.Warnf("package `%s` does not contain signature but verification is requested", name)
-				}
-			}
-
-			if err := handler(ctx, source.Object{
-				Path:     name,
-				Contents: lb.Reader,
-				SHA256:   checksum,
-				Size:     size,
-				MimeType: detectMimeTypeByFilename(name),
-			}); err != nil {
-				return errors.Wrap(err, "error calling object handler")
-			}
-
-			if cnt%processStatusInterval == 0 {
-				log.WithFields(log.Fields{
-					"repository_url": r.repoURL,
-				}).Infof("%d files processed ...", cnt+1)
-			}
-
-			return nil
-		}(pkg.Name, pkg.Checksum, strings.TrimSuffix(r.repoURL, "/")+"/"+strings.TrimPrefix(pkg.Name, "/"), pkg.Size)
+		g.Go(func() error {
+			return r.processPackage(gCtx, cnt, pkg, gpgKeyring, handler)
+		})
+	}
+	return g.Wait()
+}
+
+func (r *repository) processPackage(ctx context.Context, cnt int, pkg models.Package, gpgKeyring openpgp.EntityList, handler source.ObjectHandler) error {
+	name := pkg.Name
+	checksum := pkg.Checksum
+	sourceURL := strings.TrimSuffix(r.repoURL, "/") + "/" + strings.TrimPrefix(pkg.Name, "/")
+
+	lb := lazyblob.New(sourceURL, os.TempDir(), pkg.Size)
+
+	// Ownership of the scratch data is passed to the handler along
+	// with the object so it's only removed here on early return
+	handedOff := false
+	defer func() {
+		if handedOff {
+			return
+		}
+
+		if err := lb.Close(); err != nil {
+			log.Warnf("error removing scratch data: %s", err)
+		}
+	}()
+
+	if pkg.ChecksumType != "sha256" {
+		filename, err := lb.Filename(ctx)
 		if err != nil {
-			return err
+			return errors.Wrap(err, "error getting package filename")
+		}
+
+		checksum, err = checksumFile(filename)
+		if err != nil {
+			return errors.Wrap(err, "error calculating checksum")
 		}
 	}
+
+	if len(gpgKeyring) > 0 {
+		fp, err := lb.Reader(ctx)
+		if err != nil {
+			return errors.Wrap(err, "error getting object reader")
+		}
+
+		_, sigs, err := rpmutils.Verify(fp, gpgKeyring)
+		if err != nil {
+			return errors.Wrapf(err, "error verifying package signature: %s", name)
+		}
+
+		if len(sigs) == 0 {
+			log.Warnf("package `%s` does not contain signature but verification is requested", name)
+		}
+	}
+
+	handedOff = true
+	if err := handler(ctx, source.Object{
+		Path:     name,
+		Contents: lb.Reader,
+		SHA256:   checksum,
+		Size:     pkg.Size,
+		MimeType: detectMimeTypeByFilename(name),
+		Close:    lb.Close,
+	}); err != nil {
+		return errors.Wrap(err, "error calling object handler")
+	}
+
+	if cnt%processStatusInterval == 0 {
+		log.WithFields(log.Fields{
+			"repository_url": r.repoURL,
+		}).Infof("%d files processed ...", cnt+1)
+	}
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package yum
 import (
 	"context"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	echo "github.com/labstack/echo/v4"
@@ -13,10 +14,14 @@ import (
 )
 
 func (s *yumTestSuite) TestRepo() {
+	var mu sync.Mutex
 	result := []source.Object{}
 
-	repo := New(s.srv.URL+"/repo/", nil, nil)
+	repo := New(s.srv.URL+"/repo/", nil, nil, 4)
 	err := repo.Process(s.ctx, func(ctx context.Context, obj source.Object) error {
+		mu.Lock()
+		defer mu.Unlock()
+
 		result = append(result, obj)
 		return nil
 	})
@@ -25,10 +30,14 @@ func (s *yumTestSuite) TestRepo() {
 }
 
 func (s *yumTestSuite) TestRepoWithGPGKey() {
+	var mu sync.Mutex
 	result := []source.Object{}
 
-	repo := New(s.srv.URL+"/repo-signed/", ptr.String(s.srv.URL+"/gpg/somekey.gpg"), nil)
+	repo := New(s.srv.URL+"/repo-signed/", ptr.String(s.srv.URL+"/gpg/somekey.gpg"), nil, 4)
 	err := repo.Process(s.ctx, func(ctx context.Context, obj source.Object) error {
+		mu.Lock()
+		defer mu.Unlock()
+
 		result = append(result, obj)
 		return nil
 	})
@@ -37,14 +46,19 @@ func (s *yumTestSuite) TestRepoWithGPGKey() {
 }
 
 func (s *yumTestSuite) TestRepoWithGPGKeyAndChecksum() {
+	var mu sync.Mutex
 	result := []source.Object{}
 
 	repo := New(
 		s.srv.URL+"/repo-signed/",
 		ptr.String(s.srv.URL+"/gpg/somekey.gpg"),
 		ptr.String("aa392a2005c38f10ce21034d6d1aaace5bbee1c3d98ac1ee06a42336d741473e"),
+		4,
 	)
 	err := repo.Process(s.ctx, func(ctx context.Context, obj source.Object) error {
+		mu.Lock()
+		defer mu.Unlock()
+
 		result = append(result, obj)
 		return nil
 	})
@@ -53,14 +67,19 @@ func (s *yumTestSuite) TestRepoWithGPGKeyAndChecksum() {
 }
 
 func (s *yumTestSuite) TestRepoWithGPGKeyAndInvalidChecksum() {
+	var mu sync.Mutex
 	result := []source.Object{}
 
 	repo := New(
 		s.srv.URL+"/repo-signed/",
 		ptr.String(s.srv.URL+"/gpg/somekey.gpg"),
 		ptr.String("invalid"),
+		4,
 	)
 	err := repo.Process(s.ctx, func(ctx context.Context, obj source.Object) error {
+		mu.Lock()
+		defer mu.Unlock()
+
 		result = append(result, obj)
 		return nil
 	})
@@ -72,10 +91,14 @@ func (s *yumTestSuite) TestRepoWithGPGKeyAndInvalidChecksum() {
 }
 
 func (s *yumTestSuite) TestRepoSHA1() {
+	var mu sync.Mutex
 	result := []source.Object{}
 
-	repo := New(s.srv.URL+"/repo/", nil, nil)
+	repo := New(s.srv.URL+"/repo/", nil, nil, 4)
 	err := repo.Process(s.ctx, func(ctx context.Context, obj source.Object) error {
+		mu.Lock()
+		defer mu.Unlock()
+
 		result = append(result, obj)
 		return nil
 	})
@@ -84,7 +107,8 @@ func (s *yumTestSuite) TestRepoSHA1() {
 }
 
 func (s *yumTestSuite) TestRepoWithGPGKeyMissedSignature() {
-	repo := New(s.srv.URL+"/repo/", ptr.String(s.srv.URL+"/gpg/somekey.gpg"), nil)
+	// Single worker to get the error for the first package deterministically
+	repo := New(s.srv.URL+"/repo/", ptr.String(s.srv.URL+"/gpg/somekey.gpg"), nil, 1)
 	err := repo.Process(s.ctx, func(ctx context.Context, obj source.Object) error {
 		return nil
 	})
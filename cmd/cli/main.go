@@ -15,15 +15,18 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
+	cliAuth "github.com/teran/archived/cli/auth"
 	"github.com/teran/archived/cli/router"
 	"github.com/teran/archived/cli/service"
 	"github.com/teran/archived/cli/service/source"
 	aptSource "github.com/teran/archived/cli/service/source/apt"
+	archiveSource "github.com/teran/archived/cli/service/source/archive"
 	localSource "github.com/teran/archived/cli/service/source/local"
 	yumSource "github.com/teran/archived/cli/service/source/yum"
 	"github.com/teran/archived/cli/service/source/yum/yum_repo/mirrorlist"
 	"github.com/teran/archived/cli/service/stat_cache/local"
 	v1proto "github.com/teran/archived/manager/presenter/grpc/proto/v1"
+	"github.com/teran/archived/models"
 )
 
 var (
@@ -58,6 +61,10 @@ var (
 				Default("false").
 				Bool()
 
+	token = app.Flag("token", "Bearer token to authenticate in Manager API").
+		Envar("ARCHIVED_CLI_TOKEN").
+		String()
+
 	cacheDir = app.Flag("cache-dir", "Stat-cache directory for objects").
 			Default("~/.cache/archived/cli/objects").
 			Envar("ARCHIVED_CLI_STAT_CACHE_DIR").
@@ -81,6 +88,12 @@ var (
 
 	namespaceList = namespace.Command("list", "list namespaces")
 
+	namespaceSet        = namespace.Command("set", "set parameters for namespace")
+	namespaceSetName    = namespaceSet.Arg("name", "name of the namespace").Required().String()
+	namespaceSetPrivate = namespaceSet.Flag("private", "Serve the namespace only to authorized publisher clients").
+				Default("false").
+				Bool()
+
 	container           = app.Command("container", "container operations")
 	containerCreate     = container.Command("create", "create new container")
 	containerCreateName = containerCreate.Arg("name", "name of the container to create").Required().String()
@@ -101,6 +114,19 @@ var (
 	containerSetContainer = containerSet.Arg("name", "name of the container").Required().String()
 	containerSetTTL       = containerSet.Flag("ttl", "Container TTL").Default("-1ns").Duration()
 
+	// retention policy is sent only if any of keep-* flags is passed so the
+	// existing policy is kept as is otherwise
+	containerSetRetentionIsSet bool
+
+	containerSetKeepLast = containerSet.Flag("keep-last", "Amount of the latest published versions to keep (0 to disable)").
+				Default("0").IsSetByUser(&containerSetRetentionIsSet).Uint32()
+	containerSetKeepDaily = containerSet.Flag("keep-daily", "Amount of the days to keep the latest published version for (0 to disable)").
+				Default("0").IsSetByUser(&containerSetRetentionIsSet).Uint32()
+	containerSetKeepWeekly = containerSet.Flag("keep-weekly", "Amount of the weeks to keep the latest published version for (0 to disable)").
+				Default("0").IsSetByUser(&containerSetRetentionIsSet).Uint32()
+	containerSetKeepMonthly = containerSet.Flag("keep-monthly", "Amount of the months to keep the latest published version for (0 to disable)").
+				Default("0").IsSetByUser(&containerSetRetentionIsSet).Uint32()
+
 	containerList = container.Command("list", "list containers")
 
 	version                = app.Command("version", "version operations")
@@ -111,6 +137,11 @@ var (
 				Bool()
 	versionCreateFromDir = versionCreate.Flag("from-dir", "create version right from directory").
 				String()
+	versionCreateResume = versionCreate.Flag("resume", "resume creation of the given unpublished version skipping objects already present").
+				String()
+	versionCreateConcurrency = versionCreate.Flag("concurrency", "amount of objects to checksum and upload in parallel").
+					Default("4").
+					Int()
 
 	versionCreateFromYumRepo = versionCreate.Flag("from-yum-repo", "create version right from yum repository").
 					String()
@@ -121,6 +152,9 @@ var (
 	versionCreateFromYumRepoGPGKeyChecksum = versionCreate.Flag("rpm-gpg-key-checksum", "SHA256 checksum for the GPG key provided").
 						String()
 
+	versionCreateFromArchive = versionCreate.Flag("from-archive", "create version right from tar, tar.gz, tar.zst or zip archive (path or URL)").
+					String()
+
 	versionCreateFromAptRepo = versionCreate.Flag("from-apt-repo", "create version right from apt repository").
 					String()
 	versionCreateFromAptRepoSuite = versionCreate.Flag("from-apt-repo-suite", "create version with suites").
@@ -130,6 +164,14 @@ var (
 	versionCreateFromAptRepoArchitecture = versionCreate.Flag("from-apt-repo-architecture", "create version with components").
 						Strings()
 
+	versionClone            = version.Command("clone", "create new unpublished version with the same objects as the given one")
+	versionCloneContainer   = versionClone.Arg("container", "name of the container to clone version from").Required().String()
+	versionCloneVersion     = versionClone.Arg("version", "version to clone").Required().String()
+	versionCloneToNamespace = versionClone.Flag("to-namespace", "namespace to create the new version in (source namespace by default)").
+				String()
+	versionCloneToContainer = versionClone.Flag("to-container", "container to create the new version in (source container by default)").
+				String()
+
 	versionDelete          = version.Command("delete", "delete the given version")
 	versionDeleteContainer = versionDelete.Arg("container", "name of the container to delete version of").Required().String()
 	versionDeleteVersion   = versionDelete.Arg("version", "version to delete").Required().String()
@@ -141,10 +183,53 @@ var (
 	versionPublishContainer = versionPublish.Arg("container", "name of the container to publish version for").Required().String()
 	versionPublishVersion   = versionPublish.Arg("version", "version to publish").Required().String()
 
+	versionProtect          = version.Command("protect", "protect the given version from deletion")
+	versionProtectContainer = versionProtect.Arg("container", "name of the container to protect version for").Required().String()
+	versionProtectVersion   = versionProtect.Arg("version", "version to protect").Required().String()
+
+	versionUnprotect          = version.Command("unprotect", "remove deletion protection from the given version")
+	versionUnprotectContainer = versionUnprotect.Arg("container", "name of the container to unprotect version for").Required().String()
+	versionUnprotectVersion   = versionUnprotect.Arg("version", "version to unprotect").Required().String()
+
+	versionDiff          = version.Command("diff", "show objects added, removed or changed between two versions")
+	versionDiffContainer = versionDiff.Arg("container", "name of the container to compare versions in").Required().String()
+	versionDiffFrom      = versionDiff.Arg("from-version", "version to compare from").Required().String()
+	versionDiffTo        = versionDiff.Arg("to-version", "version to compare to").Required().String()
+
+	versionExport          = version.Command("export", "export all the version objects to the archive")
+	versionExportContainer = versionExport.Arg("container", "name of the container to export version from").Required().String()
+	versionExportVersion   = versionExport.Arg("version", "version to export").Required().String()
+	versionExportFormat    = versionExport.Flag("format", "archive format").
+				Default("tar").
+				Enum("tar", "tar.zst", "zip")
+	versionExportOutput = versionExport.Flag("output", "path to write the archive to, `-` for stdout (<container>-<version>.<format> by default)").
+				Short('o').
+				String()
+
+	alias = app.Command("alias", "alias operations")
+
+	aliasCreate          = alias.Command("create", "create new alias pointing to the given version")
+	aliasCreateContainer = aliasCreate.Arg("container", "name of the container to create alias for").Required().String()
+	aliasCreateName      = aliasCreate.Arg("name", "name of the alias").Required().String()
+	aliasCreateVersion   = aliasCreate.Arg("version", "version the alias points to").Required().String()
+
+	aliasMove          = alias.Command("move", "point the given alias to another version")
+	aliasMoveContainer = aliasMove.Arg("container", "name of the container to move alias in").Required().String()
+	aliasMoveName      = aliasMove.Arg("name", "name of the alias").Required().String()
+	aliasMoveVersion   = aliasMove.Arg("version", "version the alias points to").Required().String()
+
+	aliasDelete          = alias.Command("delete", "delete the given alias")
+	aliasDeleteContainer = aliasDelete.Arg("container", "name of the container to delete alias from").Required().String()
+	aliasDeleteName      = aliasDelete.Arg("name", "name of the alias").Required().String()
+
+	aliasList          = alias.Command("list", "list aliases for the given container")
+	aliasListContainer = aliasList.Arg("container", "name of the container to list aliases for").Required().String()
+
 	object              = app.Command("object", "object operations")
 	objectList          = object.Command("list", "list objects in the given container and version")
 	objectListContainer = objectList.Arg("container", "name of the container to list objects from").Required().String()
 	objectListVersion   = objectList.Arg("version", "version to list objects from").Required().String()
+	objectListLong      = objectList.Flag("long", "print size, checksum, MIME type and creation time of the objects").Short('l').Bool()
 
 	objectURL          = object.Command("url", "get URL for the object")
 	objectURLContainer = objectURL.Arg("container", "name of the container to publish object from").Required().String()
@@ -156,6 +241,16 @@ var (
 	deleteObjectVersion   = deleteObject.Arg("version", "version to delete object from").Required().String()
 	deleteObjectKey       = deleteObject.Arg("key", "key of the object to delete").Required().String()
 
+	audit              = app.Command("audit", "audit log operations")
+	auditList          = audit.Command("list", "list audit events")
+	auditListActor     = auditList.Flag("actor", "Filter events by actor").String()
+	auditListOperation = auditList.Flag("operation", "Filter events by operation (e.g. DeleteContainer)").String()
+	auditListNamespace = auditList.Flag("filter-namespace", "Filter events by namespace").String()
+	auditListSince     = auditList.Flag("since", "List events happened within the given period (0 to disable)").
+				Default("0s").Duration()
+	auditListLimit = auditList.Flag("limit", "Maximum amount of events to list").
+			Default("100").Uint64()
+
 	statCache         = app.Command("stat-cache", "stat cache operations")
 	statCacheShowPath = statCache.Command("show-path", "print actual cache path")
 )
@@ -206,6 +301,10 @@ func main() {
 		})))
 	}
 
+	if *token != "" {
+		grpcOpts = append(grpcOpts, grpc.WithPerRPCCredentials(cliAuth.NewTokenCredentials(*token, !*insecureFlag)))
+	}
+
 	dial, err := grpc.NewClient(*manageEndpoint, grpcOpts...)
 	if err != nil {
 		panic(err)
@@ -231,9 +330,9 @@ func main() {
 	var src source.Source
 	switch {
 	case *versionCreateFromDir != "":
-		src = localSource.New(*versionCreateFromDir, cacheRepo)
+		src = localSource.New(*versionCreateFromDir, cacheRepo, *versionCreateConcurrency)
 	case *versionCreateFromYumRepo != "":
-		src = yumSource.New(*versionCreateFromYumRepo, versionCreateFromYumRepoGPGKey, versionCreateFromYumRepoGPGKeyChecksum)
+		src = yumSource.New(*versionCreateFromYumRepo, versionCreateFromYumRepoGPGKey, versionCreateFromYumRepoGPGKeyChecksum, *versionCreateConcurrency)
 	case *versionCreateFromYumMirrorlist != "":
 		ml, err := mirrorlist.New(ctx, *versionCreateFromYumMirrorlist)
 		if err != nil {
@@ -241,33 +340,61 @@ func main() {
 		}
 
 		yumRepository := ml.URL(mirrorlist.SelectModeRandom)
-		src = yumSource.New(yumRepository, versionCreateFromYumRepoGPGKey, versionCreateFromYumRepoGPGKeyChecksum)
+		src = yumSource.New(yumRepository, versionCreateFromYumRepoGPGKey, versionCreateFromYumRepoGPGKeyChecksum, *versionCreateConcurrency)
+	case *versionCreateFromArchive != "":
+		src = archiveSource.New(*versionCreateFromArchive)
 	case *versionCreateFromAptRepo != "":
-		src = aptSource.New(*versionCreateFromAptRepo, *versionCreateFromAptRepoSuite, *versionCreateFromAptRepoComponent, *versionCreateFromAptRepoArchitecture)
+		src = aptSource.New(*versionCreateFromAptRepo, *versionCreateFromAptRepoSuite, *versionCreateFromAptRepoComponent, *versionCreateFromAptRepoArchitecture, *versionCreateConcurrency)
 	}
 
 	r.Register(namespaceCreate.FullCommand(), cliSvc.CreateNamespace(*namespaceCreateName))
 	r.Register(namespaceRename.FullCommand(), cliSvc.RenameNamespace(*namespaceRenameOldName, *namespaceRenameNewName))
 	r.Register(namespaceList.FullCommand(), cliSvc.ListNamespaces())
 	r.Register(namespaceDelete.FullCommand(), cliSvc.DeleteNamespace(*namespaceDeleteName))
+	r.Register(namespaceSet.FullCommand(), cliSvc.SetNamespaceParameters(*namespaceSetName, *namespaceSetPrivate))
 
 	r.Register(containerCreate.FullCommand(), cliSvc.CreateContainer(*namespaceName, *containerCreateName, *containerCreateTTL))
 	r.Register(containerMove.FullCommand(), cliSvc.MoveContainer(*namespaceName, *containerMoveName, *containerMoveNamespace))
 	r.Register(containerRename.FullCommand(), cliSvc.RenameContainer(*namespaceName, *containerRenameOldName, *containerRenameNewName))
 	r.Register(containerList.FullCommand(), cliSvc.ListContainers(*namespaceName))
 	r.Register(containerDelete.FullCommand(), cliSvc.DeleteContainer(*namespaceName, *containerDeleteName))
-	r.Register(containerSet.FullCommand(), cliSvc.SetContainerParameters(*namespaceName, *containerSetContainer, *containerSetTTL))
+
+	var containerSetRetention *models.RetentionPolicy
+	if containerSetRetentionIsSet {
+		containerSetRetention = &models.RetentionPolicy{
+			KeepLast:    *containerSetKeepLast,
+			KeepDaily:   *containerSetKeepDaily,
+			KeepWeekly:  *containerSetKeepWeekly,
+			KeepMonthly: *containerSetKeepMonthly,
+		}
+	}
+	r.Register(containerSet.FullCommand(), cliSvc.SetContainerParameters(*namespaceName, *containerSetContainer, *containerSetTTL, containerSetRetention))
 
 	r.Register(versionList.FullCommand(), cliSvc.ListVersions(*namespaceName, *versionListContainer))
 	r.Register(versionCreate.FullCommand(), cliSvc.CreateVersion(
-		*namespaceName, *versionCreateContainer, *versionCreatePublish, src,
+		*namespaceName, *versionCreateContainer, *versionCreatePublish, src, *versionCreateConcurrency, *versionCreateResume,
+	))
+	r.Register(versionClone.FullCommand(), cliSvc.CloneVersion(
+		*namespaceName, *versionCloneContainer, *versionCloneVersion, *versionCloneToNamespace, *versionCloneToContainer,
 	))
 	r.Register(versionDelete.FullCommand(), cliSvc.DeleteVersion(*namespaceName, *versionDeleteContainer, *versionDeleteVersion))
 	r.Register(versionPublish.FullCommand(), cliSvc.PublishVersion(*namespaceName, *versionPublishContainer, *versionPublishVersion))
+	r.Register(versionProtect.FullCommand(), cliSvc.ProtectVersion(*namespaceName, *versionProtectContainer, *versionProtectVersion))
+	r.Register(versionUnprotect.FullCommand(), cliSvc.UnprotectVersion(*namespaceName, *versionUnprotectContainer, *versionUnprotectVersion))
+	r.Register(versionDiff.FullCommand(), cliSvc.DiffVersions(*namespaceName, *versionDiffContainer, *versionDiffFrom, *versionDiffTo))
+	r.Register(versionExport.FullCommand(), cliSvc.ExportVersion(
+		*namespaceName, *versionExportContainer, *versionExportVersion, *versionExportFormat, *versionExportOutput,
+	))
+
+	r.Register(aliasCreate.FullCommand(), cliSvc.CreateAlias(*namespaceName, *aliasCreateContainer, *aliasCreateName, *aliasCreateVersion))
+	r.Register(aliasMove.FullCommand(), cliSvc.MoveAlias(*namespaceName, *aliasMoveContainer, *aliasMoveName, *aliasMoveVersion))
+	r.Register(aliasDelete.FullCommand(), cliSvc.DeleteAlias(*namespaceName, *aliasDeleteContainer, *aliasDeleteName))
+	r.Register(aliasList.FullCommand(), cliSvc.ListAliases(*namespaceName, *aliasListContainer))
 
-	r.Register(objectList.FullCommand(), cliSvc.ListObjects(*namespaceName, *objectListContainer, *objectListVersion))
+	r.Register(objectList.FullCommand(), cliSvc.ListObjects(*namespaceName, *objectListContainer, *objectListVersion, *objectListLong))
 	r.Register(objectURL.FullCommand(), cliSvc.GetObjectURL(*namespaceName, *objectURLContainer, *objectURLVersion, *objectURLKey))
 	r.Register(deleteObject.FullCommand(), cliSvc.DeleteObject(*namespaceName, *deleteObjectContainer, *deleteObjectVersion, *deleteObjectKey))
+	r.Register(auditList.FullCommand(), cliSvc.ListAuditEvents(*auditListActor, *auditListOperation, *auditListNamespace, *auditListSince, *auditListLimit))
 	r.Register(statCacheShowPath.FullCommand(), func(ctx context.Context) error {
 		fmt.Println(*cacheDir)
 		return nil
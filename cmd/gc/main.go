@@ -2,15 +2,26 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"fmt"
+	"io"
+	"os"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/kelseyhightower/envconfig"
 	_ "github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/teran/archived/gc/service"
+	"github.com/teran/archived/repositories/blob"
+	awsBlobRepo "github.com/teran/archived/repositories/blob/aws"
+	filesystemBlobRepo "github.com/teran/archived/repositories/blob/filesystem"
 	"github.com/teran/archived/repositories/metadata/postgresql"
 )
 
@@ -19,12 +30,33 @@ var (
 	buildTimestamp = "undefined"
 )
 
+const (
+	blobBackendS3         = "s3"
+	blobBackendFilesystem = "filesystem"
+)
+
 type config struct {
 	LogLevel log.Level `envconfig:"LOG_LEVEL" default:"info"`
 
 	MetadataDSN string `envconfig:"METADATA_DSN" required:"true"`
 
+	BLOBBackend string `envconfig:"BLOB_BACKEND" default:"s3"`
+
+	BLOBS3Endpoint       string `envconfig:"BLOB_S3_ENDPOINT"`
+	BLOBS3Bucket         string `envconfig:"BLOB_S3_BUCKET"`
+	BLOBS3AccessKeyID    string `envconfig:"BLOB_S3_ACCESS_KEY_ID"`
+	BLOBS3SecretKey      string `envconfig:"BLOB_S3_SECRET_KEY"`
+	BLOBS3Region         string `envconfig:"BLOB_S3_REGION" default:"default"`
+	BLOBS3DisableSSL     bool   `envconfig:"BLOB_S3_DISABLE_SSL" default:"false"`
+	BLOBS3ForcePathStyle bool   `envconfig:"BLOB_S3_FORCE_PATH_STYLE" default:"true"`
+
+	BLOBFSRoot string `envconfig:"BLOB_FS_ROOT"`
+
 	UnpublishedVersionMaxAge time.Duration `envconfig:"UNPUBLISHED_VERSION_MAX_AGE" default:"168h"`
+	OrphanedBlobsGracePeriod time.Duration `envconfig:"ORPHANED_BLOBS_GRACE_PERIOD" default:"24h"`
+
+	DryRun     bool   `envconfig:"GC_DRY_RUN" default:"false"`
+	ReportFile string `envconfig:"GC_REPORT_FILE" default:""`
 }
 
 func main() {
@@ -50,9 +82,68 @@ func main() {
 
 	postgresqlRepo := postgresql.New(db)
 
+	var blobRepo blob.Repository
+	switch cfg.BLOBBackend {
+	case blobBackendS3:
+		if cfg.BLOBS3Endpoint == "" || cfg.BLOBS3Bucket == "" || cfg.BLOBS3AccessKeyID == "" || cfg.BLOBS3SecretKey == "" {
+			panic("BLOB_S3_ENDPOINT, BLOB_S3_BUCKET, BLOB_S3_ACCESS_KEY_ID and BLOB_S3_SECRET_KEY are required for s3 BLOB backend")
+		}
+
+		s3cfg, err := s3config.LoadDefaultConfig(context.TODO(),
+			s3config.WithRegion(cfg.BLOBS3Region),
+			s3config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(
+					cfg.BLOBS3AccessKeyID, cfg.BLOBS3SecretKey,
+					"",
+				),
+			),
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
+			o.UsePathStyle = cfg.BLOBS3ForcePathStyle
+			o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
+		})
+
+		// presigned links are not used by gc so TTL doesn't matter here
+		blobRepo = awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, time.Minute)
+	case blobBackendFilesystem:
+		// signed links are not issued by gc so random signing key is used
+		// and base URL doesn't matter here
+		signingKey := make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			panic(err)
+		}
+
+		blobRepo, err = filesystemBlobRepo.New(cfg.BLOBFSRoot, "", signingKey, time.Minute)
+		if err != nil {
+			panic(err)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported BLOB backend: `%s`", cfg.BLOBBackend))
+	}
+
+	var reportWriter io.Writer = os.Stdout
+	if cfg.ReportFile != "" {
+		fp, err := os.Create(cfg.ReportFile)
+		if err != nil {
+			panic(err)
+		}
+		defer func() { _ = fp.Close() }()
+
+		reportWriter = fp
+	}
+
 	svc, err := service.New(&service.Config{
 		MdRepo:                   postgresqlRepo,
+		BlobRepo:                 blobRepo,
 		UnpublishedVersionMaxAge: cfg.UnpublishedVersionMaxAge,
+		OrphanedBlobsGracePeriod: cfg.OrphanedBlobsGracePeriod,
+		DryRun:                   cfg.DryRun,
+		ReportWriter:             reportWriter,
 	})
 	if err != nil {
 		panic(err)
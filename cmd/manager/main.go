@@ -30,12 +30,22 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/teran/archived/manager/audit"
+	"github.com/teran/archived/manager/auth"
+	"github.com/teran/archived/manager/authz"
 	grpcManagePresenter "github.com/teran/archived/manager/presenter/grpc"
+	"github.com/teran/archived/repositories/blob"
 	awsBlobRepo "github.com/teran/archived/repositories/blob/aws"
+	filesystemBlobRepo "github.com/teran/archived/repositories/blob/filesystem"
 	"github.com/teran/archived/repositories/metadata/postgresql"
 	"github.com/teran/archived/service"
 )
 
+const (
+	blobBackendS3         = "s3"
+	blobBackendFilesystem = "filesystem"
+)
+
 var (
 	appVersion     = "n/a (dev build)"
 	buildTimestamp = "undefined"
@@ -49,15 +59,32 @@ type config struct {
 
 	MetadataDSN string `envconfig:"METADATA_DSN" required:"true"`
 
-	BLOBS3Endpoint         string        `envconfig:"BLOB_S3_ENDPOINT" required:"true"`
-	BLOBS3Bucket           string        `envconfig:"BLOB_S3_BUCKET" required:"true"`
+	BLOBBackend string `envconfig:"BLOB_BACKEND" default:"s3"`
+
+	BLOBS3Endpoint         string        `envconfig:"BLOB_S3_ENDPOINT"`
+	BLOBS3Bucket           string        `envconfig:"BLOB_S3_BUCKET"`
 	BLOBS3CreateBucket     bool          `envconfig:"BLOB_S3_CREATE_BUCKET" default:"false"`
 	BLOBS3PresignedLinkTTL time.Duration `envconfig:"BLOB_S3_PRESIGNED_LINK_TTL" default:"5m"`
-	BLOBS3AccessKeyID      string        `envconfig:"BLOB_S3_ACCESS_KEY_ID" required:"true"`
-	BLOBS3SecretKey        string        `envconfig:"BLOB_S3_SECRET_KEY" required:"true"`
+	BLOBS3AccessKeyID      string        `envconfig:"BLOB_S3_ACCESS_KEY_ID"`
+	BLOBS3SecretKey        string        `envconfig:"BLOB_S3_SECRET_KEY"`
 	BLOBS3Region           string        `envconfig:"BLOB_S3_REGION" default:"default"`
 	BLOBS3DisableSSL       bool          `envconfig:"BLOB_S3_DISABLE_SSL" default:"false"`
 	BLOBS3ForcePathStyle   bool          `envconfig:"BLOB_S3_FORCE_PATH_STYLE" default:"true"`
+
+	BLOBFSRoot       string        `envconfig:"BLOB_FS_ROOT"`
+	BLOBFSBaseURL    string        `envconfig:"BLOB_FS_BASE_URL"`
+	BLOBFSSigningKey string        `envconfig:"BLOB_FS_SIGNING_KEY"`
+	BLOBFSLinkTTL    time.Duration `envconfig:"BLOB_FS_LINK_TTL" default:"5m"`
+	BLOBFSAddr       string        `envconfig:"BLOB_FS_ADDR" default:":8082"`
+
+	AuthStaticTokens    map[string]string `envconfig:"AUTH_STATIC_TOKENS"`
+	AuthJWKSFile        string            `envconfig:"AUTH_JWKS_FILE"`
+	AuthJWTIssuer       string            `envconfig:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience     string            `envconfig:"AUTH_JWT_AUDIENCE"`
+	AuthJWTSubjectClaim string            `envconfig:"AUTH_JWT_SUBJECT_CLAIM" default:"sub"`
+	AuthJWTGroupsClaim  string            `envconfig:"AUTH_JWT_GROUPS_CLAIM" default:"groups"`
+
+	AuthzPolicyFile string `envconfig:"AUTHZ_POLICY_FILE"`
 }
 
 func main() {
@@ -86,38 +113,73 @@ func main() {
 	postgresqlRepo := postgresql.New(db)
 
 	ctx := context.TODO()
-	s3cfg, err := s3config.LoadDefaultConfig(ctx,
-		s3config.WithRegion(cfg.BLOBS3Region),
-		s3config.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(
-				cfg.BLOBS3AccessKeyID, cfg.BLOBS3SecretKey,
-				"",
-			),
-		),
-	)
-	if err != nil {
-		panic(err)
-	}
+	var blobRepo blob.Repository
+	switch cfg.BLOBBackend {
+	case blobBackendS3:
+		if cfg.BLOBS3Endpoint == "" || cfg.BLOBS3Bucket == "" || cfg.BLOBS3AccessKeyID == "" || cfg.BLOBS3SecretKey == "" {
+			panic("BLOB_S3_ENDPOINT, BLOB_S3_BUCKET, BLOB_S3_ACCESS_KEY_ID and BLOB_S3_SECRET_KEY are required for s3 BLOB backend")
+		}
 
-	s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
-		o.UsePathStyle = cfg.BLOBS3ForcePathStyle
-		o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
-	})
+		s3cfg, err := s3config.LoadDefaultConfig(ctx,
+			s3config.WithRegion(cfg.BLOBS3Region),
+			s3config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(
+					cfg.BLOBS3AccessKeyID, cfg.BLOBS3SecretKey,
+					"",
+				),
+			),
+		)
+		if err != nil {
+			panic(err)
+		}
 
-	if cfg.BLOBS3CreateBucket {
-		_, err := s3client.CreateBucket(ctx, &s3.CreateBucketInput{
-			Bucket: aws.String(cfg.BLOBS3Bucket),
+		s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
+			o.UsePathStyle = cfg.BLOBS3ForcePathStyle
+			o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
 		})
+
+		if cfg.BLOBS3CreateBucket {
+			_, err := s3client.CreateBucket(ctx, &s3.CreateBucketInput{
+				Bucket: aws.String(cfg.BLOBS3Bucket),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+		blobRepo = awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, cfg.BLOBS3PresignedLinkTTL)
+	case blobBackendFilesystem:
+		fsRepo, err := filesystemBlobRepo.New(cfg.BLOBFSRoot, cfg.BLOBFSBaseURL, []byte(cfg.BLOBFSSigningKey), cfg.BLOBFSLinkTTL)
 		if err != nil {
 			panic(err)
 		}
+		blobRepo = fsRepo
+
+		g.Go(func() error {
+			srv := &http.Server{
+				Addr:    cfg.BLOBFSAddr,
+				Handler: fsRepo,
+			}
+
+			return srv.ListenAndServe()
+		})
+	default:
+		panic(fmt.Sprintf("unsupported BLOB backend: `%s`", cfg.BLOBBackend))
 	}
-	blobRepo := awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, cfg.BLOBS3PresignedLinkTTL)
 
-	managerSvc := service.NewManager(postgresqlRepo, blobRepo)
+	managerSvc := audit.New(service.NewManager(postgresqlRepo, blobRepo), postgresqlRepo)
+
+	authorizer := authz.NewAllowAll()
+	if cfg.AuthzPolicyFile != "" {
+		authorizer, err = authz.NewFromFile(cfg.AuthzPolicyFile)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		log.Warn("no authorization policy configured: all the operations are allowed!")
+	}
 
-	managePresenter := grpcManagePresenter.New(managerSvc)
+	managePresenter := grpcManagePresenter.New(managerSvc, authorizer)
 
 	listener, err := net.Listen("tcp", cfg.Addr)
 	if err != nil {
@@ -137,17 +199,47 @@ func main() {
 		return nil
 	}
 
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		srvMetrics.UnaryServerInterceptor(grpcprom.WithExemplarFromContext(exemplarFromContext)),
+		logging.UnaryServerInterceptor(interceptorLogger()),
+		recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(grpcPanicRecoveryHandler)),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		srvMetrics.StreamServerInterceptor(grpcprom.WithExemplarFromContext(exemplarFromContext)),
+		logging.StreamServerInterceptor(interceptorLogger()),
+		recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(grpcPanicRecoveryHandler)),
+	}
+
+	authenticators := []auth.Authenticator{}
+	if len(cfg.AuthStaticTokens) > 0 {
+		authenticators = append(authenticators, auth.NewStatic(cfg.AuthStaticTokens))
+	}
+
+	if cfg.AuthJWKSFile != "" {
+		jwtAuth, err := auth.NewJWT(auth.JWTConfig{
+			JWKSFile:     cfg.AuthJWKSFile,
+			Issuer:       cfg.AuthJWTIssuer,
+			Audience:     cfg.AuthJWTAudience,
+			SubjectClaim: cfg.AuthJWTSubjectClaim,
+			GroupsClaim:  cfg.AuthJWTGroupsClaim,
+		})
+		if err != nil {
+			panic(err)
+		}
+		authenticators = append(authenticators, jwtAuth)
+	}
+
+	if len(authenticators) > 0 {
+		authenticator := auth.NewChain(authenticators...)
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(authenticator))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(authenticator))
+	} else {
+		log.Warn("no authentication methods configured: manager API is available without authentication!")
+	}
+
 	gs := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			srvMetrics.UnaryServerInterceptor(grpcprom.WithExemplarFromContext(exemplarFromContext)),
-			logging.UnaryServerInterceptor(interceptorLogger()),
-			recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(grpcPanicRecoveryHandler)),
-		),
-		grpc.ChainStreamInterceptor(
-			srvMetrics.StreamServerInterceptor(grpcprom.WithExemplarFromContext(exemplarFromContext)),
-			logging.StreamServerInterceptor(interceptorLogger()),
-			recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(grpcPanicRecoveryHandler)),
-		),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	)
 	managePresenter.Register(gs)
 
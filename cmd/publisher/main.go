@@ -2,9 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -22,13 +28,22 @@ import (
 	"github.com/teran/go-collection/random"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/teran/archived/publisher/access"
 	htmlPresenter "github.com/teran/archived/publisher/presenter/html"
+	jsonPresenter "github.com/teran/archived/publisher/presenter/json"
+	"github.com/teran/archived/repositories/blob"
 	awsBlobRepo "github.com/teran/archived/repositories/blob/aws"
+	filesystemBlobRepo "github.com/teran/archived/repositories/blob/filesystem"
 	"github.com/teran/archived/repositories/cache/metadata/memcache"
 	"github.com/teran/archived/repositories/metadata/postgresql"
 	"github.com/teran/archived/service"
 )
 
+const (
+	blobBackendS3         = "s3"
+	blobBackendFilesystem = "filesystem"
+)
+
 var (
 	appVersion     = "n/a (dev build)"
 	buildTimestamp = "undefined"
@@ -38,6 +53,12 @@ type config struct {
 	Addr        string `envconfig:"ADDR" default:":8080"`
 	MetricsAddr string `envconfig:"METRICS_ADDR" default:":8081"`
 
+	TLSCertFile     string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile      string `envconfig:"TLS_KEY_FILE"`
+	TLSClientCAFile string `envconfig:"TLS_CLIENT_CA_FILE"`
+
+	AccessConfigFile string `envconfig:"ACCESS_CONFIG_FILE"`
+
 	LogLevel log.Level `envconfig:"LOG_LEVEL" default:"info"`
 
 	MetadataDSN string `envconfig:"METADATA_DSN" required:"true"`
@@ -45,17 +66,26 @@ type config struct {
 	MemcacheServers []string      `envconfig:"MEMCACHE_SERVERS"`
 	MemcacheTTL     time.Duration `envconfig:"MEMCACHE_TTL" default:"60m"`
 
-	BLOBS3Endpoint         string        `envconfig:"BLOB_S3_ENDPOINT" required:"true"`
-	BLOBS3Bucket           string        `envconfig:"BLOB_S3_BUCKET" required:"true"`
+	BLOBBackend string `envconfig:"BLOB_BACKEND" default:"s3"`
+
+	BLOBS3Endpoint         string        `envconfig:"BLOB_S3_ENDPOINT"`
+	BLOBS3Bucket           string        `envconfig:"BLOB_S3_BUCKET"`
 	BLOBS3PresignedLinkTTL time.Duration `envconfig:"BLOB_S3_PRESIGNED_LINK_TTL" default:"5m"`
-	BLOBS3AccessKeyID      string        `envconfig:"BLOB_S3_ACCESS_KEY_ID" required:"true"`
-	BLOBS3SecretKey        string        `envconfig:"BLOB_S3_SECRET_KEY" required:"true"`
+	BLOBS3AccessKeyID      string        `envconfig:"BLOB_S3_ACCESS_KEY_ID"`
+	BLOBS3SecretKey        string        `envconfig:"BLOB_S3_SECRET_KEY"`
 	BLOBS3Region           string        `envconfig:"BLOB_S3_REGION" default:"default"`
 	BLOBS3DisableSSL       bool          `envconfig:"BLOB_S3_DISABLE_SSL" default:"false"`
 	BLOBS3ForcePathStyle   bool          `envconfig:"BLOB_S3_FORCE_PATH_STYLE" default:"true"`
 
 	BLOBS3PreserveSchemeOnRedirect bool `envconfig:"BLOB_S3_PRESERVE_SCHEME_ON_REDIRECT" default:"true"`
 
+	ServingMode htmlPresenter.ServingMode `envconfig:"SERVING_MODE" default:"redirect"`
+
+	BLOBFSRoot       string        `envconfig:"BLOB_FS_ROOT"`
+	BLOBFSBaseURL    string        `envconfig:"BLOB_FS_BASE_URL"`
+	BLOBFSSigningKey string        `envconfig:"BLOB_FS_SIGNING_KEY"`
+	BLOBFSLinkTTL    time.Duration `envconfig:"BLOB_FS_LINK_TTL" default:"5m"`
+
 	HTMLTemplateDir string `envconfig:"HTML_TEMPLATE_DIR" required:"true"`
 	StaticDir       string `envconfig:"STATIC_DIR" required:"true"`
 
@@ -114,40 +144,100 @@ func main() {
 
 	ctx := context.TODO()
 
-	s3cfg, err := s3config.LoadDefaultConfig(ctx,
-		s3config.WithRegion(cfg.BLOBS3Region),
-		s3config.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(
-				cfg.BLOBS3AccessKeyID,
-				cfg.BLOBS3SecretKey,
-				"",
+	var blobRepo blob.Repository
+	switch cfg.BLOBBackend {
+	case blobBackendS3:
+		if cfg.BLOBS3Endpoint == "" || cfg.BLOBS3Bucket == "" || cfg.BLOBS3AccessKeyID == "" || cfg.BLOBS3SecretKey == "" {
+			panic("BLOB_S3_ENDPOINT, BLOB_S3_BUCKET, BLOB_S3_ACCESS_KEY_ID and BLOB_S3_SECRET_KEY are required for s3 BLOB backend")
+		}
+
+		s3cfg, err := s3config.LoadDefaultConfig(ctx,
+			s3config.WithRegion(cfg.BLOBS3Region),
+			s3config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(
+					cfg.BLOBS3AccessKeyID,
+					cfg.BLOBS3SecretKey,
+					"",
+				),
 			),
-		),
-	)
-	if err != nil {
-		panic(err)
-	}
+		)
+		if err != nil {
+			panic(err)
+		}
 
-	s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
-		o.UsePathStyle = cfg.BLOBS3ForcePathStyle
-		o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
-	})
+		s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
+			o.UsePathStyle = cfg.BLOBS3ForcePathStyle
+			o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
+		})
 
-	blobRepo := awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, cfg.BLOBS3PresignedLinkTTL)
+		blobRepo = awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, cfg.BLOBS3PresignedLinkTTL)
+	case blobBackendFilesystem:
+		fsRepo, err := filesystemBlobRepo.New(cfg.BLOBFSRoot, cfg.BLOBFSBaseURL, []byte(cfg.BLOBFSSigningKey), cfg.BLOBFSLinkTTL)
+		if err != nil {
+			panic(err)
+		}
+		blobRepo = fsRepo
+
+		baseURL, err := url.Parse(cfg.BLOBFSBaseURL)
+		if err != nil {
+			panic(err)
+		}
+		e.Any(strings.TrimSuffix(baseURL.Path, "/")+"/*", echo.WrapHandler(fsRepo))
+	default:
+		panic(fmt.Sprintf("unsupported BLOB backend: `%s`", cfg.BLOBBackend))
+	}
 
 	publisherSvc := service.NewPublisher(repo, blobRepo, cfg.VersionsPerPage, cfg.ObjectsPerPage, cfg.ContainersPerPage)
 
-	p := htmlPresenter.New(publisherSvc, cfg.HTMLTemplateDir, cfg.StaticDir, cfg.BLOBS3PreserveSchemeOnRedirect, htmlPresenter.DisplayConfig{
+	authenticator := access.New(access.Config{})
+	if cfg.AccessConfigFile != "" {
+		authenticator, err = access.NewFromFile(cfg.AccessConfigFile)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		log.Info("no access config file specified: private namespaces won't be served to anyone")
+	}
+
+	if cfg.ServingMode != htmlPresenter.ServingModeRedirect && cfg.ServingMode != htmlPresenter.ServingModeProxy {
+		panic(fmt.Sprintf("unsupported serving mode: `%s`", cfg.ServingMode))
+	}
+
+	p := htmlPresenter.New(publisherSvc, authenticator, cfg.HTMLTemplateDir, cfg.StaticDir, cfg.BLOBS3PreserveSchemeOnRedirect, cfg.ServingMode, htmlPresenter.DisplayConfig{
 		DefaultTheme:         cfg.DefaultTheme,
 		MaxPagesInPagination: cfg.MaxPagesInPagination,
 	})
 	p.Register(e)
 
+	jsonPresenter.New(publisherSvc, authenticator).Register(e)
+
+	srv := &http.Server{
+		Addr:    cfg.Addr,
+		Handler: e,
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		caData, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			panic(err)
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caData) {
+			panic("no certificates found in TLS client CA file")
+		}
+
+		srv.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientAuth: tls.VerifyClientCertIfGiven,
+			ClientCAs:  clientCAs,
+		}
+	}
+
 	g.Go(func() error {
-		srv := &http.Server{
-			Addr:    cfg.Addr,
-			Handler: e,
+		if cfg.TLSCertFile != "" {
+			return srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
 		}
 
 		return srv.ListenAndServe()
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/kelseyhightower/envconfig"
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"github.com/teran/go-collection/applications/metrics"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/teran/archived/repositories/blob"
+	awsBlobRepo "github.com/teran/archived/repositories/blob/aws"
+	filesystemBlobRepo "github.com/teran/archived/repositories/blob/filesystem"
+	"github.com/teran/archived/repositories/metadata/postgresql"
+	"github.com/teran/archived/scrubber/service"
+)
+
+var (
+	appVersion     = "n/a (dev build)"
+	buildTimestamp = "undefined"
+)
+
+const (
+	blobBackendS3         = "s3"
+	blobBackendFilesystem = "filesystem"
+
+	modeScrub = "scrub"
+	modeCheck = "check"
+)
+
+type config struct {
+	Mode string `envconfig:"SCRUBBER_MODE" default:"scrub"`
+
+	Addr string `envconfig:"METRICS_ADDR" default:":8081"`
+
+	LogLevel log.Level `envconfig:"LOG_LEVEL" default:"info"`
+
+	MetadataDSN string `envconfig:"METADATA_DSN" required:"true"`
+
+	BLOBBackend string `envconfig:"BLOB_BACKEND" default:"s3"`
+
+	BLOBS3Endpoint       string `envconfig:"BLOB_S3_ENDPOINT"`
+	BLOBS3Bucket         string `envconfig:"BLOB_S3_BUCKET"`
+	BLOBS3AccessKeyID    string `envconfig:"BLOB_S3_ACCESS_KEY_ID"`
+	BLOBS3SecretKey      string `envconfig:"BLOB_S3_SECRET_KEY"`
+	BLOBS3Region         string `envconfig:"BLOB_S3_REGION" default:"default"`
+	BLOBS3DisableSSL     bool   `envconfig:"BLOB_S3_DISABLE_SSL" default:"false"`
+	BLOBS3ForcePathStyle bool   `envconfig:"BLOB_S3_FORCE_PATH_STYLE" default:"true"`
+
+	BLOBFSRoot string `envconfig:"BLOB_FS_ROOT"`
+
+	BatchSize uint64        `envconfig:"SCRUB_BATCH_SIZE" default:"100"`
+	RateLimit uint64        `envconfig:"SCRUB_RATE_LIMIT" default:"0"`
+	Interval  time.Duration `envconfig:"SCRUB_INTERVAL" default:"24h"`
+
+	CheckRepair          bool          `envconfig:"CHECK_REPAIR" default:"false"`
+	CheckReportFile      string        `envconfig:"CHECK_REPORT_FILE" default:""`
+	CheckLeakGracePeriod time.Duration `envconfig:"CHECK_LEAK_GRACE_PERIOD" default:"24h"`
+}
+
+func main() {
+	var cfg config
+	envconfig.MustProcess("", &cfg)
+
+	log.SetLevel(cfg.LogLevel)
+
+	lf := new(log.TextFormatter)
+	lf.FullTimestamp = true
+	log.SetFormatter(lf)
+
+	log.Infof("Initializing archived-scrubber (%s @ %s) ...", appVersion, buildTimestamp)
+
+	if cfg.Mode != modeScrub && cfg.Mode != modeCheck {
+		log.Fatalf("unexpected scrubber mode `%s`: only `%s` and `%s` are supported", cfg.Mode, modeScrub, modeCheck)
+	}
+
+	db, err := sql.Open("postgres", cfg.MetadataDSN)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := db.Ping(); err != nil {
+		panic(err)
+	}
+
+	postgresqlRepo := postgresql.New(db)
+
+	var blobRepo blob.Repository
+	switch cfg.BLOBBackend {
+	case blobBackendS3:
+		if cfg.BLOBS3Endpoint == "" || cfg.BLOBS3Bucket == "" || cfg.BLOBS3AccessKeyID == "" || cfg.BLOBS3SecretKey == "" {
+			panic("BLOB_S3_ENDPOINT, BLOB_S3_BUCKET, BLOB_S3_ACCESS_KEY_ID and BLOB_S3_SECRET_KEY are required for s3 BLOB backend")
+		}
+
+		s3cfg, err := s3config.LoadDefaultConfig(context.TODO(),
+			s3config.WithRegion(cfg.BLOBS3Region),
+			s3config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(
+					cfg.BLOBS3AccessKeyID, cfg.BLOBS3SecretKey,
+					"",
+				),
+			),
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
+			o.UsePathStyle = cfg.BLOBS3ForcePathStyle
+			o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
+		})
+
+		// presigned links are not used by scrubber so TTL doesn't matter here
+		blobRepo = awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, time.Minute)
+	case blobBackendFilesystem:
+		// signed links are not issued by scrubber so random signing key is used
+		// and base URL doesn't matter here
+		signingKey := make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			panic(err)
+		}
+
+		blobRepo, err = filesystemBlobRepo.New(cfg.BLOBFSRoot, "", signingKey, time.Minute)
+		if err != nil {
+			panic(err)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported BLOB backend: `%s`", cfg.BLOBBackend))
+	}
+
+	var reportWriter io.Writer = os.Stdout
+	if cfg.Mode == modeCheck && cfg.CheckReportFile != "" {
+		fp, err := os.Create(cfg.CheckReportFile)
+		if err != nil {
+			panic(err)
+		}
+		defer func() { _ = fp.Close() }()
+
+		reportWriter = fp
+	}
+
+	svc, err := service.New(&service.Config{
+		MdRepo:          postgresqlRepo,
+		BlobRepo:        blobRepo,
+		Registerer:      prometheus.DefaultRegisterer,
+		BatchSize:       cfg.BatchSize,
+		RateLimit:       cfg.RateLimit,
+		Interval:        cfg.Interval,
+		Repair:          cfg.CheckRepair,
+		LeakGracePeriod: cfg.CheckLeakGracePeriod,
+		ReportWriter:    reportWriter,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if cfg.Mode == modeCheck {
+		if err := svc.Check(context.Background()); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	g.Go(func() error {
+		return svc.Run(ctx)
+	})
+
+	me := echo.New()
+	me.Use(middleware.Logger())
+	me.Use(middleware.Recover())
+
+	checkFn := func() error {
+		if err := db.Ping(); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	metrics := metrics.New(checkFn, checkFn, checkFn)
+	metrics.Register(me)
+
+	g.Go(func() error {
+		srv := http.Server{
+			Addr:    cfg.Addr,
+			Handler: me,
+		}
+
+		return srv.ListenAndServe()
+	})
+
+	if err := g.Wait(); err != nil {
+		panic(err)
+	}
+}
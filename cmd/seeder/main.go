@@ -21,11 +21,18 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/teran/archived/repositories/blob"
 	awsBlobRepo "github.com/teran/archived/repositories/blob/aws"
+	filesystemBlobRepo "github.com/teran/archived/repositories/blob/filesystem"
 	"github.com/teran/archived/repositories/metadata/postgresql"
 	"github.com/teran/archived/service"
 )
 
+const (
+	blobBackendS3         = "s3"
+	blobBackendFilesystem = "filesystem"
+)
+
 var (
 	appVersion     = "n/a (dev build)"
 	buildTimestamp = "undefined"
@@ -36,16 +43,24 @@ type config struct {
 
 	MetadataDSN string `envconfig:"METADATA_DSN" required:"true"`
 
-	BLOBS3Endpoint         string        `envconfig:"BLOB_S3_ENDPOINT" required:"true"`
-	BLOBS3Bucket           string        `envconfig:"BLOB_S3_BUCKET" required:"true"`
+	BLOBBackend string `envconfig:"BLOB_BACKEND" default:"s3"`
+
+	BLOBS3Endpoint         string        `envconfig:"BLOB_S3_ENDPOINT"`
+	BLOBS3Bucket           string        `envconfig:"BLOB_S3_BUCKET"`
 	BLOBS3CreateBucket     bool          `envconfig:"BLOB_S3_CREATE_BUCKET" default:"false"`
 	BLOBS3PresignedLinkTTL time.Duration `envconfig:"BLOB_S3_PRESIGNED_LINK_TTL" default:"5m"`
-	BLOBS3AccessKeyID      string        `envconfig:"BLOB_S3_ACCESS_KEY_ID" required:"true"`
-	BLOBS3SecretKey        string        `envconfig:"BLOB_S3_SECRET_KEY" required:"true"`
+	BLOBS3AccessKeyID      string        `envconfig:"BLOB_S3_ACCESS_KEY_ID"`
+	BLOBS3SecretKey        string        `envconfig:"BLOB_S3_SECRET_KEY"`
 	BLOBS3Region           string        `envconfig:"BLOB_S3_REGION" default:"default"`
 	BLOBS3DisableSSL       bool          `envconfig:"BLOB_S3_DISABLE_SSL" default:"false"`
 	BLOBS3ForcePathStyle   bool          `envconfig:"BLOB_S3_FORCE_PATH_STYLE" default:"true"`
 
+	BLOBFSRoot       string        `envconfig:"BLOB_FS_ROOT"`
+	BLOBFSBaseURL    string        `envconfig:"BLOB_FS_BASE_URL"`
+	BLOBFSSigningKey string        `envconfig:"BLOB_FS_SIGNING_KEY"`
+	BLOBFSLinkTTL    time.Duration `envconfig:"BLOB_FS_LINK_TTL" default:"5m"`
+	BLOBFSAddr       string        `envconfig:"BLOB_FS_ADDR" default:":8082"`
+
 	CreateNamespaces             int `envconfig:"CREATE_NAMESPACES" default:"10"`
 	CreateContainersPerNamespace int `envconfig:"CREATE_CONTAINERS_PER_NAMESPACE" default:"100"`
 	CreateVersionsPerContainer   int `envconfig:"CREATE_VERSIONS_PER_CONTAINER" default:"100"`
@@ -78,35 +93,58 @@ func main() {
 
 	ctx := context.TODO()
 
-	s3cfg, err := s3config.LoadDefaultConfig(ctx,
-		s3config.WithRegion(cfg.BLOBS3Region),
-		s3config.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(
-				cfg.BLOBS3AccessKeyID,
-				cfg.BLOBS3SecretKey,
-				"",
-			),
-		),
-	)
-	if err != nil {
-		panic(err)
-	}
+	var blobRepo blob.Repository
+	switch cfg.BLOBBackend {
+	case blobBackendS3:
+		if cfg.BLOBS3Endpoint == "" || cfg.BLOBS3Bucket == "" || cfg.BLOBS3AccessKeyID == "" || cfg.BLOBS3SecretKey == "" {
+			panic("BLOB_S3_ENDPOINT, BLOB_S3_BUCKET, BLOB_S3_ACCESS_KEY_ID and BLOB_S3_SECRET_KEY are required for s3 BLOB backend")
+		}
 
-	s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
-		o.UsePathStyle = cfg.BLOBS3ForcePathStyle
-		o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
-	})
+		s3cfg, err := s3config.LoadDefaultConfig(ctx,
+			s3config.WithRegion(cfg.BLOBS3Region),
+			s3config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(
+					cfg.BLOBS3AccessKeyID,
+					cfg.BLOBS3SecretKey,
+					"",
+				),
+			),
+		)
+		if err != nil {
+			panic(err)
+		}
 
-	if cfg.BLOBS3CreateBucket {
-		_, err := s3client.CreateBucket(ctx, &s3.CreateBucketInput{
-			Bucket: aws.String(cfg.BLOBS3Bucket),
+		s3client := s3.NewFromConfig(s3cfg, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(cfg.BLOBS3Endpoint)
+			o.UsePathStyle = cfg.BLOBS3ForcePathStyle
+			o.EndpointOptions.DisableHTTPS = cfg.BLOBS3DisableSSL
 		})
+
+		if cfg.BLOBS3CreateBucket {
+			_, err := s3client.CreateBucket(ctx, &s3.CreateBucketInput{
+				Bucket: aws.String(cfg.BLOBS3Bucket),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+		blobRepo = awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, cfg.BLOBS3PresignedLinkTTL)
+	case blobBackendFilesystem:
+		fsRepo, err := filesystemBlobRepo.New(cfg.BLOBFSRoot, cfg.BLOBFSBaseURL, []byte(cfg.BLOBFSSigningKey), cfg.BLOBFSLinkTTL)
 		if err != nil {
 			panic(err)
 		}
+		blobRepo = fsRepo
+
+		go func() {
+			if err := http.ListenAndServe(cfg.BLOBFSAddr, fsRepo); err != nil {
+				panic(err)
+			}
+		}()
+	default:
+		panic(fmt.Sprintf("unsupported BLOB backend: `%s`", cfg.BLOBBackend))
 	}
-	blobRepo := awsBlobRepo.New(s3client, cfg.BLOBS3Bucket, cfg.BLOBS3PresignedLinkTTL)
+
 	managerSvc := service.NewManager(postgresqlRepo, blobRepo)
 
 	for i := 0; i <= cfg.CreateNamespaces; i++ {
@@ -164,6 +202,10 @@ func main() {
 						panic(errors.Errorf("unexpected status code on upload: %s", uploadResp.Status))
 					}
 
+					if err := managerSvc.ConfirmBLOB(ctx, casKey); err != nil {
+						panic(err)
+					}
+
 					if err := managerSvc.AddObject(ctx, namespace, container, version, key, casKey); err != nil {
 						panic(err)
 					}
@@ -1,21 +1,30 @@
 package service
 
 import (
+	"io"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 
+	"github.com/teran/archived/repositories/blob"
 	"github.com/teran/archived/repositories/metadata"
 )
 
 type Config struct {
 	MdRepo                   metadata.Repository
+	BlobRepo                 blob.Repository
 	UnpublishedVersionMaxAge time.Duration
+	OrphanedBlobsGracePeriod time.Duration
+	DryRun                   bool
+	ReportWriter             io.Writer
 }
 
 func (c Config) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.MdRepo, validation.Required),
+		validation.Field(&c.BlobRepo, validation.Required),
 		validation.Field(&c.UnpublishedVersionMaxAge, validation.Required, validation.Min(time.Hour)),
+		validation.Field(&c.OrphanedBlobsGracePeriod, validation.Required, validation.Min(time.Hour)),
+		validation.Field(&c.ReportWriter, validation.When(c.DryRun, validation.Required)),
 	)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	blobRepoMock "github.com/teran/archived/repositories/blob/mock"
 	mockRepo "github.com/teran/archived/repositories/metadata/mock"
 )
 
@@ -22,14 +23,41 @@ func TestConfigValidate(t *testing.T) {
 			name: "valid config",
 			in: &Config{
 				MdRepo:                   mockRepo.New(),
+				BlobRepo:                 blobRepoMock.New(),
 				UnpublishedVersionMaxAge: 10 * time.Hour,
+				OrphanedBlobsGracePeriod: 24 * time.Hour,
 			},
 		},
+		{
+			name: "too short grace period",
+			in: &Config{
+				MdRepo:                   mockRepo.New(),
+				BlobRepo:                 blobRepoMock.New(),
+				UnpublishedVersionMaxAge: 10 * time.Hour,
+				OrphanedBlobsGracePeriod: time.Minute,
+			},
+			expOut: errors.New(
+				"OrphanedBlobsGracePeriod: must be no less than 1h0m0s.",
+			),
+		},
+		{
+			name: "dry run without report writer",
+			in: &Config{
+				MdRepo:                   mockRepo.New(),
+				BlobRepo:                 blobRepoMock.New(),
+				UnpublishedVersionMaxAge: 10 * time.Hour,
+				OrphanedBlobsGracePeriod: 24 * time.Hour,
+				DryRun:                   true,
+			},
+			expOut: errors.New(
+				"ReportWriter: cannot be blank.",
+			),
+		},
 		{
 			name: "empty config",
 			in:   &Config{},
 			expOut: errors.New(
-				"MdRepo: cannot be blank; UnpublishedVersionMaxAge: cannot be blank.",
+				"BlobRepo: cannot be blank; MdRepo: cannot be blank; OrphanedBlobsGracePeriod: cannot be blank; UnpublishedVersionMaxAge: cannot be blank.",
 			),
 		},
 	}
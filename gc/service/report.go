@@ -0,0 +1,86 @@
+package service
+
+import (
+	"time"
+
+	"github.com/teran/archived/models"
+)
+
+const (
+	blobReasonOrphaned = "orphaned"
+
+	versionReasonExpired         = "expired"
+	versionReasonRetentionPolicy = "retention_policy"
+)
+
+type Report struct {
+	DryRun   bool            `json:"dry_run"`
+	Summary  ReportSummary   `json:"summary"`
+	Versions []ReportVersion `json:"versions"`
+	Blobs    []ReportBlob    `json:"blobs"`
+}
+
+type ReportSummary struct {
+	VersionsCount  uint64 `json:"versions_count"`
+	ObjectsCount   uint64 `json:"objects_count"`
+	BlobsCount     uint64 `json:"blobs_count"`
+	BytesReclaimed uint64 `json:"bytes_reclaimed"`
+}
+
+type ReportVersion struct {
+	Namespace   string         `json:"namespace"`
+	Container   string         `json:"container"`
+	Version     string         `json:"version"`
+	IsPublished bool           `json:"is_published"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Reason      string         `json:"reason"`
+	Objects     []ReportObject `json:"objects"`
+}
+
+type ReportObject struct {
+	Key      string `json:"key"`
+	Checksum string `json:"checksum,omitempty"`
+	Size     uint64 `json:"size,omitempty"`
+}
+
+type ReportBlob struct {
+	Checksum string `json:"checksum"`
+	Size     uint64 `json:"size"`
+	MimeType string `json:"mime_type"`
+	Reason   string `json:"reason"`
+}
+
+func (r *Report) addVersion(v models.ExpiredVersion, reason string) {
+	rv := ReportVersion{
+		Namespace:   v.Namespace,
+		Container:   v.Container,
+		Version:     v.Version.Name,
+		IsPublished: v.Version.IsPublished,
+		CreatedAt:   v.Version.CreatedAt,
+		Reason:      reason,
+		Objects:     []ReportObject{},
+	}
+
+	for _, o := range v.Objects {
+		rv.Objects = append(rv.Objects, ReportObject{
+			Key:      o.Key,
+			Checksum: o.Checksum,
+			Size:     o.Size,
+		})
+	}
+
+	r.Versions = append(r.Versions, rv)
+	r.Summary.VersionsCount++
+	r.Summary.ObjectsCount += uint64(len(rv.Objects))
+}
+
+func (r *Report) addBlob(b models.Blob, reason string) {
+	r.Blobs = append(r.Blobs, ReportBlob{
+		Checksum: b.Checksum,
+		Size:     b.Size,
+		MimeType: b.MimeType,
+		Reason:   reason,
+	})
+	r.Summary.BlobsCount++
+	r.Summary.BytesReclaimed += b.Size
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/teran/archived/models"
+)
+
+func versionsToDeleteByRetentionPolicy(versions []models.Version, policy models.RetentionPolicy) []models.Version {
+	if !policy.IsEnabled() {
+		return []models.Version{}
+	}
+
+	sorted := make([]models.Version, len(versions))
+	copy(sorted, versions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	keep := map[string]struct{}{}
+	for i := 0; i < len(sorted) && i < int(policy.KeepLast); i++ {
+		keep[sorted[i].Name] = struct{}{}
+	}
+
+	for _, rule := range []struct {
+		amount uint32
+		bucket func(v models.Version) string
+	}{
+		{
+			amount: policy.KeepDaily,
+			bucket: func(v models.Version) string {
+				return v.CreatedAt.UTC().Format("2006-01-02")
+			},
+		},
+		{
+			amount: policy.KeepWeekly,
+			bucket: func(v models.Version) string {
+				year, week := v.CreatedAt.UTC().ISOWeek()
+				return strconv.Itoa(year) + "-" + strconv.Itoa(week)
+			},
+		},
+		{
+			amount: policy.KeepMonthly,
+			bucket: func(v models.Version) string {
+				return v.CreatedAt.UTC().Format("2006-01")
+			},
+		},
+	} {
+		if rule.amount == 0 {
+			continue
+		}
+
+		buckets := map[string]struct{}{}
+		for _, v := range sorted {
+			b := rule.bucket(v)
+			if _, ok := buckets[b]; ok {
+				continue
+			}
+
+			if len(buckets) >= int(rule.amount) {
+				break
+			}
+
+			buckets[b] = struct{}{}
+			keep[v.Name] = struct{}{}
+		}
+	}
+
+	result := []models.Version{}
+	for _, v := range sorted {
+		if v.IsProtected {
+			continue
+		}
+
+		if _, ok := keep[v.Name]; !ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
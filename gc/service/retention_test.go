@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/teran/archived/models"
+)
+
+func TestVersionsToDeleteByRetentionPolicy(t *testing.T) {
+	type testCase struct {
+		name     string
+		versions []models.Version
+		policy   models.RetentionPolicy
+		expOut   []string
+	}
+
+	versions := []models.Version{
+		{Name: "20240101100000", IsPublished: true, CreatedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{Name: "20240115100000", IsPublished: true, IsProtected: true, CreatedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{Name: "20240201100000", IsPublished: true, CreatedAt: time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)},
+		{Name: "20240301100000", IsPublished: true, CreatedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+		{Name: "20240302100000", IsPublished: true, CreatedAt: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)},
+		{Name: "20240302110000", IsPublished: true, CreatedAt: time.Date(2024, 3, 2, 11, 0, 0, 0, time.UTC)},
+		{Name: "20240303100000", IsPublished: true, CreatedAt: time.Date(2024, 3, 3, 10, 0, 0, 0, time.UTC)},
+	}
+
+	tcs := []testCase{
+		{
+			name:     "disabled policy",
+			versions: versions,
+			policy:   models.RetentionPolicy{},
+			expOut:   []string{},
+		},
+		{
+			name:     "keep last",
+			versions: versions,
+			policy:   models.RetentionPolicy{KeepLast: 3},
+			expOut: []string{
+				"20240301100000",
+				"20240201100000",
+				"20240101100000",
+			},
+		},
+		{
+			name:     "keep more than available",
+			versions: versions,
+			policy:   models.RetentionPolicy{KeepLast: 30},
+			expOut:   []string{},
+		},
+		{
+			name:     "keep daily",
+			versions: versions,
+			policy:   models.RetentionPolicy{KeepDaily: 2},
+			expOut: []string{
+				"20240302100000",
+				"20240301100000",
+				"20240201100000",
+				"20240101100000",
+			},
+		},
+		{
+			name:     "keep weekly",
+			versions: versions,
+			policy:   models.RetentionPolicy{KeepWeekly: 2},
+			expOut: []string{
+				"20240302110000",
+				"20240302100000",
+				"20240301100000",
+				"20240101100000",
+			},
+		},
+		{
+			name:     "keep last and monthly",
+			versions: versions,
+			policy:   models.RetentionPolicy{KeepLast: 2, KeepMonthly: 12},
+			expOut: []string{
+				"20240302100000",
+				"20240301100000",
+				"20240101100000",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			names := []string{}
+			for _, v := range versionsToDeleteByRetentionPolicy(tc.versions, tc.policy) {
+				names = append(names, v.Name)
+			}
+			r.Equal(tc.expOut, names)
+		})
+	}
+}
@@ -2,9 +2,19 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+const (
+	orphanedBlobsBatchSize uint64 = 1000
+	versionsPageSize       uint64 = 1000
+	objectsPageSize        uint64 = 1000
 )
 
 type Service interface {
@@ -28,13 +38,29 @@ func New(cfg *Config) (Service, error) {
 }
 
 func (s *service) Run(ctx context.Context) error {
+	if s.cfg.DryRun {
+		log.Info("running garbage collection in dry-run mode ...")
+
+		return s.report(ctx)
+	}
+
 	log.Info("running garbage collection ...")
 
+	log.Debug("Running retention policies enforcement ...")
+	if err := s.applyRetentionPolicies(ctx); err != nil {
+		return errors.Wrap(err, "error applying retention policies")
+	}
+
 	log.Debug("Running expired versions collection ...")
 	if err := s.deleteExpiredVersions(ctx); err != nil {
 		return errors.Wrap(err, "error deleting expired versions")
 	}
 
+	log.Debug("Running orphaned blobs collection ...")
+	if err := s.deleteOrphanedBlobs(ctx); err != nil {
+		return errors.Wrap(err, "error deleting orphaned blobs")
+	}
+
 	return nil
 }
 
@@ -45,3 +71,245 @@ func (s *service) deleteExpiredVersions(ctx context.Context) error {
 
 	return nil
 }
+
+func (s *service) applyRetentionPolicies(ctx context.Context) error {
+	candidates, err := s.listRetentionCandidates(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range candidates {
+		log.WithFields(log.Fields{
+			"namespace": c.namespace,
+			"container": c.container,
+			"version":   c.version.Name,
+		}).Debug("deleting version by retention policy ...")
+
+		err := s.cfg.MdRepo.DeleteVersion(ctx, c.namespace, c.container, c.version.Name)
+		if errors.Is(err, metadata.ErrAliased) {
+			log.WithFields(log.Fields{
+				"namespace": c.namespace,
+				"container": c.container,
+				"version":   c.version.Name,
+			}).Info("version was aliased after listing, skipping")
+			continue
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "error deleting version `%s/%s/%s`", c.namespace, c.container, c.version.Name)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"versions": len(candidates),
+	}).Info("retention policies enforcement complete")
+
+	return nil
+}
+
+type retentionCandidate struct {
+	namespace string
+	container string
+	version   models.Version
+}
+
+func (s *service) listRetentionCandidates(ctx context.Context) ([]retentionCandidate, error) {
+	namespaces, err := s.cfg.MdRepo.ListNamespaces(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing namespaces")
+	}
+
+	candidates := []retentionCandidate{}
+	for _, namespace := range namespaces {
+		containers, err := s.cfg.MdRepo.ListContainers(ctx, namespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error listing containers in namespace `%s`", namespace)
+		}
+
+		for _, container := range containers {
+			if !container.RetentionPolicy.IsEnabled() {
+				continue
+			}
+
+			versions := []models.Version{}
+			var offset uint64
+			for {
+				total, page, err := s.cfg.MdRepo.ListPublishedVersionsByContainerAndPage(ctx, namespace, container.Name, offset, versionsPageSize)
+				if err != nil {
+					return nil, errors.Wrapf(err, "error listing versions of container `%s/%s`", namespace, container.Name)
+				}
+
+				versions = append(versions, page...)
+				offset += versionsPageSize
+				if offset >= total || len(page) == 0 {
+					break
+				}
+			}
+
+			aliases, err := s.cfg.MdRepo.ListAliases(ctx, namespace, container.Name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error listing aliases of container `%s/%s`", namespace, container.Name)
+			}
+
+			aliased := map[string]struct{}{}
+			for _, a := range aliases {
+				aliased[a.Version] = struct{}{}
+			}
+
+			for _, v := range versionsToDeleteByRetentionPolicy(versions, container.RetentionPolicy) {
+				if _, ok := aliased[v.Name]; ok {
+					continue
+				}
+
+				candidates = append(candidates, retentionCandidate{
+					namespace: namespace,
+					container: container.Name,
+					version:   v,
+				})
+			}
+		}
+	}
+
+	return candidates, nil
+}
+
+func (s *service) deleteOrphanedBlobs(ctx context.Context) error {
+	var (
+		blobsTotal uint64
+		bytesTotal uint64
+	)
+
+	for {
+		// blobs are marked as being deleted first so they couldn't be
+		// uploaded or referenced again while their data is being removed,
+		// the metadata is removed only after the data is gone and the blobs
+		// left marked on failure are picked up by the next run
+		blobs, err := s.cfg.MdRepo.MarkOrphanedBlobsDeleting(ctx, s.cfg.OrphanedBlobsGracePeriod, orphanedBlobsBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "error marking orphaned blobs as being deleted")
+		}
+
+		// the batch could be short because of the rows locked by concurrent
+		// transactions so only the empty one means there's nothing left
+		if len(blobs) == 0 {
+			break
+		}
+
+		checksums := []string{}
+		for _, b := range blobs {
+			log.WithFields(log.Fields{
+				"checksum": b.Checksum,
+				"size":     b.Size,
+			}).Debug("deleting orphaned blob ...")
+
+			if err := s.cfg.BlobRepo.DeleteBlob(ctx, b.Checksum); err != nil {
+				return errors.Wrapf(err, "error deleting blob `%s`", b.Checksum)
+			}
+
+			checksums = append(checksums, b.Checksum)
+			blobsTotal++
+			bytesTotal += b.Size
+		}
+
+		if err := s.cfg.MdRepo.DeleteBlobs(ctx, checksums...); err != nil {
+			return errors.Wrap(err, "error deleting orphaned blobs metadata")
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"blobs": blobsTotal,
+		"bytes": bytesTotal,
+	}).Info("orphaned blobs collection complete")
+
+	return nil
+}
+
+func (s *service) report(ctx context.Context) error {
+	r := &Report{
+		DryRun:   true,
+		Versions: []ReportVersion{},
+		Blobs:    []ReportBlob{},
+	}
+
+	versions, err := s.cfg.MdRepo.ListExpiredVersionsWithObjects(ctx, s.cfg.UnpublishedVersionMaxAge)
+	if err != nil {
+		return errors.Wrap(err, "error listing expired versions")
+	}
+
+	expired := map[string]struct{}{}
+	for _, v := range versions {
+		expired[v.Namespace+"/"+v.Container+"/"+v.Version.Name] = struct{}{}
+		r.addVersion(v, versionReasonExpired)
+	}
+
+	retentionCandidates, err := s.listRetentionCandidates(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range retentionCandidates {
+		if _, ok := expired[c.namespace+"/"+c.container+"/"+c.version.Name]; ok {
+			continue
+		}
+
+		ev := models.ExpiredVersion{
+			Namespace: c.namespace,
+			Container: c.container,
+			Version:   c.version,
+			Objects:   []models.Object{},
+		}
+
+		var offset uint64
+		for {
+			total, objects, err := s.cfg.MdRepo.ListObjects(ctx, c.namespace, c.container, c.version.Name, offset, objectsPageSize)
+			if err != nil {
+				return errors.Wrapf(err, "error listing objects of version `%s/%s/%s`", c.namespace, c.container, c.version.Name)
+			}
+
+			ev.Objects = append(ev.Objects, objects...)
+
+			offset += objectsPageSize
+			if offset >= total || len(objects) == 0 {
+				break
+			}
+		}
+
+		r.addVersion(ev, versionReasonRetentionPolicy)
+	}
+
+	// blobs released by the versions above are still referenced at the moment
+	// and their grace period starts only when the versions are actually deleted
+	// so the same predicate as the real run uses gives exactly the blobs
+	// to be deleted
+	var offset uint64
+	for {
+		blobs, err := s.cfg.MdRepo.ListOrphanedBlobs(ctx, s.cfg.OrphanedBlobsGracePeriod, offset, orphanedBlobsBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "error listing orphaned blobs")
+		}
+
+		for _, b := range blobs {
+			r.addBlob(b, blobReasonOrphaned)
+		}
+
+		if uint64(len(blobs)) < orphanedBlobsBatchSize {
+			break
+		}
+		offset += orphanedBlobsBatchSize
+	}
+
+	log.WithFields(log.Fields{
+		"versions":        r.Summary.VersionsCount,
+		"objects":         r.Summary.ObjectsCount,
+		"blobs":           r.Summary.BlobsCount,
+		"bytes_reclaimed": r.Summary.BytesReclaimed,
+	}).Info("dry-run report is ready")
+
+	enc := json.NewEncoder(s.cfg.ReportWriter)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return errors.Wrap(err, "error writing report")
+	}
+
+	return nil
+}
@@ -1,14 +1,19 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 	gtm "github.com/teran/go-collection/time/mock"
 
+	"github.com/teran/archived/models"
+	blobRepoMock "github.com/teran/archived/repositories/blob/mock"
+	"github.com/teran/archived/repositories/metadata"
 	repoMock "github.com/teran/archived/repositories/metadata/mock"
 )
 
@@ -17,39 +22,288 @@ func init() {
 }
 
 func (s *serviceTestSuite) TestDeleteUnpublishedExpiredVersions() {
+	s.repoMock.On("ListNamespaces").Return([]string{}, nil).Once()
 	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{}, nil).Once()
 
 	err := s.svc.Run(s.ctx)
 	s.Require().NoError(err)
 }
 
+func (s *serviceTestSuite) TestDeleteOrphanedBlobs() {
+	s.repoMock.On("ListNamespaces").Return([]string{}, nil).Once()
+	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{
+		{
+			Checksum: "deadbeef",
+			Size:     10,
+			MimeType: "text/plain",
+		},
+		{
+			Checksum: "cafebabe",
+			Size:     20,
+			MimeType: "application/json",
+		},
+	}, nil).Once()
+	s.blobRepoMock.On("DeleteBlob", "deadbeef").Return(nil).Once()
+	s.blobRepoMock.On("DeleteBlob", "cafebabe").Return(nil).Once()
+	s.repoMock.On("DeleteBlobs", []string{"deadbeef", "cafebabe"}).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{}, nil).Once()
+
+	err := s.svc.Run(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestDeleteOrphanedBlobsShortBatch() {
+	s.repoMock.On("ListNamespaces").Return([]string{}, nil).Once()
+	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{
+		{
+			Checksum: "deadbeef",
+			Size:     10,
+			MimeType: "text/plain",
+		},
+	}, nil).Once()
+	s.blobRepoMock.On("DeleteBlob", "deadbeef").Return(nil).Once()
+	s.repoMock.On("DeleteBlobs", []string{"deadbeef"}).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{
+		{
+			Checksum: "cafebabe",
+			Size:     20,
+			MimeType: "application/json",
+		},
+	}, nil).Once()
+	s.blobRepoMock.On("DeleteBlob", "cafebabe").Return(nil).Once()
+	s.repoMock.On("DeleteBlobs", []string{"cafebabe"}).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{}, nil).Once()
+
+	err := s.svc.Run(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestDeleteOrphanedBlobsBlobRepoError() {
+	s.repoMock.On("ListNamespaces").Return([]string{}, nil).Once()
+	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{
+		{
+			Checksum: "deadbeef",
+			Size:     10,
+			MimeType: "text/plain",
+		},
+	}, nil).Once()
+	s.blobRepoMock.On("DeleteBlob", "deadbeef").Return(errors.New("blah")).Once()
+
+	err := s.svc.Run(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("error deleting orphaned blobs: error deleting blob `deadbeef`: blah", err.Error())
+}
+
+func (s *serviceTestSuite) TestDeleteOrphanedBlobsMetadataError() {
+	s.repoMock.On("ListNamespaces").Return([]string{}, nil).Once()
+	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{}, errors.New("blah")).Once()
+
+	err := s.svc.Run(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("error deleting orphaned blobs: error marking orphaned blobs as being deleted: blah", err.Error())
+}
+
+func (s *serviceTestSuite) TestDeleteOrphanedBlobsDeleteMetadataError() {
+	s.repoMock.On("ListNamespaces").Return([]string{}, nil).Once()
+	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{
+		{
+			Checksum: "deadbeef",
+			Size:     10,
+			MimeType: "text/plain",
+		},
+	}, nil).Once()
+	s.blobRepoMock.On("DeleteBlob", "deadbeef").Return(nil).Once()
+	s.repoMock.On("DeleteBlobs", []string{"deadbeef"}).Return(errors.New("blah")).Once()
+
+	err := s.svc.Run(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("error deleting orphaned blobs: error deleting orphaned blobs metadata: blah", err.Error())
+}
+
+func (s *serviceTestSuite) TestApplyRetentionPolicies() {
+	s.repoMock.On("ListNamespaces").Return([]string{"default"}, nil).Once()
+	s.repoMock.On("ListContainers", "default").Return([]models.Container{
+		{Name: "no-policy"},
+		{Name: "with-policy", RetentionPolicy: models.RetentionPolicy{KeepLast: 1}},
+	}, nil).Once()
+	s.repoMock.On("ListPublishedVersionsByContainerAndPage", "default", "with-policy", uint64(0), uint64(1000)).Return(uint64(3), []models.Version{
+		{Name: "20240102030405", IsPublished: true, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Name: "20240101030405", IsPublished: true, CreatedAt: time.Date(2024, 1, 1, 3, 4, 5, 0, time.UTC)},
+		{Name: "20231231030405", IsPublished: true, CreatedAt: time.Date(2023, 12, 31, 3, 4, 5, 0, time.UTC)},
+	}, nil).Once()
+	s.repoMock.On("ListAliases", "default", "with-policy").Return([]models.Alias{
+		{Name: "stable", Version: "20231231030405"},
+	}, nil).Once()
+	s.repoMock.On("DeleteVersion", "default", "with-policy", "20240101030405").Return(nil).Once()
+	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{}, nil).Once()
+
+	err := s.svc.Run(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestApplyRetentionPoliciesSkipsAliasedVersion() {
+	s.repoMock.On("ListNamespaces").Return([]string{"default"}, nil).Once()
+	s.repoMock.On("ListContainers", "default").Return([]models.Container{
+		{Name: "with-policy", RetentionPolicy: models.RetentionPolicy{KeepLast: 1}},
+	}, nil).Once()
+	s.repoMock.On("ListPublishedVersionsByContainerAndPage", "default", "with-policy", uint64(0), uint64(1000)).Return(uint64(3), []models.Version{
+		{Name: "20240102030405", IsPublished: true, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Name: "20240101030405", IsPublished: true, CreatedAt: time.Date(2024, 1, 1, 3, 4, 5, 0, time.UTC)},
+		{Name: "20231231030405", IsPublished: true, CreatedAt: time.Date(2023, 12, 31, 3, 4, 5, 0, time.UTC)},
+	}, nil).Once()
+	s.repoMock.On("ListAliases", "default", "with-policy").Return([]models.Alias{}, nil).Once()
+	s.repoMock.On("DeleteVersion", "default", "with-policy", "20240101030405").Return(metadata.ErrAliased).Once()
+	s.repoMock.On("DeleteVersion", "default", "with-policy", "20231231030405").Return(nil).Once()
+	s.repoMock.On("DeleteExpiredVersionsWithObjects", 10*time.Hour).Return(nil).Once()
+	s.repoMock.On("MarkOrphanedBlobsDeleting", 24*time.Hour, uint64(1000)).Return([]models.Blob{}, nil).Once()
+
+	err := s.svc.Run(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestDryRunReport() {
+	buf := &bytes.Buffer{}
+
+	svc, err := New(&Config{
+		MdRepo:                   s.repoMock,
+		BlobRepo:                 s.blobRepoMock,
+		UnpublishedVersionMaxAge: 10 * time.Hour,
+		OrphanedBlobsGracePeriod: 24 * time.Hour,
+		DryRun:                   true,
+		ReportWriter:             buf,
+	})
+	s.Require().NoError(err)
+
+	s.repoMock.On("ListNamespaces").Return([]string{"default"}, nil).Once()
+	s.repoMock.On("ListContainers", "default").Return([]models.Container{
+		{Name: "with-policy", RetentionPolicy: models.RetentionPolicy{KeepLast: 1}},
+	}, nil).Once()
+	s.repoMock.On("ListPublishedVersionsByContainerAndPage", "default", "with-policy", uint64(0), uint64(1000)).Return(uint64(2), []models.Version{
+		{Name: "20240102030405", IsPublished: true, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Name: "20240101030405", IsPublished: true, CreatedAt: time.Date(2024, 1, 1, 3, 4, 5, 0, time.UTC)},
+	}, nil).Once()
+	s.repoMock.On("ListAliases", "default", "with-policy").Return([]models.Alias{}, nil).Once()
+	s.repoMock.On("ListObjects", "default", "with-policy", "20240101030405", uint64(0), uint64(1000)).Return(uint64(1), []models.Object{
+		{
+			Key:      "file3.txt",
+			Checksum: "abcdef01",
+			Size:     30,
+			MimeType: "text/plain",
+		},
+	}, nil).Once()
+	s.repoMock.On("ListExpiredVersionsWithObjects", 10*time.Hour).Return([]models.ExpiredVersion{
+		{
+			Namespace: "default",
+			Container: "test-container",
+			Version: models.Version{
+				Name:        "20240102030405",
+				IsPublished: true,
+				CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			Objects: []models.Object{
+				{
+					Key:      "file1.txt",
+					Checksum: "deadbeef",
+					Size:     10,
+					MimeType: "text/plain",
+				},
+				{
+					Key:      "file2.txt",
+					Checksum: "cafebabe",
+					Size:     20,
+					MimeType: "text/plain",
+				},
+			},
+		},
+	}, nil).Once()
+	s.repoMock.On("ListOrphanedBlobs", 24*time.Hour, uint64(0), uint64(1000)).Return([]models.Blob{
+		{
+			Checksum: "abcdef",
+			Size:     100,
+			MimeType: "application/octet-stream",
+		},
+	}, nil).Once()
+
+	err = svc.Run(s.ctx)
+	s.Require().NoError(err)
+	s.Require().JSONEq(`{
+		"dry_run": true,
+		"summary": {
+			"versions_count": 2,
+			"objects_count": 3,
+			"blobs_count": 1,
+			"bytes_reclaimed": 100
+		},
+		"versions": [
+			{
+				"namespace": "default",
+				"container": "test-container",
+				"version": "20240102030405",
+				"is_published": true,
+				"created_at": "2024-01-02T03:04:05Z",
+				"reason": "expired",
+				"objects": [
+					{"key": "file1.txt", "checksum": "deadbeef", "size": 10},
+					{"key": "file2.txt", "checksum": "cafebabe", "size": 20}
+				]
+			},
+			{
+				"namespace": "default",
+				"container": "with-policy",
+				"version": "20240101030405",
+				"is_published": true,
+				"created_at": "2024-01-01T03:04:05Z",
+				"reason": "retention_policy",
+				"objects": [
+					{"key": "file3.txt", "checksum": "abcdef01", "size": 30}
+				]
+			}
+		],
+		"blobs": [
+			{"checksum": "abcdef", "size": 100, "mime_type": "application/octet-stream", "reason": "orphaned"}
+		]
+	}`, buf.String())
+}
+
 // Definitions ...
 type serviceTestSuite struct {
 	suite.Suite
 
-	ctx      context.Context
-	svc      Service
-	repoMock *repoMock.Mock
-	tp       *gtm.TimeNowMock
+	ctx          context.Context
+	svc          Service
+	repoMock     *repoMock.Mock
+	blobRepoMock *blobRepoMock.Mock
+	tp           *gtm.TimeNowMock
 }
 
 func (s *serviceTestSuite) SetupTest() {
 	s.ctx = context.TODO()
 
 	s.repoMock = repoMock.New()
+	s.blobRepoMock = blobRepoMock.New()
 
 	s.tp = gtm.NewTimeNowMock()
 
 	var err error
 	s.svc, err = New(&Config{
 		MdRepo:                   s.repoMock,
+		BlobRepo:                 s.blobRepoMock,
 		UnpublishedVersionMaxAge: 10 * time.Hour,
+		OrphanedBlobsGracePeriod: 24 * time.Hour,
 	})
 	s.Require().NoError(err)
 }
 
 func (s *serviceTestSuite) TearDownTest() {
 	s.repoMock.AssertExpectations(s.T())
+	s.blobRepoMock.AssertExpectations(s.T())
 	s.tp.AssertExpectations(s.T())
 }
 
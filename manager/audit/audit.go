@@ -0,0 +1,320 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/manager/auth"
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
+	"github.com/teran/archived/service"
+)
+
+const anonymousActor = "anonymous"
+
+var _ service.Manager = (*manager)(nil)
+
+// manager wraps service.Manager and records all the mutating operations
+// to the audit log. Read-only operations are passed through as is.
+type manager struct {
+	service.Manager
+
+	repo metadata.Repository
+}
+
+func New(svc service.Manager, repo metadata.Repository) service.Manager {
+	return &manager{
+		Manager: svc,
+		repo:    repo,
+	}
+}
+
+func (m *manager) CreateNamespace(ctx context.Context, name string) error {
+	err := m.Manager.CreateNamespace(ctx, name)
+	m.record(ctx, "CreateNamespace", name, nil, err)
+	return err
+}
+
+func (m *manager) RenameNamespace(ctx context.Context, oldName, newName string) error {
+	err := m.Manager.RenameNamespace(ctx, oldName, newName)
+	m.record(ctx, "RenameNamespace", oldName, map[string]string{
+		"new_name": newName,
+	}, err)
+	return err
+}
+
+func (m *manager) DeleteNamespace(ctx context.Context, name string) error {
+	err := m.Manager.DeleteNamespace(ctx, name)
+	m.record(ctx, "DeleteNamespace", name, nil, err)
+	return err
+}
+
+func (m *manager) SetNamespacePrivate(ctx context.Context, name string, isPrivate bool) error {
+	err := m.Manager.SetNamespacePrivate(ctx, name, isPrivate)
+	m.record(ctx, "SetNamespacePrivate", name, map[string]string{
+		"is_private": strconv.FormatBool(isPrivate),
+	}, err)
+	return err
+}
+
+func (m *manager) CreateContainer(ctx context.Context, namespace, name string, ttl time.Duration) error {
+	err := m.Manager.CreateContainer(ctx, namespace, name, ttl)
+	m.record(ctx, "CreateContainer", namespace, map[string]string{
+		"container": name,
+		"ttl":       ttl.String(),
+	}, err)
+	return err
+}
+
+func (m *manager) MoveContainer(ctx context.Context, namespace, container, destNamespace string) error {
+	err := m.Manager.MoveContainer(ctx, namespace, container, destNamespace)
+	m.record(ctx, "MoveContainer", namespace, map[string]string{
+		"container":             container,
+		"destination_namespace": destNamespace,
+	}, err)
+	return err
+}
+
+func (m *manager) RenameContainer(ctx context.Context, namespace, oldName, newName string) error {
+	err := m.Manager.RenameContainer(ctx, namespace, oldName, newName)
+	m.record(ctx, "RenameContainer", namespace, map[string]string{
+		"container": oldName,
+		"new_name":  newName,
+	}, err)
+	return err
+}
+
+func (m *manager) DeleteContainer(ctx context.Context, namespace, name string) error {
+	err := m.Manager.DeleteContainer(ctx, namespace, name)
+	m.record(ctx, "DeleteContainer", namespace, map[string]string{
+		"container": name,
+	}, err)
+	return err
+}
+
+func (m *manager) SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error {
+	err := m.Manager.SetContainerParameters(ctx, namespace, name, ttl, retention)
+	params := map[string]string{
+		"container": name,
+		"ttl":       ttl.String(),
+	}
+	if retention != nil {
+		params["keep_last"] = strconv.FormatUint(uint64(retention.KeepLast), 10)
+		params["keep_daily"] = strconv.FormatUint(uint64(retention.KeepDaily), 10)
+		params["keep_weekly"] = strconv.FormatUint(uint64(retention.KeepWeekly), 10)
+		params["keep_monthly"] = strconv.FormatUint(uint64(retention.KeepMonthly), 10)
+	}
+	m.record(ctx, "SetContainerParameters", namespace, params, err)
+	return err
+}
+
+func (m *manager) CreateVersion(ctx context.Context, namespace, container string) (string, error) {
+	id, err := m.Manager.CreateVersion(ctx, namespace, container)
+	m.record(ctx, "CreateVersion", namespace, map[string]string{
+		"container": container,
+		"version":   id,
+	}, err)
+	return id, err
+}
+
+func (m *manager) CloneVersion(ctx context.Context, namespace, container, versionID, destNamespace, destContainer string) (string, error) {
+	id, err := m.Manager.CloneVersion(ctx, namespace, container, versionID, destNamespace, destContainer)
+	m.record(ctx, "CloneVersion", namespace, map[string]string{
+		"container":             container,
+		"version":               versionID,
+		"destination_namespace": destNamespace,
+		"destination_container": destContainer,
+		"new_version":           id,
+	}, err)
+	return id, err
+}
+
+func (m *manager) PublishVersion(ctx context.Context, namespace, container, id string) error {
+	err := m.Manager.PublishVersion(ctx, namespace, container, id)
+	m.record(ctx, "PublishVersion", namespace, map[string]string{
+		"container": container,
+		"version":   id,
+	}, err)
+	return err
+}
+
+func (m *manager) DeleteVersion(ctx context.Context, namespace, container, id string) error {
+	err := m.Manager.DeleteVersion(ctx, namespace, container, id)
+	m.record(ctx, "DeleteVersion", namespace, map[string]string{
+		"container": container,
+		"version":   id,
+	}, err)
+	return err
+}
+
+func (m *manager) ProtectVersion(ctx context.Context, namespace, container, id string) error {
+	err := m.Manager.ProtectVersion(ctx, namespace, container, id)
+	m.record(ctx, "ProtectVersion", namespace, map[string]string{
+		"container": container,
+		"version":   id,
+	}, err)
+	return err
+}
+
+func (m *manager) UnprotectVersion(ctx context.Context, namespace, container, id string) error {
+	err := m.Manager.UnprotectVersion(ctx, namespace, container, id)
+	m.record(ctx, "UnprotectVersion", namespace, map[string]string{
+		"container": container,
+		"version":   id,
+	}, err)
+	return err
+}
+
+func (m *manager) CreateAlias(ctx context.Context, namespace, container, name, versionID string) error {
+	err := m.Manager.CreateAlias(ctx, namespace, container, name, versionID)
+	m.record(ctx, "CreateAlias", namespace, map[string]string{
+		"container": container,
+		"alias":     name,
+		"version":   versionID,
+	}, err)
+	return err
+}
+
+func (m *manager) MoveAlias(ctx context.Context, namespace, container, name, versionID string) error {
+	err := m.Manager.MoveAlias(ctx, namespace, container, name, versionID)
+	m.record(ctx, "MoveAlias", namespace, map[string]string{
+		"container": container,
+		"alias":     name,
+		"version":   versionID,
+	}, err)
+	return err
+}
+
+func (m *manager) DeleteAlias(ctx context.Context, namespace, container, name string) error {
+	err := m.Manager.DeleteAlias(ctx, namespace, container, name)
+	m.record(ctx, "DeleteAlias", namespace, map[string]string{
+		"container": container,
+		"alias":     name,
+	}, err)
+	return err
+}
+
+func (m *manager) AddObject(ctx context.Context, namespace, container, versionID, key, casKey string) error {
+	err := m.Manager.AddObject(ctx, namespace, container, versionID, key, casKey)
+	m.record(ctx, "AddObject", namespace, map[string]string{
+		"container": container,
+		"version":   versionID,
+		"key":       key,
+		"checksum":  casKey,
+	}, err)
+	return err
+}
+
+func (m *manager) CreateObjects(ctx context.Context, namespace, container, versionID string, objects []models.Object) (map[string]string, error) {
+	urls, err := m.Manager.CreateObjects(ctx, namespace, container, versionID, objects)
+	m.record(ctx, "CreateObjects", namespace, map[string]string{
+		"container": container,
+		"version":   versionID,
+		"count":     strconv.Itoa(len(objects)),
+	}, err)
+	return urls, err
+}
+
+func (m *manager) DeleteObject(ctx context.Context, namespace, container, versionID, key string) error {
+	err := m.Manager.DeleteObject(ctx, namespace, container, versionID, key)
+	m.record(ctx, "DeleteObject", namespace, map[string]string{
+		"container": container,
+		"version":   versionID,
+		"key":       key,
+	}, err)
+	return err
+}
+
+func (m *manager) VerifyBLOBs(ctx context.Context, namespace, container, versionID string) (map[string]string, error) {
+	urls, err := m.Manager.VerifyBLOBs(ctx, namespace, container, versionID)
+	m.record(ctx, "VerifyBLOBs", namespace, map[string]string{
+		"container": container,
+		"version":   versionID,
+		"missing":   strconv.Itoa(len(urls)),
+	}, err)
+	return urls, err
+}
+
+// BLOBs are shared between namespaces so their operations are recorded
+// without namespace
+
+func (m *manager) EnsureBLOBPresenceOrGetUploadURL(ctx context.Context, checksum string, size uint64, mimeType string) (string, error) {
+	url, err := m.Manager.EnsureBLOBPresenceOrGetUploadURL(ctx, checksum, size, mimeType)
+	m.record(ctx, "EnsureBLOBPresenceOrGetUploadURL", "", map[string]string{
+		"checksum":  checksum,
+		"size":      strconv.FormatUint(size, 10),
+		"mime_type": mimeType,
+	}, err)
+	return url, err
+}
+
+func (m *manager) ConfirmBLOB(ctx context.Context, checksum string) error {
+	err := m.Manager.ConfirmBLOB(ctx, checksum)
+	m.record(ctx, "ConfirmBLOB", "", map[string]string{
+		"checksum": checksum,
+	}, err)
+	return err
+}
+
+func (m *manager) CreateMultipartUpload(ctx context.Context, checksum string, size uint64) (models.MultipartUpload, error) {
+	upload, err := m.Manager.CreateMultipartUpload(ctx, checksum, size)
+	m.record(ctx, "CreateMultipartUpload", "", map[string]string{
+		"checksum":  checksum,
+		"size":      strconv.FormatUint(size, 10),
+		"upload_id": upload.UploadID,
+	}, err)
+	return upload, err
+}
+
+func (m *manager) CompleteMultipartUpload(ctx context.Context, checksum, uploadID string, parts []models.UploadPart) error {
+	err := m.Manager.CompleteMultipartUpload(ctx, checksum, uploadID, parts)
+	m.record(ctx, "CompleteMultipartUpload", "", map[string]string{
+		"checksum":  checksum,
+		"upload_id": uploadID,
+		"parts":     strconv.Itoa(len(parts)),
+	}, err)
+	return err
+}
+
+func (m *manager) AbortMultipartUpload(ctx context.Context, checksum, uploadID string) error {
+	err := m.Manager.AbortMultipartUpload(ctx, checksum, uploadID)
+	m.record(ctx, "AbortMultipartUpload", "", map[string]string{
+		"checksum":  checksum,
+		"upload_id": uploadID,
+	}, err)
+	return err
+}
+
+func (m *manager) record(ctx context.Context, operation, namespace string, parameters map[string]string, opErr error) {
+	actor := anonymousActor
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		actor = identity.Subject
+	}
+
+	event := models.AuditEvent{
+		Actor:      actor,
+		Operation:  operation,
+		Namespace:  namespace,
+		Parameters: parameters,
+		Result:     models.AuditEventResultSuccess,
+	}
+
+	if opErr != nil {
+		event.Result = models.AuditEventResultFailure
+		event.Error = opErr.Error()
+	}
+
+	// The operation is already performed at this point so audit event must
+	// be written even if the client went away
+	if err := m.repo.CreateAuditEvent(context.WithoutCancel(ctx), event); err != nil {
+		log.WithFields(log.Fields{
+			"error":     err,
+			"actor":     actor,
+			"operation": operation,
+			"namespace": namespace,
+		}).Error("error writing audit event")
+	}
+}
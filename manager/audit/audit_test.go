@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/teran/archived/manager/auth"
+	"github.com/teran/archived/models"
+	repoMock "github.com/teran/archived/repositories/metadata/mock"
+	"github.com/teran/archived/service"
+)
+
+func (s *auditTestSuite) TestSuccessfulOperation() {
+	ctx := auth.NewContext(s.ctx, auth.Identity{Subject: "john"})
+
+	s.svcMock.On("CreateContainer", "default", "test-container", time.Hour).Return(nil).Once()
+	s.repoMock.On("CreateAuditEvent", models.AuditEvent{
+		Actor:     "john",
+		Operation: "CreateContainer",
+		Namespace: "default",
+		Parameters: map[string]string{
+			"container": "test-container",
+			"ttl":       "1h0m0s",
+		},
+		Result: models.AuditEventResultSuccess,
+	}).Return(nil).Once()
+
+	err := s.svc.CreateContainer(ctx, "default", "test-container", time.Hour)
+	s.Require().NoError(err)
+}
+
+func (s *auditTestSuite) TestFailedOperation() {
+	ctx := auth.NewContext(s.ctx, auth.Identity{Subject: "jane"})
+
+	s.svcMock.On("DeleteVersion", "default", "test-container", "20240102030405").Return(service.ErrProtected).Once()
+	s.repoMock.On("CreateAuditEvent", models.AuditEvent{
+		Actor:     "jane",
+		Operation: "DeleteVersion",
+		Namespace: "default",
+		Parameters: map[string]string{
+			"container": "test-container",
+			"version":   "20240102030405",
+		},
+		Result: models.AuditEventResultFailure,
+		Error:  "entity is protected",
+	}).Return(nil).Once()
+
+	err := s.svc.DeleteVersion(ctx, "default", "test-container", "20240102030405")
+	s.Require().Error(err)
+	s.Require().ErrorIs(err, service.ErrProtected)
+}
+
+func (s *auditTestSuite) TestAnonymousActor() {
+	s.svcMock.On("CreateNamespace", "test-namespace").Return(nil).Once()
+	s.repoMock.On("CreateAuditEvent", models.AuditEvent{
+		Actor:     "anonymous",
+		Operation: "CreateNamespace",
+		Namespace: "test-namespace",
+		Result:    models.AuditEventResultSuccess,
+	}).Return(nil).Once()
+
+	err := s.svc.CreateNamespace(s.ctx, "test-namespace")
+	s.Require().NoError(err)
+}
+
+func (s *auditTestSuite) TestAuditWriteErrorIsNotPropagated() {
+	s.svcMock.On("PublishVersion", "default", "test-container", "20240102030405").Return(nil).Once()
+	s.repoMock.On("CreateAuditEvent", models.AuditEvent{
+		Actor:     "anonymous",
+		Operation: "PublishVersion",
+		Namespace: "default",
+		Parameters: map[string]string{
+			"container": "test-container",
+			"version":   "20240102030405",
+		},
+		Result: models.AuditEventResultSuccess,
+	}).Return(errors.New("blah")).Once()
+
+	err := s.svc.PublishVersion(s.ctx, "default", "test-container", "20240102030405")
+	s.Require().NoError(err)
+}
+
+func (s *auditTestSuite) TestReadOnlyOperationIsNotRecorded() {
+	s.svcMock.On("ListNamespaces").Return([]string{"default"}, nil).Once()
+
+	namespaces, err := s.svc.ListNamespaces(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"default"}, namespaces)
+}
+
+func (s *auditTestSuite) TestBlobOperationIsRecordedWithoutNamespace() {
+	s.svcMock.On("ConfirmBLOB", "deadbeef").Return(nil).Once()
+	s.repoMock.On("CreateAuditEvent", models.AuditEvent{
+		Actor:     "anonymous",
+		Operation: "ConfirmBLOB",
+		Parameters: map[string]string{
+			"checksum": "deadbeef",
+		},
+		Result: models.AuditEventResultSuccess,
+	}).Return(nil).Once()
+
+	err := s.svc.ConfirmBLOB(s.ctx, "deadbeef")
+	s.Require().NoError(err)
+}
+
+// TestAllMutatingOperationsAreAudited fails when new method is added to
+// service.Manager without explicit decision on whether it has to be audited:
+// embedding passes it through silently otherwise
+func (s *auditTestSuite) TestAllMutatingOperationsAreAudited() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "audit.go", nil, 0)
+	s.Require().NoError(err)
+
+	audited := map[string]struct{}{}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+			continue
+		}
+
+		if star, ok := fn.Recv.List[0].Type.(*ast.StarExpr); ok {
+			if ident, ok := star.X.(*ast.Ident); ok && ident.Name == "manager" {
+				audited[fn.Name.Name] = struct{}{}
+			}
+		}
+	}
+
+	iface := reflect.TypeOf((*service.Manager)(nil)).Elem()
+	for i := 0; i < iface.NumMethod(); i++ {
+		name := iface.Method(i).Name
+
+		_, isAudited := audited[name]
+		_, isReadOnly := readOnlyOperations[name]
+
+		s.Require().Truef(isAudited != isReadOnly, "method `%s` must be either audited or listed as read-only operation", name)
+	}
+
+	for name := range readOnlyOperations {
+		_, ok := iface.MethodByName(name)
+		s.Require().Truef(ok, "read-only operation `%s` is not a part of service.Manager", name)
+	}
+}
+
+// Definitions ...
+var readOnlyOperations = map[string]struct{}{
+	"ListNamespaces":              {},
+	"ListPrivateNamespaces":       {},
+	"ListContainers":              {},
+	"ListContainersByPage":        {},
+	"ListAllVersions":             {},
+	"ListPublishedVersions":       {},
+	"ListPublishedVersionsByPage": {},
+	"GetVersion":                  {},
+	"DiffVersions":                {},
+	"ListAliases":                 {},
+	"ListObjects":                 {},
+	"ListObjectsByPage":           {},
+	"GetObjectURL":                {},
+	"GetObject":                   {},
+	"GetBlobURL":                  {},
+	"ExportVersion":               {},
+	"ListAuditEvents":             {},
+}
+
+type auditTestSuite struct {
+	suite.Suite
+
+	ctx      context.Context
+	svc      service.Manager
+	svcMock  *service.Mock
+	repoMock *repoMock.Mock
+}
+
+func (s *auditTestSuite) SetupTest() {
+	s.ctx = context.Background()
+
+	s.svcMock = service.NewMock()
+	s.repoMock = repoMock.New()
+
+	s.svc = New(s.svcMock, s.repoMock)
+}
+
+func (s *auditTestSuite) TearDownTest() {
+	s.svcMock.AssertExpectations(s.T())
+	s.repoMock.AssertExpectations(s.T())
+}
+
+func TestAuditTestSuite(t *testing.T) {
+	suite.Run(t, &auditTestSuite{})
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type Identity struct {
+	Subject string
+	Groups  []string
+}
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Identity, error)
+}
+
+type identityKey struct{}
+
+func NewContext(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+type chain []Authenticator
+
+func NewChain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+func (c chain) Authenticate(ctx context.Context, token string) (Identity, error) {
+	for _, a := range c {
+		identity, err := a.Authenticate(ctx, token)
+		if err == nil {
+			return identity, nil
+		}
+
+		if !errors.Is(err, ErrUnauthenticated) {
+			return Identity{}, err
+		}
+	}
+	return Identity{}, ErrUnauthenticated
+}
@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func (s *authTestSuite) TestStatic() {
+	a := NewStatic(map[string]string{
+		"ci":    "ci-token",
+		"admin": "admin-token",
+	})
+
+	identity, err := a.Authenticate(s.ctx, "ci-token")
+	s.Require().NoError(err)
+	s.Require().Equal(Identity{Subject: "ci"}, identity)
+
+	_, err = a.Authenticate(s.ctx, "unknown-token")
+	s.Require().Error(err)
+	s.Require().Equal(ErrUnauthenticated, err)
+
+	_, err = a.Authenticate(s.ctx, "")
+	s.Require().Error(err)
+	s.Require().Equal(ErrUnauthenticated, err)
+}
+
+func (s *authTestSuite) TestJWT() {
+	a, err := NewJWT(JWTConfig{
+		JWKSFile: "testdata/jwks.json",
+		Issuer:   "https://issuer.example.com",
+		Audience: "archived",
+	})
+	s.Require().NoError(err)
+
+	type testCase struct {
+		name     string
+		token    string
+		expIdent Identity
+		expErr   error
+	}
+
+	tcs := []testCase{
+		{
+			name: "RSA key",
+			token: s.signRSA(jwt.MapClaims{
+				"iss":    "https://issuer.example.com",
+				"aud":    "archived",
+				"sub":    "user@example.com",
+				"groups": []string{"admins", "developers"},
+				"exp":    time.Now().Add(time.Hour).Unix(),
+			}, "test-rsa"),
+			expIdent: Identity{
+				Subject: "user@example.com",
+				Groups:  []string{"admins", "developers"},
+			},
+		},
+		{
+			name: "EC key",
+			token: s.signEC(jwt.MapClaims{
+				"iss": "https://issuer.example.com",
+				"aud": "archived",
+				"sub": "robot",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}, "test-ec"),
+			expIdent: Identity{
+				Subject: "robot",
+			},
+		},
+		{
+			name: "expired token",
+			token: s.signRSA(jwt.MapClaims{
+				"iss": "https://issuer.example.com",
+				"aud": "archived",
+				"sub": "user@example.com",
+				"exp": time.Now().Add(-1 * time.Hour).Unix(),
+			}, "test-rsa"),
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "no expiration",
+			token: s.signRSA(jwt.MapClaims{
+				"iss": "https://issuer.example.com",
+				"aud": "archived",
+				"sub": "user@example.com",
+			}, "test-rsa"),
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "wrong audience",
+			token: s.signRSA(jwt.MapClaims{
+				"iss": "https://issuer.example.com",
+				"aud": "something-else",
+				"sub": "user@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}, "test-rsa"),
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "wrong issuer",
+			token: s.signRSA(jwt.MapClaims{
+				"iss": "https://evil.example.com",
+				"aud": "archived",
+				"sub": "user@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}, "test-rsa"),
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "unknown key id",
+			token: s.signRSA(jwt.MapClaims{
+				"iss": "https://issuer.example.com",
+				"aud": "archived",
+				"sub": "user@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}, "unknown"),
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "key id mismatch",
+			token: s.signRSA(jwt.MapClaims{
+				"iss": "https://issuer.example.com",
+				"aud": "archived",
+				"sub": "user@example.com",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}, "test-ec"),
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name:   "garbage",
+			token:  "not-a-jwt",
+			expErr: ErrUnauthenticated,
+		},
+	}
+
+	for _, tc := range tcs {
+		s.Run(tc.name, func() {
+			identity, err := a.Authenticate(s.ctx, tc.token)
+			if tc.expErr != nil {
+				s.Require().Error(err)
+				s.Require().Equal(tc.expErr, err)
+				return
+			}
+
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expIdent, identity)
+		})
+	}
+}
+
+func (s *authTestSuite) TestJWTErrors() {
+	_, err := NewJWT(JWTConfig{
+		JWKSFile: "testdata/not-existent.json",
+	})
+	s.Require().Error(err)
+
+	_, err = NewJWT(JWTConfig{
+		JWKSFile: "testdata/private.pem",
+	})
+	s.Require().Error(err)
+}
+
+func (s *authTestSuite) TestChain() {
+	jwtAuth, err := NewJWT(JWTConfig{
+		JWKSFile: "testdata/jwks.json",
+	})
+	s.Require().NoError(err)
+
+	a := NewChain(NewStatic(map[string]string{"ci": "ci-token"}), jwtAuth)
+
+	identity, err := a.Authenticate(s.ctx, "ci-token")
+	s.Require().NoError(err)
+	s.Require().Equal(Identity{Subject: "ci"}, identity)
+
+	identity, err = a.Authenticate(s.ctx, s.signRSA(jwt.MapClaims{
+		"sub": "user@example.com",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}, "test-rsa"))
+	s.Require().NoError(err)
+	s.Require().Equal(Identity{Subject: "user@example.com"}, identity)
+
+	_, err = a.Authenticate(s.ctx, "unknown-token")
+	s.Require().Error(err)
+	s.Require().Equal(ErrUnauthenticated, err)
+}
+
+func (s *authTestSuite) TestUnaryServerInterceptor() {
+	interceptor := UnaryServerInterceptor(NewStatic(map[string]string{"ci": "ci-token"}))
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		identity, ok := IdentityFromContext(ctx)
+		s.Require().True(ok)
+		return identity.Subject, nil
+	}
+
+	resp, err := interceptor(
+		metadata.NewIncomingContext(s.ctx, metadata.Pairs("authorization", "Bearer ci-token")),
+		nil, &grpc.UnaryServerInfo{}, handler,
+	)
+	s.Require().NoError(err)
+	s.Require().Equal("ci", resp)
+
+	_, err = interceptor(s.ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = Unauthenticated desc = missing credentials", err.Error())
+
+	_, err = interceptor(
+		metadata.NewIncomingContext(s.ctx, metadata.Pairs("authorization", "Basic Y2k6Y2ktdG9rZW4=")),
+		nil, &grpc.UnaryServerInfo{}, handler,
+	)
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = Unauthenticated desc = unsupported authorization scheme", err.Error())
+
+	_, err = interceptor(
+		metadata.NewIncomingContext(s.ctx, metadata.Pairs("authorization", "Bearer wrong-token")),
+		nil, &grpc.UnaryServerInfo{}, handler,
+	)
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = Unauthenticated desc = invalid credentials", err.Error())
+}
+
+// Definitions ...
+type authTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+}
+
+func (s *authTestSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func (s *authTestSuite) signRSA(claims jwt.MapClaims, kid string) string {
+	data, err := os.ReadFile("testdata/private.pem")
+	s.Require().NoError(err)
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	s.Require().NoError(err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	v, err := token.SignedString(key)
+	s.Require().NoError(err)
+	return v
+}
+
+func (s *authTestSuite) signEC(claims jwt.MapClaims, kid string) string {
+	data, err := os.ReadFile("testdata/private_ec.pem")
+	s.Require().NoError(err)
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	s.Require().NoError(err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+
+	v, err := token.SignedString(key)
+	s.Require().NoError(err)
+	return v
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, &authTestSuite{})
+}
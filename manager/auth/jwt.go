@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSubjectClaim = "sub"
+	defaultGroupsClaim  = "groups"
+)
+
+type JWTConfig struct {
+	JWKSFile     string
+	Issuer       string
+	Audience     string
+	SubjectClaim string
+	GroupsClaim  string
+}
+
+type jwtAuthenticator struct {
+	cfg    JWTConfig
+	keys   map[string]crypto.PublicKey
+	parser *jwt.Parser
+}
+
+func NewJWT(cfg JWTConfig) (Authenticator, error) {
+	if cfg.SubjectClaim == "" {
+		cfg.SubjectClaim = defaultSubjectClaim
+	}
+
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = defaultGroupsClaim
+	}
+
+	keys, err := loadJWKS(cfg.JWKSFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading JWKS")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+	}
+
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &jwtAuthenticator{
+		cfg:    cfg,
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+func (j *jwtAuthenticator) Authenticate(_ context.Context, token string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := j.parser.ParseWithClaims(token, claims, j.keyFunc)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Debug("error validating JWT")
+		return Identity{}, ErrUnauthenticated
+	}
+
+	subject, ok := claims[j.cfg.SubjectClaim].(string)
+	if !ok || subject == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	identity := Identity{Subject: subject}
+	if groups, ok := claims[j.cfg.GroupsClaim].([]any); ok {
+		for _, g := range groups {
+			if v, ok := g.(string); ok {
+				identity.Groups = append(identity.Groups, v)
+			}
+		}
+	}
+
+	return identity, nil
+}
+
+func (j *jwtAuthenticator) keyFunc(t *jwt.Token) (any, error) {
+	kid, ok := t.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("kid header is missing")
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, errors.Errorf("unknown key `%s`", kid)
+	}
+	return key, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func loadJWKS(filename string) (map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading file")
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, errors.Wrap(err, "error decoding JWKS")
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing key `%s`", k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys found")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve `%s`", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type `%s`", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,183 @@
+package authz
+
+import (
+	"context"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/teran/archived/manager/auth"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+type Action string
+
+const (
+	ActionNamespaceCreate Action = "namespace.create"
+	ActionNamespaceRename Action = "namespace.rename"
+	ActionNamespaceDelete Action = "namespace.delete"
+	ActionNamespaceList   Action = "namespace.list"
+	ActionNamespaceSet    Action = "namespace.set"
+
+	ActionContainerCreate Action = "container.create"
+	ActionContainerMove   Action = "container.move"
+	ActionContainerRename Action = "container.rename"
+	ActionContainerDelete Action = "container.delete"
+	ActionContainerList   Action = "container.list"
+	ActionContainerSet    Action = "container.set"
+
+	ActionVersionCreate    Action = "version.create"
+	ActionVersionList      Action = "version.list"
+	ActionVersionDelete    Action = "version.delete"
+	ActionVersionPublish   Action = "version.publish"
+	ActionVersionProtect   Action = "version.protect"
+	ActionVersionUnprotect Action = "version.unprotect"
+	ActionVersionDiff      Action = "version.diff"
+	ActionVersionClone     Action = "version.clone"
+
+	ActionAliasCreate Action = "alias.create"
+	ActionAliasMove   Action = "alias.move"
+	ActionAliasDelete Action = "alias.delete"
+	ActionAliasList   Action = "alias.list"
+
+	ActionObjectCreate Action = "object.create"
+	ActionObjectList   Action = "object.list"
+	ActionObjectURL    Action = "object.url"
+	ActionObjectDelete Action = "object.delete"
+
+	ActionAuditList Action = "audit.list"
+
+	// BLOBs are shared between all the namespaces so the action is always
+	// checked against namespace `*`
+	ActionBlobWrite Action = "blob.write"
+)
+
+type Authorizer interface {
+	Authorize(ctx context.Context, namespace string, action Action) error
+}
+
+type Rule struct {
+	Subjects   []string `yaml:"subjects"`
+	Groups     []string `yaml:"groups"`
+	Namespaces []string `yaml:"namespaces"`
+	Actions    []string `yaml:"actions"`
+}
+
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+type policy struct {
+	rules []Rule
+}
+
+func New(p Policy) Authorizer {
+	return &policy{
+		rules: p.Rules,
+	}
+}
+
+func NewFromFile(filename string) (Authorizer, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading policy file")
+	}
+
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, errors.Wrap(err, "error decoding policy file")
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return New(p), nil
+}
+
+func (p Policy) Validate() error {
+	for idx, r := range p.Rules {
+		if len(r.Subjects) == 0 && len(r.Groups) == 0 {
+			return errors.Errorf("rule #%d: at least one subject or group is required", idx)
+		}
+
+		if len(r.Namespaces) == 0 {
+			return errors.Errorf("rule #%d: at least one namespace is required", idx)
+		}
+
+		if len(r.Actions) == 0 {
+			return errors.Errorf("rule #%d: at least one action is required", idx)
+		}
+
+		for _, patterns := range [][]string{r.Subjects, r.Groups, r.Namespaces, r.Actions} {
+			for _, pattern := range patterns {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return errors.Wrapf(err, "rule #%d: malformed pattern `%s`", idx, pattern)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *policy) Authorize(ctx context.Context, namespace string, action Action) error {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	for _, r := range p.rules {
+		if !r.matchIdentity(identity) {
+			continue
+		}
+
+		if matchAny(r.Namespaces, namespace) && matchAny(r.Actions, string(action)) {
+			return nil
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"subject":   identity.Subject,
+		"groups":    identity.Groups,
+		"namespace": namespace,
+		"action":    action,
+	}).Info("permission denied")
+
+	return ErrPermissionDenied
+}
+
+func (r Rule) matchIdentity(identity auth.Identity) bool {
+	if matchAny(r.Subjects, identity.Subject) {
+		return true
+	}
+
+	for _, g := range identity.Groups {
+		if matchAny(r.Groups, g) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type allowAll struct{}
+
+func NewAllowAll() Authorizer {
+	return allowAll{}
+}
+
+func (allowAll) Authorize(context.Context, string, Action) error {
+	return nil
+}
@@ -0,0 +1,181 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/teran/archived/manager/auth"
+)
+
+func (s *authzTestSuite) TestPolicy() {
+	type testCase struct {
+		name      string
+		identity  *auth.Identity
+		namespace string
+		action    Action
+		expErr    error
+	}
+
+	tcs := []testCase{
+		{
+			name:      "ci creates version in ci namespace",
+			identity:  &auth.Identity{Subject: "ci"},
+			namespace: "ci-builds",
+			action:    ActionVersionCreate,
+		},
+		{
+			name:      "ci creates objects in ci namespace",
+			identity:  &auth.Identity{Subject: "ci"},
+			namespace: "ci-builds",
+			action:    ActionObjectCreate,
+		},
+		{
+			name:      "ci uploads blobs",
+			identity:  &auth.Identity{Subject: "ci"},
+			namespace: "*",
+			action:    ActionBlobWrite,
+		},
+		{
+			name:      "auditor uploads blobs",
+			identity:  &auth.Identity{Subject: "jane", Groups: []string{"auditors"}},
+			namespace: "*",
+			action:    ActionBlobWrite,
+			expErr:    ErrPermissionDenied,
+		},
+		{
+			name:      "ci deletes version in ci namespace",
+			identity:  &auth.Identity{Subject: "ci"},
+			namespace: "ci-builds",
+			action:    ActionVersionDelete,
+			expErr:    ErrPermissionDenied,
+		},
+		{
+			name:      "ci creates version in other namespace",
+			identity:  &auth.Identity{Subject: "ci"},
+			namespace: "default",
+			action:    ActionVersionCreate,
+			expErr:    ErrPermissionDenied,
+		},
+		{
+			name:      "admin deletes version",
+			identity:  &auth.Identity{Subject: "john", Groups: []string{"developers", "admins"}},
+			namespace: "default",
+			action:    ActionVersionDelete,
+		},
+		{
+			name:      "auditor lists versions",
+			identity:  &auth.Identity{Subject: "jane", Groups: []string{"auditors"}},
+			namespace: "default",
+			action:    ActionVersionList,
+		},
+		{
+			name:      "auditor publishes version",
+			identity:  &auth.Identity{Subject: "jane", Groups: []string{"auditors"}},
+			namespace: "default",
+			action:    ActionVersionPublish,
+			expErr:    ErrPermissionDenied,
+		},
+		{
+			name:      "unknown identity",
+			identity:  &auth.Identity{Subject: "nobody"},
+			namespace: "default",
+			action:    ActionNamespaceList,
+			expErr:    ErrPermissionDenied,
+		},
+		{
+			name:      "no identity",
+			namespace: "default",
+			action:    ActionNamespaceList,
+			expErr:    ErrPermissionDenied,
+		},
+	}
+
+	a, err := NewFromFile("testdata/policy.yaml")
+	s.Require().NoError(err)
+
+	for _, tc := range tcs {
+		s.Run(tc.name, func() {
+			ctx := s.ctx
+			if tc.identity != nil {
+				ctx = auth.NewContext(ctx, *tc.identity)
+			}
+
+			err := a.Authorize(ctx, tc.namespace, tc.action)
+			if tc.expErr != nil {
+				s.Require().Error(err)
+				s.Require().Equal(tc.expErr, err)
+				return
+			}
+			s.Require().NoError(err)
+		})
+	}
+}
+
+func (s *authzTestSuite) TestPolicyValidation() {
+	type testCase struct {
+		name   string
+		policy Policy
+		expErr string
+	}
+
+	tcs := []testCase{
+		{
+			name: "no identities",
+			policy: Policy{Rules: []Rule{
+				{Namespaces: []string{"*"}, Actions: []string{"*"}},
+			}},
+			expErr: "rule #0: at least one subject or group is required",
+		},
+		{
+			name: "no namespaces",
+			policy: Policy{Rules: []Rule{
+				{Subjects: []string{"ci"}, Actions: []string{"*"}},
+			}},
+			expErr: "rule #0: at least one namespace is required",
+		},
+		{
+			name: "no actions",
+			policy: Policy{Rules: []Rule{
+				{Subjects: []string{"ci"}, Namespaces: []string{"*"}},
+			}},
+			expErr: "rule #0: at least one action is required",
+		},
+		{
+			name: "malformed pattern",
+			policy: Policy{Rules: []Rule{
+				{Subjects: []string{"ci"}, Namespaces: []string{"[ci"}, Actions: []string{"*"}},
+			}},
+			expErr: "rule #0: malformed pattern `[ci`: syntax error in pattern",
+		},
+	}
+
+	for _, tc := range tcs {
+		s.Run(tc.name, func() {
+			err := tc.policy.Validate()
+			s.Require().Error(err)
+			s.Require().Equal(tc.expErr, err.Error())
+		})
+	}
+}
+
+func (s *authzTestSuite) TestAllowAll() {
+	err := NewAllowAll().Authorize(s.ctx, "default", ActionNamespaceDelete)
+	s.Require().NoError(err)
+}
+
+// Definitions ...
+type authzTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+}
+
+func (s *authzTestSuite) SetupTest() {
+	s.ctx = context.Background()
+}
+
+func TestAuthzTestSuite(t *testing.T) {
+	suite.Run(t, &authzTestSuite{})
+}
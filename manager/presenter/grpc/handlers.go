@@ -2,16 +2,30 @@ package grpc
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/teran/archived/manager/authz"
 	v1 "github.com/teran/archived/manager/presenter/grpc/proto/v1"
+	"github.com/teran/archived/models"
 	"github.com/teran/archived/service"
-	"github.com/teran/go-collection/types/ptr"
+)
+
+const (
+	// maxObjectsPerBatch keeps CreateObjects requests well below the default
+	// gRPC message size limit
+	maxObjectsPerBatch = 10000
+
+	errorDomain            = "archived"
+	blobNotConfirmedReason = "BLOB_NOT_CONFIRMED"
 )
 
 var _ v1.ManageServiceServer = (*handlers)(nil)
@@ -25,16 +39,22 @@ type ManageServerInterface interface {
 type handlers struct {
 	v1.UnimplementedManageServiceServer
 
-	svc service.Manager
+	svc   service.Manager
+	authz authz.Authorizer
 }
 
-func New(svc service.Manager) ManageServerInterface {
+func New(svc service.Manager, authorizer authz.Authorizer) ManageServerInterface {
 	return &handlers{
-		svc: svc,
+		svc:   svc,
+		authz: authorizer,
 	}
 }
 
 func (h *handlers) CreateNamespace(ctx context.Context, in *v1.CreateNamespaceRequest) (*v1.CreateNamespaceResponse, error) {
+	if err := h.authorize(ctx, in.GetName(), authz.ActionNamespaceCreate); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.CreateNamespace(ctx, in.GetName())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -44,6 +64,13 @@ func (h *handlers) CreateNamespace(ctx context.Context, in *v1.CreateNamespaceRe
 }
 
 func (h *handlers) RenameNamespace(ctx context.Context, in *v1.RenameNamespaceRequest) (*v1.RenameNamespaceResponse, error) {
+	if err := h.authorize(ctx, in.GetOldName(), authz.ActionNamespaceRename); err != nil {
+		return nil, err
+	}
+	if err := h.authorize(ctx, in.GetNewName(), authz.ActionNamespaceRename); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.RenameNamespace(ctx, in.GetOldName(), in.GetNewName())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -53,6 +80,10 @@ func (h *handlers) RenameNamespace(ctx context.Context, in *v1.RenameNamespaceRe
 }
 
 func (h *handlers) DeleteNamespace(ctx context.Context, in *v1.DeleteNamespaceRequest) (*v1.DeleteNamespaceResponse, error) {
+	if err := h.authorize(ctx, in.GetName(), authz.ActionNamespaceDelete); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.DeleteNamespace(ctx, in.GetName())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -61,18 +92,42 @@ func (h *handlers) DeleteNamespace(ctx context.Context, in *v1.DeleteNamespaceRe
 	return &v1.DeleteNamespaceResponse{}, nil
 }
 
+func (h *handlers) SetNamespaceParameters(ctx context.Context, in *v1.SetNamespaceParametersRequest) (*v1.SetNamespaceParametersResponse, error) {
+	if err := h.authorize(ctx, in.GetName(), authz.ActionNamespaceSet); err != nil {
+		return nil, err
+	}
+
+	err := h.svc.SetNamespacePrivate(ctx, in.GetName(), in.GetIsPrivate())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.SetNamespaceParametersResponse{}, nil
+}
+
 func (h *handlers) ListNamespaces(ctx context.Context, in *v1.ListNamespacesRequest) (*v1.ListNamespacesResponse, error) {
 	namespaces, err := h.svc.ListNamespaces(ctx)
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
 
+	allowed := []string{}
+	for _, namespace := range namespaces {
+		if err := h.authz.Authorize(ctx, namespace, authz.ActionNamespaceList); err == nil {
+			allowed = append(allowed, namespace)
+		}
+	}
+
 	return &v1.ListNamespacesResponse{
-		Name: namespaces,
+		Name: allowed,
 	}, nil
 }
 
 func (h *handlers) CreateContainer(ctx context.Context, in *v1.CreateContainerRequest) (*v1.CreateContainerResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionContainerCreate); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.CreateContainer(ctx, in.GetNamespace(), in.GetName(), time.Duration(in.GetTtlSeconds())*time.Second)
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -82,6 +137,13 @@ func (h *handlers) CreateContainer(ctx context.Context, in *v1.CreateContainerRe
 }
 
 func (h *handlers) MoveContainer(ctx context.Context, in *v1.MoveContainerRequest) (*v1.MoveContainerResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionContainerMove); err != nil {
+		return nil, err
+	}
+	if err := h.authorize(ctx, in.GetDestinationNamespace(), authz.ActionContainerMove); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.MoveContainer(ctx, in.GetNamespace(), in.GetContainerName(), in.GetDestinationNamespace())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -91,6 +153,10 @@ func (h *handlers) MoveContainer(ctx context.Context, in *v1.MoveContainerReques
 }
 
 func (h *handlers) RenameContainer(ctx context.Context, in *v1.RenameContainerRequest) (*v1.RenameContainerResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionContainerRename); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.RenameContainer(ctx, in.GetNamespace(), in.GetOldName(), in.GetNewName())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -100,6 +166,10 @@ func (h *handlers) RenameContainer(ctx context.Context, in *v1.RenameContainerRe
 }
 
 func (h *handlers) DeleteContainer(ctx context.Context, in *v1.DeleteContainerRequest) (*v1.DeleteContainerResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionContainerDelete); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.DeleteContainer(ctx, in.GetNamespace(), in.GetName())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -109,7 +179,21 @@ func (h *handlers) DeleteContainer(ctx context.Context, in *v1.DeleteContainerRe
 }
 
 func (h *handlers) SetContainerParameters(ctx context.Context, in *v1.SetContainerParametersRequest) (*v1.SetContainerParametersResponse, error) {
-	err := h.svc.SetContainerParameters(ctx, in.GetNamespace(), in.GetName(), time.Duration(in.GetTtlSeconds())*time.Second)
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionContainerSet); err != nil {
+		return nil, err
+	}
+
+	var retention *models.RetentionPolicy
+	if rp := in.GetRetentionPolicy(); rp != nil {
+		retention = &models.RetentionPolicy{
+			KeepLast:    rp.GetKeepLast(),
+			KeepDaily:   rp.GetKeepDaily(),
+			KeepWeekly:  rp.GetKeepWeekly(),
+			KeepMonthly: rp.GetKeepMonthly(),
+		}
+	}
+
+	err := h.svc.SetContainerParameters(ctx, in.GetNamespace(), in.GetName(), time.Duration(in.GetTtlSeconds())*time.Second, retention)
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
@@ -118,6 +202,10 @@ func (h *handlers) SetContainerParameters(ctx context.Context, in *v1.SetContain
 }
 
 func (h *handlers) ListContainers(ctx context.Context, in *v1.ListContainersRequest) (*v1.ListContainersResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionContainerList); err != nil {
+		return nil, err
+	}
+
 	containers, err := h.svc.ListContainers(ctx, in.GetNamespace())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -134,6 +222,10 @@ func (h *handlers) ListContainers(ctx context.Context, in *v1.ListContainersRequ
 }
 
 func (h *handlers) CreateVersion(ctx context.Context, in *v1.CreateVersionRequest) (*v1.CreateVersionResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionCreate); err != nil {
+		return nil, err
+	}
+
 	version, err := h.svc.CreateVersion(ctx, in.GetNamespace(), in.GetContainer())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -144,7 +236,34 @@ func (h *handlers) CreateVersion(ctx context.Context, in *v1.CreateVersionReques
 	}, nil
 }
 
+func (h *handlers) CloneVersion(ctx context.Context, in *v1.CloneVersionRequest) (*v1.CloneVersionResponse, error) {
+	destNamespace := in.GetDestinationNamespace()
+	if destNamespace == "" {
+		destNamespace = in.GetNamespace()
+	}
+
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionClone); err != nil {
+		return nil, err
+	}
+	if err := h.authorize(ctx, destNamespace, authz.ActionVersionClone); err != nil {
+		return nil, err
+	}
+
+	version, err := h.svc.CloneVersion(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion(), destNamespace, in.GetDestinationContainer())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.CloneVersionResponse{
+		Version: version,
+	}, nil
+}
+
 func (h *handlers) ListVersions(ctx context.Context, in *v1.ListVersionsRequest) (*v1.ListVersionsResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionList); err != nil {
+		return nil, err
+	}
+
 	versions, err := h.svc.ListAllVersions(ctx, in.GetNamespace(), in.GetContainer())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -161,6 +280,10 @@ func (h *handlers) ListVersions(ctx context.Context, in *v1.ListVersionsRequest)
 }
 
 func (h *handlers) DeleteVersion(ctx context.Context, in *v1.DeleteVersionRequest) (*v1.DeleteVersionResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionDelete); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.DeleteVersion(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -170,6 +293,10 @@ func (h *handlers) DeleteVersion(ctx context.Context, in *v1.DeleteVersionReques
 }
 
 func (h *handlers) PublishVersion(ctx context.Context, in *v1.PublishVersionRequest) (*v1.PublishVersionResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionPublish); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.PublishVersion(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -178,23 +305,319 @@ func (h *handlers) PublishVersion(ctx context.Context, in *v1.PublishVersionRequ
 	return &v1.PublishVersionResponse{}, nil
 }
 
+func (h *handlers) ProtectVersion(ctx context.Context, in *v1.ProtectVersionRequest) (*v1.ProtectVersionResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionProtect); err != nil {
+		return nil, err
+	}
+
+	err := h.svc.ProtectVersion(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.ProtectVersionResponse{}, nil
+}
+
+func (h *handlers) UnprotectVersion(ctx context.Context, in *v1.UnprotectVersionRequest) (*v1.UnprotectVersionResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionUnprotect); err != nil {
+		return nil, err
+	}
+
+	err := h.svc.UnprotectVersion(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.UnprotectVersionResponse{}, nil
+}
+
+func (h *handlers) DiffVersions(ctx context.Context, in *v1.DiffVersionsRequest) (*v1.DiffVersionsResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionVersionDiff); err != nil {
+		return nil, err
+	}
+
+	diff, err := h.svc.DiffVersions(ctx, in.GetNamespace(), in.GetContainer(), in.GetFromVersion(), in.GetToVersion())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	resp := &v1.DiffVersionsResponse{
+		FromVersion:     diff.FromVersion,
+		ToVersion:       diff.ToVersion,
+		Added:           []*v1.Object{},
+		Removed:         []*v1.Object{},
+		Changed:         []*v1.ObjectChange{},
+		AddedBytes:      diff.AddedBytes,
+		RemovedBytes:    diff.RemovedBytes,
+		ChangedOldBytes: diff.ChangedOldBytes,
+		ChangedNewBytes: diff.ChangedNewBytes,
+	}
+	for _, o := range diff.Added {
+		resp.Added = append(resp.Added, &v1.Object{
+			Key:      o.Key,
+			Size:     o.Size,
+			Checksum: o.Checksum,
+			MimeType: o.MimeType,
+		})
+	}
+	for _, o := range diff.Removed {
+		resp.Removed = append(resp.Removed, &v1.Object{
+			Key:      o.Key,
+			Size:     o.Size,
+			Checksum: o.Checksum,
+			MimeType: o.MimeType,
+		})
+	}
+	for _, c := range diff.Changed {
+		resp.Changed = append(resp.Changed, &v1.ObjectChange{
+			Key:         c.Key,
+			OldChecksum: c.OldChecksum,
+			OldSize:     c.OldSize,
+			NewChecksum: c.NewChecksum,
+			NewSize:     c.NewSize,
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *handlers) CreateAlias(ctx context.Context, in *v1.CreateAliasRequest) (*v1.CreateAliasResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionAliasCreate); err != nil {
+		return nil, err
+	}
+
+	err := h.svc.CreateAlias(ctx, in.GetNamespace(), in.GetContainer(), in.GetName(), in.GetVersion())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.CreateAliasResponse{}, nil
+}
+
+func (h *handlers) MoveAlias(ctx context.Context, in *v1.MoveAliasRequest) (*v1.MoveAliasResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionAliasMove); err != nil {
+		return nil, err
+	}
+
+	err := h.svc.MoveAlias(ctx, in.GetNamespace(), in.GetContainer(), in.GetName(), in.GetVersion())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.MoveAliasResponse{}, nil
+}
+
+func (h *handlers) DeleteAlias(ctx context.Context, in *v1.DeleteAliasRequest) (*v1.DeleteAliasResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionAliasDelete); err != nil {
+		return nil, err
+	}
+
+	err := h.svc.DeleteAlias(ctx, in.GetNamespace(), in.GetContainer(), in.GetName())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.DeleteAliasResponse{}, nil
+}
+
+func (h *handlers) ListAliases(ctx context.Context, in *v1.ListAliasesRequest) (*v1.ListAliasesResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionAliasList); err != nil {
+		return nil, err
+	}
+
+	aliases, err := h.svc.ListAliases(ctx, in.GetNamespace(), in.GetContainer())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	result := []*v1.Alias{}
+	for _, a := range aliases {
+		result = append(result, &v1.Alias{
+			Name:    a.Name,
+			Version: a.Version,
+		})
+	}
+
+	return &v1.ListAliasesResponse{
+		Aliases: result,
+	}, nil
+}
+
 func (h *handlers) CreateObject(ctx context.Context, in *v1.CreateObjectRequest) (*v1.CreateObjectResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionObjectCreate); err != nil {
+		return nil, err
+	}
+
+	if err := h.authorizeBlobWrite(ctx); err != nil {
+		return nil, err
+	}
+
 	url, err := h.svc.EnsureBLOBPresenceOrGetUploadURL(ctx, in.GetChecksum(), in.GetSize(), in.GetMimeType())
-	if err != nil && url == "" {
+	if err != nil {
 		return nil, mapServiceError(err)
 	}
 
+	// The object could reference the BLOB only after its upload is confirmed
+	// so the request is refused with the upload URL attached to the error
+	if url != "" {
+		st, err := status.New(codes.FailedPrecondition, service.ErrNotConfirmed.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason: blobNotConfirmedReason,
+			Domain: errorDomain,
+			Metadata: map[string]string{
+				"checksum":   in.GetChecksum(),
+				"upload_url": url,
+			},
+		})
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return nil, st.Err()
+	}
+
 	err = h.svc.AddObject(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion(), in.GetKey(), in.GetChecksum())
 	if err != nil {
 		return nil, mapServiceError(err)
 	}
 
-	return &v1.CreateObjectResponse{
-		UploadUrl: ptr.String(url),
+	return &v1.CreateObjectResponse{}, nil
+}
+
+func (h *handlers) CreateObjects(ctx context.Context, in *v1.CreateObjectsRequest) (*v1.CreateObjectsResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionObjectCreate); err != nil {
+		return nil, err
+	}
+
+	if err := h.authorizeBlobWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	if len(in.GetObjects()) > maxObjectsPerBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "too many objects in batch: %d (max %d)", len(in.GetObjects()), maxObjectsPerBatch)
+	}
+
+	objects := []models.Object{}
+	for _, o := range in.GetObjects() {
+		objects = append(objects, models.Object{
+			Key:      o.GetKey(),
+			Checksum: o.GetChecksum(),
+			Size:     o.GetSize(),
+			MimeType: o.GetMimeType(),
+		})
+	}
+
+	urls, err := h.svc.CreateObjects(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion(), objects)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	resp := &v1.CreateObjectsResponse{
+		UploadUrls: []*v1.UploadURL{},
+	}
+	for _, checksum := range slices.Sorted(maps.Keys(urls)) {
+		resp.UploadUrls = append(resp.UploadUrls, &v1.UploadURL{
+			Checksum: checksum,
+			Url:      urls[checksum],
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *handlers) CreateMultipartUpload(ctx context.Context, in *v1.CreateMultipartUploadRequest) (*v1.CreateMultipartUploadResponse, error) {
+	if err := h.authorizeBlobWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	upload, err := h.svc.CreateMultipartUpload(ctx, in.GetChecksum(), in.GetSize())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.CreateMultipartUploadResponse{
+		UploadId: upload.UploadID,
+		PartSize: upload.PartSize,
+		PartUrls: upload.PartURLs,
 	}, nil
 }
 
+func (h *handlers) CompleteMultipartUpload(ctx context.Context, in *v1.CompleteMultipartUploadRequest) (*v1.CompleteMultipartUploadResponse, error) {
+	if err := h.authorizeBlobWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	parts := []models.UploadPart{}
+	for _, p := range in.GetParts() {
+		parts = append(parts, models.UploadPart{
+			Number: p.GetNumber(),
+			ETag:   p.GetEtag(),
+		})
+	}
+
+	if err := h.svc.CompleteMultipartUpload(ctx, in.GetChecksum(), in.GetUploadId(), parts); err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.CompleteMultipartUploadResponse{}, nil
+}
+
+func (h *handlers) AbortMultipartUpload(ctx context.Context, in *v1.AbortMultipartUploadRequest) (*v1.AbortMultipartUploadResponse, error) {
+	if err := h.authorizeBlobWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.svc.AbortMultipartUpload(ctx, in.GetChecksum(), in.GetUploadId()); err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.AbortMultipartUploadResponse{}, nil
+}
+
+func (h *handlers) ConfirmBLOB(ctx context.Context, in *v1.ConfirmBLOBRequest) (*v1.ConfirmBLOBResponse, error) {
+	if err := h.authorizeBlobWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.svc.ConfirmBLOB(ctx, in.GetChecksum()); err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	return &v1.ConfirmBLOBResponse{}, nil
+}
+
+func (h *handlers) VerifyBLOBs(ctx context.Context, in *v1.VerifyBLOBsRequest) (*v1.VerifyBLOBsResponse, error) {
+	// Missing BLOBs are supposed to be uploaded so it's a part of object
+	// creation
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionObjectCreate); err != nil {
+		return nil, err
+	}
+
+	if err := h.authorizeBlobWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	urls, err := h.svc.VerifyBLOBs(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion())
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	resp := &v1.VerifyBLOBsResponse{
+		UploadUrls: []*v1.UploadURL{},
+	}
+	for _, checksum := range slices.Sorted(maps.Keys(urls)) {
+		resp.UploadUrls = append(resp.UploadUrls, &v1.UploadURL{
+			Checksum: checksum,
+			Url:      urls[checksum],
+		})
+	}
+
+	return resp, nil
+}
+
 func (h *handlers) ListObjects(ctx context.Context, in *v1.ListObjectsRequest) (*v1.ListObjectsResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionObjectList); err != nil {
+		return nil, err
+	}
+
 	objects, err := h.svc.ListObjects(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion())
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
@@ -203,12 +626,29 @@ func (h *handlers) ListObjects(ctx context.Context, in *v1.ListObjectsRequest) (
 		return nil, mapServiceError(err)
 	}
 
-	return &v1.ListObjectsResponse{
-		Objects: objects,
-	}, nil
+	resp := &v1.ListObjectsResponse{
+		Objects:       []string{},
+		ObjectDetails: []*v1.Object{},
+	}
+	for _, o := range objects {
+		resp.Objects = append(resp.Objects, o.Key)
+		resp.ObjectDetails = append(resp.ObjectDetails, &v1.Object{
+			Key:       o.Key,
+			Size:      o.Size,
+			Checksum:  o.Checksum,
+			MimeType:  o.MimeType,
+			CreatedAt: timestamppb.New(o.CreatedAt),
+		})
+	}
+
+	return resp, nil
 }
 
 func (h *handlers) GetObjectURL(ctx context.Context, in *v1.GetObjectURLRequest) (*v1.GetObjectURLResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionObjectURL); err != nil {
+		return nil, err
+	}
+
 	url, err := h.svc.GetObjectURL(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion(), in.GetKey())
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
@@ -223,6 +663,10 @@ func (h *handlers) GetObjectURL(ctx context.Context, in *v1.GetObjectURLRequest)
 }
 
 func (h *handlers) DeleteObject(ctx context.Context, in *v1.DeleteObjectRequest) (*v1.DeleteObjectResponse, error) {
+	if err := h.authorize(ctx, in.GetNamespace(), authz.ActionObjectDelete); err != nil {
+		return nil, err
+	}
+
 	err := h.svc.DeleteObject(ctx, in.GetNamespace(), in.GetContainer(), in.GetVersion(), in.GetKey())
 	if err != nil {
 		return nil, mapServiceError(err)
@@ -231,13 +675,93 @@ func (h *handlers) DeleteObject(ctx context.Context, in *v1.DeleteObjectRequest)
 	return &v1.DeleteObjectResponse{}, nil
 }
 
+func (h *handlers) ListAuditEvents(ctx context.Context, in *v1.ListAuditEventsRequest) (*v1.ListAuditEventsResponse, error) {
+	namespace := in.GetNamespace()
+	if namespace == "" {
+		namespace = "*"
+	}
+
+	if err := h.authorize(ctx, namespace, authz.ActionAuditList); err != nil {
+		return nil, err
+	}
+
+	filter := models.AuditEventFilter{
+		Actor:     in.GetActor(),
+		Operation: in.GetOperation(),
+		Namespace: in.GetNamespace(),
+		Limit:     in.GetLimit(),
+	}
+
+	if in.GetSince() != nil {
+		filter.Since = in.GetSince().AsTime()
+	}
+
+	if in.GetUntil() != nil {
+		filter.Until = in.GetUntil().AsTime()
+	}
+
+	events, err := h.svc.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, mapServiceError(err)
+	}
+
+	result := []*v1.AuditEvent{}
+	for _, e := range events {
+		result = append(result, &v1.AuditEvent{
+			Id:         e.ID,
+			Actor:      e.Actor,
+			Operation:  e.Operation,
+			Namespace:  e.Namespace,
+			Parameters: e.Parameters,
+			Result:     string(e.Result),
+			Error:      e.Error,
+			CreatedAt:  timestamppb.New(e.CreatedAt),
+		})
+	}
+
+	return &v1.ListAuditEventsResponse{
+		Events: result,
+	}, nil
+}
+
+func (h *handlers) authorize(ctx context.Context, namespace string, action authz.Action) error {
+	err := h.authz.Authorize(ctx, namespace, action)
+	if err != nil {
+		if errors.Is(err, authz.ErrPermissionDenied) {
+			return status.Errorf(codes.PermissionDenied, "%s: action `%s` is not allowed in namespace `%s`", err.Error(), action, namespace)
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// authorizeBlobWrite checks the permission to upload and confirm BLOBs which
+// are shared between namespaces so it's granted for namespace `*` only
+func (h *handlers) authorizeBlobWrite(ctx context.Context) error {
+	return h.authorize(ctx, "*", authz.ActionBlobWrite)
+}
+
 func (h *handlers) Register(gs *grpc.Server) {
 	v1.RegisterManageServiceServer(gs, h)
 }
 
 func mapServiceError(err error) error {
-	if errors.Is(err, service.ErrNotFound) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
 		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrProtected), errors.Is(err, service.ErrAliased), errors.Is(err, service.ErrNotPublished), errors.Is(err, service.ErrNotConfirmed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, service.ErrBroken):
+		return status.Error(codes.DataLoss, err.Error())
+	case errors.Is(err, service.ErrDeleting):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
 	}
-	return status.Error(codes.Internal, err.Error())
 }
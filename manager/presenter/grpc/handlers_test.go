@@ -5,10 +5,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/suite"
 	"github.com/teran/go-collection/types/ptr"
 	grpctest "github.com/teran/go-grpctest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/teran/archived/manager/auth"
+	"github.com/teran/archived/manager/authz"
 	v1pb "github.com/teran/archived/manager/presenter/grpc/proto/v1"
 	"github.com/teran/archived/models"
 	"github.com/teran/archived/service"
@@ -54,6 +61,16 @@ func (s *manageHandlersTestSuite) TestDeleteNamespace() {
 	s.Require().NoError(err)
 }
 
+func (s *manageHandlersTestSuite) TestSetNamespaceParameters() {
+	s.svcMock.On("SetNamespacePrivate", "test-namespace", true).Return(nil).Once()
+
+	_, err := s.client.SetNamespaceParameters(s.ctx, &v1pb.SetNamespaceParametersRequest{
+		Name:      "test-namespace",
+		IsPrivate: true,
+	})
+	s.Require().NoError(err)
+}
+
 func (s *manageHandlersTestSuite) TestCreateContainer() {
 	s.svcMock.On("CreateContainer", defaultNamespace, "test-container", 364*time.Second).Return(nil).Once()
 
@@ -112,7 +129,7 @@ func (s *manageHandlersTestSuite) TestRenameContainerNotFound() {
 }
 
 func (s *manageHandlersTestSuite) TestSetContainerParameters() {
-	s.svcMock.On("SetContainerParameters", defaultNamespace, "test-container", 1*time.Hour).Return(nil).Once()
+	s.svcMock.On("SetContainerParameters", defaultNamespace, "test-container", 1*time.Hour, (*models.RetentionPolicy)(nil)).Return(nil).Once()
 
 	_, err := s.client.SetContainerParameters(s.ctx, &v1pb.SetContainerParametersRequest{
 		Namespace:  defaultNamespace,
@@ -120,6 +137,22 @@ func (s *manageHandlersTestSuite) TestSetContainerParameters() {
 		TtlSeconds: ptr.Int64(3600),
 	})
 	s.Require().NoError(err)
+
+	s.svcMock.On("SetContainerParameters", defaultNamespace, "test-container", 1*time.Hour, &models.RetentionPolicy{
+		KeepLast:    10,
+		KeepMonthly: 12,
+	}).Return(nil).Once()
+
+	_, err = s.client.SetContainerParameters(s.ctx, &v1pb.SetContainerParametersRequest{
+		Namespace:  defaultNamespace,
+		Name:       "test-container",
+		TtlSeconds: ptr.Int64(3600),
+		RetentionPolicy: &v1pb.RetentionPolicy{
+			KeepLast:    10,
+			KeepMonthly: 12,
+		},
+	})
+	s.Require().NoError(err)
 }
 
 func (s *manageHandlersTestSuite) TestListContainers() {
@@ -169,6 +202,32 @@ func (s *manageHandlersTestSuite) TestCreateVersionNotFound() {
 	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
 }
 
+func (s *manageHandlersTestSuite) TestCloneVersion() {
+	s.svcMock.On("CloneVersion", defaultNamespace, "test-container", "stable", defaultNamespace, "").Return("20240102030406", nil).Once()
+
+	resp, err := s.client.CloneVersion(s.ctx, &v1pb.CloneVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "stable",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal("20240102030406", resp.GetVersion())
+}
+
+func (s *manageHandlersTestSuite) TestCloneVersionNotFound() {
+	s.svcMock.On("CloneVersion", defaultNamespace, "test-container", "20240102030405", "other", "test-container-2").Return("", service.ErrNotFound).Once()
+
+	_, err := s.client.CloneVersion(s.ctx, &v1pb.CloneVersionRequest{
+		Namespace:            defaultNamespace,
+		Container:            "test-container",
+		Version:              "20240102030405",
+		DestinationNamespace: "other",
+		DestinationContainer: "test-container-2",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
+}
+
 func (s *manageHandlersTestSuite) TestDeleteContainer() {
 	s.svcMock.On("DeleteContainer", defaultNamespace, "test-container").Return(nil).Once()
 
@@ -244,6 +303,189 @@ func (s *manageHandlersTestSuite) TestDeleteVersionNotFound() {
 	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
 }
 
+func (s *manageHandlersTestSuite) TestDeleteVersionProtected() {
+	s.svcMock.On("DeleteVersion", defaultNamespace, "test-container", "test-version").Return(service.ErrProtected).Once()
+
+	_, err := s.client.DeleteVersion(s.ctx, &v1pb.DeleteVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "test-version",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = FailedPrecondition desc = entity is protected", err.Error())
+}
+
+func (s *manageHandlersTestSuite) TestDeleteVersionAliased() {
+	s.svcMock.On("DeleteVersion", defaultNamespace, "test-container", "test-version").Return(service.ErrAliased).Once()
+
+	_, err := s.client.DeleteVersion(s.ctx, &v1pb.DeleteVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "test-version",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = FailedPrecondition desc = version is referenced by alias", err.Error())
+}
+
+func (s *manageHandlersTestSuite) TestProtectVersion() {
+	s.svcMock.On("ProtectVersion", defaultNamespace, "test-container", "20240102030405").Return(nil).Once()
+
+	_, err := s.client.ProtectVersion(s.ctx, &v1pb.ProtectVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "20240102030405",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestProtectVersionNotFound() {
+	s.svcMock.On("ProtectVersion", defaultNamespace, "test-container", "20240102030405").Return(service.ErrNotFound).Once()
+
+	_, err := s.client.ProtectVersion(s.ctx, &v1pb.ProtectVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "20240102030405",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
+}
+
+func (s *manageHandlersTestSuite) TestUnprotectVersion() {
+	s.svcMock.On("UnprotectVersion", defaultNamespace, "test-container", "20240102030405").Return(nil).Once()
+
+	_, err := s.client.UnprotectVersion(s.ctx, &v1pb.UnprotectVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "20240102030405",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestCreateAlias() {
+	s.svcMock.On("CreateAlias", defaultNamespace, "test-container", "stable", "20240102030405").Return(nil).Once()
+
+	_, err := s.client.CreateAlias(s.ctx, &v1pb.CreateAliasRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Name:      "stable",
+		Version:   "20240102030405",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestCreateAliasErrors() {
+	s.svcMock.On("CreateAlias", defaultNamespace, "test-container", "stable", "20240102030405").Return(service.ErrConflict).Once()
+
+	_, err := s.client.CreateAlias(s.ctx, &v1pb.CreateAliasRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Name:      "stable",
+		Version:   "20240102030405",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = AlreadyExists desc = entity already exists", err.Error())
+
+	s.svcMock.On("CreateAlias", defaultNamespace, "test-container", "stable", "20240102030405").Return(service.ErrNotPublished).Once()
+
+	_, err = s.client.CreateAlias(s.ctx, &v1pb.CreateAliasRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Name:      "stable",
+		Version:   "20240102030405",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = FailedPrecondition desc = version is not published", err.Error())
+
+	s.svcMock.On("CreateAlias", defaultNamespace, "test-container", "latest", "20240102030405").Return(errors.Wrap(service.ErrInvalidArgument, "invalid alias name `latest`")).Once()
+
+	_, err = s.client.CreateAlias(s.ctx, &v1pb.CreateAliasRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Name:      "latest",
+		Version:   "20240102030405",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = InvalidArgument desc = invalid alias name `latest`: invalid argument", err.Error())
+}
+
+func (s *manageHandlersTestSuite) TestMoveAlias() {
+	s.svcMock.On("MoveAlias", defaultNamespace, "test-container", "stable", "20240102030405").Return(nil).Once()
+
+	_, err := s.client.MoveAlias(s.ctx, &v1pb.MoveAliasRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Name:      "stable",
+		Version:   "20240102030405",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestDeleteAlias() {
+	s.svcMock.On("DeleteAlias", defaultNamespace, "test-container", "stable").Return(nil).Once()
+
+	_, err := s.client.DeleteAlias(s.ctx, &v1pb.DeleteAliasRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Name:      "stable",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestListAliases() {
+	s.svcMock.On("ListAliases", defaultNamespace, "test-container").Return([]models.Alias{
+		{Name: "stable", Version: "20240102030405"},
+		{Name: "testing", Version: "20240103030405"},
+	}, nil).Once()
+
+	resp, err := s.client.ListAliases(s.ctx, &v1pb.ListAliasesRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(resp.GetAliases(), 2)
+	s.Require().Equal("stable", resp.GetAliases()[0].GetName())
+	s.Require().Equal("20240102030405", resp.GetAliases()[0].GetVersion())
+	s.Require().Equal("testing", resp.GetAliases()[1].GetName())
+	s.Require().Equal("20240103030405", resp.GetAliases()[1].GetVersion())
+}
+
+func (s *manageHandlersTestSuite) TestListAuditEvents() {
+	s.svcMock.On("ListAuditEvents", models.AuditEventFilter{
+		Actor: "john",
+		Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Limit: 10,
+	}).Return([]models.AuditEvent{
+		{
+			ID:         1,
+			Actor:      "john",
+			Operation:  "DeleteContainer",
+			Namespace:  defaultNamespace,
+			Parameters: map[string]string{"container": "test-container"},
+			Result:     models.AuditEventResultFailure,
+			Error:      "entity is protected",
+			CreatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}, nil).Once()
+
+	resp, err := s.client.ListAuditEvents(s.ctx, &v1pb.ListAuditEventsRequest{
+		Actor: "john",
+		Since: timestamppb.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Limit: 10,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(resp.GetEvents(), 1)
+
+	event := resp.GetEvents()[0]
+	s.Require().Equal(uint64(1), event.GetId())
+	s.Require().Equal("john", event.GetActor())
+	s.Require().Equal("DeleteContainer", event.GetOperation())
+	s.Require().Equal(defaultNamespace, event.GetNamespace())
+	s.Require().Equal(map[string]string{"container": "test-container"}, event.GetParameters())
+	s.Require().Equal("failure", event.GetResult())
+	s.Require().Equal("entity is protected", event.GetError())
+	s.Require().Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), event.GetCreatedAt().AsTime())
+}
+
 func (s *manageHandlersTestSuite) TestPublishVersion() {
 	s.svcMock.On("PublishVersion", defaultNamespace, "test-container", "20240102030405").Return(nil).Once()
 
@@ -255,6 +497,18 @@ func (s *manageHandlersTestSuite) TestPublishVersion() {
 	s.Require().NoError(err)
 }
 
+func (s *manageHandlersTestSuite) TestPublishVersionNotConfirmed() {
+	s.svcMock.On("PublishVersion", defaultNamespace, "test-container", "20240102030405").Return(service.ErrNotConfirmed).Once()
+
+	_, err := s.client.PublishVersion(s.ctx, &v1pb.PublishVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "20240102030405",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = FailedPrecondition desc = BLOB upload is not confirmed", err.Error())
+}
+
 func (s *manageHandlersTestSuite) TestPublishVersionNotFound() {
 	s.svcMock.On("PublishVersion", defaultNamespace, "test-container", "20240102030405").Return(service.ErrNotFound).Once()
 
@@ -268,10 +522,7 @@ func (s *manageHandlersTestSuite) TestPublishVersionNotFound() {
 }
 
 func (s *manageHandlersTestSuite) TestCreateObject() {
-	s.svcMock.On("EnsureBLOBPresenceOrGetUploadURL", "checksum", uint64(1234), "application/x-rpm").Return("https://example.com/url", nil).Once()
-	s.svcMock.On("AddObject", defaultNamespace, "test-container", "version", "key", "checksum").Return(nil).Once()
-
-	resp, err := s.client.CreateObject(s.ctx, &v1pb.CreateObjectRequest{
+	req := &v1pb.CreateObjectRequest{
 		Namespace: defaultNamespace,
 		Container: "test-container",
 		Version:   "version",
@@ -279,9 +530,52 @@ func (s *manageHandlersTestSuite) TestCreateObject() {
 		Checksum:  "checksum",
 		Size:      uint64(1234),
 		MimeType:  "application/x-rpm",
+	}
+
+	// BLOB has to be uploaded first
+	s.svcMock.On("EnsureBLOBPresenceOrGetUploadURL", "checksum", uint64(1234), "application/x-rpm").Return("https://example.com/url", nil).Once()
+
+	_, err := s.client.CreateObject(s.ctx, req)
+	s.Require().Error(err)
+
+	st := status.Convert(err)
+	s.Require().Equal(codes.FailedPrecondition, st.Code())
+	s.Require().Len(st.Details(), 1)
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	s.Require().True(ok)
+	s.Require().Equal("BLOB_NOT_CONFIRMED", info.GetReason())
+	s.Require().Equal(map[string]string{
+		"checksum":   "checksum",
+		"upload_url": "https://example.com/url",
+	}, info.GetMetadata())
+
+	// BLOB is uploaded and confirmed
+	s.svcMock.On("EnsureBLOBPresenceOrGetUploadURL", "checksum", uint64(1234), "application/x-rpm").Return("", nil).Once()
+	s.svcMock.On("AddObject", defaultNamespace, "test-container", "version", "key", "checksum").Return(nil).Once()
+
+	resp, err := s.client.CreateObject(s.ctx, req)
+	s.Require().NoError(err)
+	s.Require().Nil(resp.UploadUrl)
+}
+
+func (s *manageHandlersTestSuite) TestConfirmBLOB() {
+	s.svcMock.On("ConfirmBLOB", "checksum").Return(nil).Once()
+
+	_, err := s.client.ConfirmBLOB(s.ctx, &v1pb.ConfirmBLOBRequest{
+		Namespace: defaultNamespace,
+		Checksum:  "checksum",
 	})
 	s.Require().NoError(err)
-	s.Require().Equal("https://example.com/url", resp.GetUploadUrl())
+
+	s.svcMock.On("ConfirmBLOB", "checksum").Return(service.ErrNotFound).Once()
+
+	_, err = s.client.ConfirmBLOB(s.ctx, &v1pb.ConfirmBLOBRequest{
+		Namespace: defaultNamespace,
+		Checksum:  "checksum",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
 }
 
 func (s *manageHandlersTestSuite) TestCreateObjectNotFound() {
@@ -300,8 +594,146 @@ func (s *manageHandlersTestSuite) TestCreateObjectNotFound() {
 	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
 }
 
+func (s *manageHandlersTestSuite) TestCreateObjects() {
+	s.svcMock.On("CreateObjects", defaultNamespace, "test-container", "version", []models.Object{
+		{Key: "key1", Checksum: "checksum1", Size: 1234, MimeType: "application/x-rpm"},
+		{Key: "key2", Checksum: "checksum2", Size: 4321, MimeType: "application/x-rpm"},
+		{Key: "key3", Checksum: "checksum3", Size: 10, MimeType: "text/plain"},
+	}).Return(map[string]string{
+		"checksum3": "https://example.com/url3",
+		"checksum1": "https://example.com/url1",
+	}, nil).Once()
+
+	resp, err := s.client.CreateObjects(s.ctx, &v1pb.CreateObjectsRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "version",
+		Objects: []*v1pb.Object{
+			{Key: "key1", Checksum: "checksum1", Size: 1234, MimeType: "application/x-rpm"},
+			{Key: "key2", Checksum: "checksum2", Size: 4321, MimeType: "application/x-rpm"},
+			{Key: "key3", Checksum: "checksum3", Size: 10, MimeType: "text/plain"},
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(resp.GetUploadUrls(), 2)
+	s.Require().Equal("checksum1", resp.GetUploadUrls()[0].GetChecksum())
+	s.Require().Equal("https://example.com/url1", resp.GetUploadUrls()[0].GetUrl())
+	s.Require().Equal("checksum3", resp.GetUploadUrls()[1].GetChecksum())
+	s.Require().Equal("https://example.com/url3", resp.GetUploadUrls()[1].GetUrl())
+}
+
+func (s *manageHandlersTestSuite) TestCreateObjectsTooManyObjects() {
+	objects := []*v1pb.Object{}
+	for i := 0; i <= maxObjectsPerBatch; i++ {
+		objects = append(objects, &v1pb.Object{Key: "key", Checksum: "checksum", Size: 1})
+	}
+
+	_, err := s.client.CreateObjects(s.ctx, &v1pb.CreateObjectsRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "version",
+		Objects:   objects,
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = InvalidArgument desc = too many objects in batch: 10001 (max 10000)", err.Error())
+}
+
+func (s *manageHandlersTestSuite) TestCreateMultipartUpload() {
+	s.svcMock.On("CreateMultipartUpload", "deadbeef", uint64(1234)).Return(models.MultipartUpload{
+		UploadID: "upload-id",
+		PartSize: 1000,
+		PartURLs: []string{"https://example.com/part1", "https://example.com/part2"},
+	}, nil).Once()
+
+	resp, err := s.client.CreateMultipartUpload(s.ctx, &v1pb.CreateMultipartUploadRequest{
+		Namespace: defaultNamespace,
+		Checksum:  "deadbeef",
+		Size:      1234,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal("upload-id", resp.GetUploadId())
+	s.Require().Equal(uint64(1000), resp.GetPartSize())
+	s.Require().Equal([]string{"https://example.com/part1", "https://example.com/part2"}, resp.GetPartUrls())
+}
+
+func (s *manageHandlersTestSuite) TestCreateMultipartUploadNotSupported() {
+	s.svcMock.On("CreateMultipartUpload", "deadbeef", uint64(1234)).Return(models.MultipartUpload{}, service.ErrNotSupported).Once()
+
+	_, err := s.client.CreateMultipartUpload(s.ctx, &v1pb.CreateMultipartUploadRequest{
+		Namespace: defaultNamespace,
+		Checksum:  "deadbeef",
+		Size:      1234,
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = Unimplemented desc = operation is not supported", err.Error())
+}
+
+func (s *manageHandlersTestSuite) TestCompleteMultipartUpload() {
+	s.svcMock.On("CompleteMultipartUpload", "deadbeef", "upload-id", []models.UploadPart{
+		{Number: 1, ETag: "etag1"},
+		{Number: 2, ETag: "etag2"},
+	}).Return(nil).Once()
+
+	_, err := s.client.CompleteMultipartUpload(s.ctx, &v1pb.CompleteMultipartUploadRequest{
+		Namespace: defaultNamespace,
+		Checksum:  "deadbeef",
+		UploadId:  "upload-id",
+		Parts: []*v1pb.UploadPart{
+			{Number: 1, Etag: "etag1"},
+			{Number: 2, Etag: "etag2"},
+		},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestAbortMultipartUpload() {
+	s.svcMock.On("AbortMultipartUpload", "deadbeef", "upload-id").Return(nil).Once()
+
+	_, err := s.client.AbortMultipartUpload(s.ctx, &v1pb.AbortMultipartUploadRequest{
+		Namespace: defaultNamespace,
+		Checksum:  "deadbeef",
+		UploadId:  "upload-id",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestVerifyBLOBs() {
+	s.svcMock.On("VerifyBLOBs", defaultNamespace, "test-container", "version").Return(map[string]string{
+		"checksum3": "https://example.com/url3",
+		"checksum1": "https://example.com/url1",
+	}, nil).Once()
+
+	resp, err := s.client.VerifyBLOBs(s.ctx, &v1pb.VerifyBLOBsRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "version",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(resp.GetUploadUrls(), 2)
+	s.Require().Equal("checksum1", resp.GetUploadUrls()[0].GetChecksum())
+	s.Require().Equal("https://example.com/url1", resp.GetUploadUrls()[0].GetUrl())
+	s.Require().Equal("checksum3", resp.GetUploadUrls()[1].GetChecksum())
+	s.Require().Equal("https://example.com/url3", resp.GetUploadUrls()[1].GetUrl())
+}
+
+func (s *manageHandlersTestSuite) TestVerifyBLOBsNotFound() {
+	s.svcMock.On("VerifyBLOBs", defaultNamespace, "test-container", "version").Return(map[string]string(nil), service.ErrNotFound).Once()
+
+	_, err := s.client.VerifyBLOBs(s.ctx, &v1pb.VerifyBLOBsRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+		Version:   "version",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
+}
+
 func (s *manageHandlersTestSuite) TestListObjects() {
-	s.svcMock.On("ListObjects", defaultNamespace, "container", "version").Return([]string{"obj1", "obj2", "obj3"}, nil).Once()
+	s.svcMock.On("ListObjects", defaultNamespace, "container", "version").Return([]models.Object{
+		{Key: "obj1", Size: 10, Checksum: "deadbeef", MimeType: "text/plain", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Key: "obj2", Size: 20, Checksum: "cafebabe", MimeType: "text/plain", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Key: "obj3", Size: 30, Checksum: "abcdef01", MimeType: "application/json", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}, nil).Once()
 
 	resp, err := s.client.ListObjects(s.ctx, &v1pb.ListObjectsRequest{
 		Namespace: defaultNamespace,
@@ -312,10 +744,16 @@ func (s *manageHandlersTestSuite) TestListObjects() {
 	s.Require().Equal([]string{
 		"obj1", "obj2", "obj3",
 	}, resp.GetObjects())
+	s.Require().Len(resp.GetObjectDetails(), 3)
+	s.Require().Equal("obj3", resp.GetObjectDetails()[2].GetKey())
+	s.Require().Equal(uint64(30), resp.GetObjectDetails()[2].GetSize())
+	s.Require().Equal("abcdef01", resp.GetObjectDetails()[2].GetChecksum())
+	s.Require().Equal("application/json", resp.GetObjectDetails()[2].GetMimeType())
+	s.Require().Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), resp.GetObjectDetails()[2].GetCreatedAt().AsTime())
 }
 
 func (s *manageHandlersTestSuite) TestListObjectsNotFound() {
-	s.svcMock.On("ListObjects", defaultNamespace, "container", "version").Return([]string{}, service.ErrNotFound).Once()
+	s.svcMock.On("ListObjects", defaultNamespace, "container", "version").Return([]models.Object{}, service.ErrNotFound).Once()
 
 	_, err := s.client.ListObjects(s.ctx, &v1pb.ListObjectsRequest{
 		Namespace: defaultNamespace,
@@ -326,6 +764,58 @@ func (s *manageHandlersTestSuite) TestListObjectsNotFound() {
 	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
 }
 
+func (s *manageHandlersTestSuite) TestDiffVersions() {
+	s.svcMock.On("DiffVersions", defaultNamespace, "container", "20240102030405", "latest").Return(models.VersionDiff{
+		FromVersion: "20240102030405",
+		ToVersion:   "20240102030406",
+		Added: []models.Object{
+			{Key: "obj4", Size: 30, Checksum: "cccc", MimeType: "text/plain"},
+		},
+		Removed: []models.Object{
+			{Key: "obj3", Size: 10, Checksum: "aaaa", MimeType: "text/plain"},
+		},
+		Changed: []models.ObjectChange{
+			{Key: "obj2", OldSize: 20, OldChecksum: "bbbb", NewSize: 40, NewChecksum: "dddd"},
+		},
+		AddedBytes:      30,
+		RemovedBytes:    10,
+		ChangedOldBytes: 20,
+		ChangedNewBytes: 40,
+	}, nil).Once()
+
+	resp, err := s.client.DiffVersions(s.ctx, &v1pb.DiffVersionsRequest{
+		Namespace:   defaultNamespace,
+		Container:   "container",
+		FromVersion: "20240102030405",
+		ToVersion:   "latest",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal("20240102030406", resp.GetToVersion())
+	s.Require().Len(resp.GetAdded(), 1)
+	s.Require().Equal("obj4", resp.GetAdded()[0].GetKey())
+	s.Require().Len(resp.GetRemoved(), 1)
+	s.Require().Equal("obj3", resp.GetRemoved()[0].GetKey())
+	s.Require().Len(resp.GetChanged(), 1)
+	s.Require().Equal("dddd", resp.GetChanged()[0].GetNewChecksum())
+	s.Require().Equal(uint64(30), resp.GetAddedBytes())
+	s.Require().Equal(uint64(10), resp.GetRemovedBytes())
+	s.Require().Equal(uint64(20), resp.GetChangedOldBytes())
+	s.Require().Equal(uint64(40), resp.GetChangedNewBytes())
+}
+
+func (s *manageHandlersTestSuite) TestDiffVersionsNotFound() {
+	s.svcMock.On("DiffVersions", defaultNamespace, "container", "20240102030405", "stable").Return(models.VersionDiff{}, service.ErrNotFound).Once()
+
+	_, err := s.client.DiffVersions(s.ctx, &v1pb.DiffVersionsRequest{
+		Namespace:   defaultNamespace,
+		Container:   "container",
+		FromVersion: "20240102030405",
+		ToVersion:   "stable",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
+}
+
 func (s *manageHandlersTestSuite) TestGetObjectURL() {
 	s.svcMock.On("GetObjectURL", defaultNamespace, "test-container", "test-version", "test-key").Return("test-url", nil).Once()
 
@@ -377,6 +867,124 @@ func (s *manageHandlersTestSuite) TestDeleteObjectNotFound() {
 	s.Require().Equal("rpc error: code = NotFound desc = entity not found", err.Error())
 }
 
+func (s *manageHandlersTestSuite) TestAuthorization() {
+	h := New(s.svcMock, authz.New(authz.Policy{
+		Rules: []authz.Rule{
+			{
+				Subjects:   []string{"ci"},
+				Namespaces: []string{"ci-*"},
+				Actions:    []string{"namespace.list", "version.create", "version.publish"},
+			},
+		},
+	}))
+
+	ctx := auth.NewContext(s.ctx, auth.Identity{Subject: "ci"})
+
+	s.svcMock.On("CreateVersion", "ci-builds", "test-container").Return("20240102030405", nil).Once()
+
+	resp, err := h.CreateVersion(ctx, &v1pb.CreateVersionRequest{
+		Namespace: "ci-builds",
+		Container: "test-container",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal("20240102030405", resp.GetVersion())
+
+	_, err = h.CreateVersion(ctx, &v1pb.CreateVersionRequest{
+		Namespace: defaultNamespace,
+		Container: "test-container",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = PermissionDenied desc = permission denied: action `version.create` is not allowed in namespace `default`", err.Error())
+
+	_, err = h.DeleteVersion(ctx, &v1pb.DeleteVersionRequest{
+		Namespace: "ci-builds",
+		Container: "test-container",
+		Version:   "20240102030405",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = PermissionDenied desc = permission denied: action `version.delete` is not allowed in namespace `ci-builds`", err.Error())
+
+	_, err = h.MoveContainer(ctx, &v1pb.MoveContainerRequest{
+		Namespace:            "ci-builds",
+		ContainerName:        "test-container",
+		DestinationNamespace: defaultNamespace,
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = PermissionDenied desc = permission denied: action `container.move` is not allowed in namespace `ci-builds`", err.Error())
+
+	_, err = h.CreateVersion(s.ctx, &v1pb.CreateVersionRequest{
+		Namespace: "ci-builds",
+		Container: "test-container",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = PermissionDenied desc = permission denied: action `version.create` is not allowed in namespace `ci-builds`", err.Error())
+}
+
+func (s *manageHandlersTestSuite) TestBlobWriteAuthorization() {
+	h := New(s.svcMock, authz.New(authz.Policy{
+		Rules: []authz.Rule{
+			{
+				Subjects:   []string{"ci", "uploader"},
+				Namespaces: []string{"ci-*"},
+				Actions:    []string{"object.*"},
+			},
+			{
+				Subjects:   []string{"uploader"},
+				Namespaces: []string{"*"},
+				Actions:    []string{"blob.write"},
+			},
+		},
+	}))
+
+	_, err := h.ConfirmBLOB(auth.NewContext(s.ctx, auth.Identity{Subject: "ci"}), &v1pb.ConfirmBLOBRequest{
+		Namespace: "ci-builds",
+		Checksum:  "deadbeef",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = PermissionDenied desc = permission denied: action `blob.write` is not allowed in namespace `*`", err.Error())
+
+	_, err = h.AbortMultipartUpload(auth.NewContext(s.ctx, auth.Identity{Subject: "ci"}), &v1pb.AbortMultipartUploadRequest{
+		Namespace: "ci-builds",
+		Checksum:  "deadbeef",
+		UploadId:  "upload-id",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = PermissionDenied desc = permission denied: action `blob.write` is not allowed in namespace `*`", err.Error())
+
+	_, err = h.CreateObjects(auth.NewContext(s.ctx, auth.Identity{Subject: "ci"}), &v1pb.CreateObjectsRequest{
+		Namespace: "ci-builds",
+		Container: "test-container",
+		Version:   "20240102030405",
+	})
+	s.Require().Error(err)
+	s.Require().Equal("rpc error: code = PermissionDenied desc = permission denied: action `blob.write` is not allowed in namespace `*`", err.Error())
+
+	s.svcMock.On("ConfirmBLOB", "deadbeef").Return(nil).Once()
+
+	_, err = h.ConfirmBLOB(auth.NewContext(s.ctx, auth.Identity{Subject: "uploader"}), &v1pb.ConfirmBLOBRequest{
+		Checksum: "deadbeef",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *manageHandlersTestSuite) TestListNamespacesFilteredByPolicy() {
+	h := New(s.svcMock, authz.New(authz.Policy{
+		Rules: []authz.Rule{
+			{
+				Subjects:   []string{"ci"},
+				Namespaces: []string{"ci-*"},
+				Actions:    []string{"namespace.list"},
+			},
+		},
+	}))
+
+	s.svcMock.On("ListNamespaces").Return([]string{"ci-builds", "ci-nightly", "default"}, nil).Once()
+
+	resp, err := h.ListNamespaces(auth.NewContext(s.ctx, auth.Identity{Subject: "ci"}), &v1pb.ListNamespacesRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"ci-builds", "ci-nightly"}, resp.GetName())
+}
+
 // Definitions ...
 type manageHandlersTestSuite struct {
 	suite.Suite
@@ -393,7 +1001,7 @@ type manageHandlersTestSuite struct {
 
 func (s *manageHandlersTestSuite) SetupTest() {
 	s.svcMock = service.NewMock()
-	s.handlers = New(s.svcMock)
+	s.handlers = New(s.svcMock, authz.NewAllowAll())
 	s.srv = grpctest.New()
 	s.handlers.Register(s.srv.Server())
 
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type Alias struct {
+	Name      string
+	Version   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
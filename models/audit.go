@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type AuditEventResult string
+
+const (
+	AuditEventResultSuccess AuditEventResult = "success"
+	AuditEventResultFailure AuditEventResult = "failure"
+)
+
+type AuditEvent struct {
+	ID         uint64
+	Actor      string
+	Operation  string
+	Namespace  string
+	Parameters map[string]string
+	Result     AuditEventResult
+	Error      string
+	CreatedAt  time.Time
+}
+
+type AuditEventFilter struct {
+	Actor     string
+	Operation string
+	Namespace string
+	Since     time.Time
+	Until     time.Time
+	Limit     uint64
+}
@@ -1,7 +1,9 @@
 package models
 
 type Blob struct {
-	Checksum string
-	Size     uint64
-	MimeType string
+	Checksum    string
+	Size        uint64
+	MimeType    string
+	IsConfirmed bool
+	IsBroken    bool
 }
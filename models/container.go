@@ -3,7 +3,19 @@ package models
 import "time"
 
 type Container struct {
-	Name        string
-	CreatedAt   time.Time
-	VersionsTTL time.Duration
+	Name            string
+	CreatedAt       time.Time
+	VersionsTTL     time.Duration
+	RetentionPolicy RetentionPolicy
+}
+
+type RetentionPolicy struct {
+	KeepLast    uint32
+	KeepDaily   uint32
+	KeepWeekly  uint32
+	KeepMonthly uint32
+}
+
+func (p RetentionPolicy) IsEnabled() bool {
+	return p.KeepLast > 0 || p.KeepDaily > 0 || p.KeepWeekly > 0 || p.KeepMonthly > 0
 }
@@ -0,0 +1,23 @@
+package models
+
+type ObjectChange struct {
+	Key         string
+	OldChecksum string
+	OldSize     uint64
+	NewChecksum string
+	NewSize     uint64
+}
+
+type VersionDiff struct {
+	FromVersion string
+	ToVersion   string
+
+	Added   []Object
+	Removed []Object
+	Changed []ObjectChange
+
+	AddedBytes      uint64
+	RemovedBytes    uint64
+	ChangedOldBytes uint64
+	ChangedNewBytes uint64
+}
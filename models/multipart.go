@@ -0,0 +1,12 @@
+package models
+
+type MultipartUpload struct {
+	UploadID string
+	PartSize uint64
+	PartURLs []string
+}
+
+type UploadPart struct {
+	Number int32
+	ETag   string
+}
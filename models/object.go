@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Object struct {
+	Key       string
+	Checksum  string
+	Size      uint64
+	MimeType  string
+	CreatedAt time.Time
+}
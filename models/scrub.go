@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type ScrubStatus string
+
+const (
+	ScrubStatusMissing  ScrubStatus = "missing"
+	ScrubStatusMismatch ScrubStatus = "mismatch"
+)
+
+type ScrubResult struct {
+	ID             uint64
+	Checksum       string
+	Status         ScrubStatus
+	ExpectedSize   uint64
+	ActualSize     uint64
+	ActualChecksum string
+	CreatedAt      time.Time
+}
@@ -5,5 +5,13 @@ import "time"
 type Version struct {
 	Name        string
 	IsPublished bool
+	IsProtected bool
 	CreatedAt   time.Time
 }
+
+type ExpiredVersion struct {
+	Namespace string
+	Container string
+	Version   Version
+	Objects   []Object
+}
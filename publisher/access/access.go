@@ -0,0 +1,198 @@
+package access
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type Token struct {
+	Name       string   `yaml:"name"`
+	Token      string   `yaml:"token"`
+	Namespaces []string `yaml:"namespaces"`
+}
+
+type BasicAuthCredential struct {
+	Username     string   `yaml:"username"`
+	PasswordHash string   `yaml:"password_hash"`
+	Namespaces   []string `yaml:"namespaces"`
+}
+
+type ClientCertificate struct {
+	CommonName string   `yaml:"common_name"`
+	Namespaces []string `yaml:"namespaces"`
+}
+
+type Config struct {
+	Tokens             []Token               `yaml:"tokens"`
+	BasicAuth          []BasicAuthCredential `yaml:"basic_auth"`
+	ClientCertificates []ClientCertificate   `yaml:"client_certificates"`
+}
+
+// Grant represents the set of private namespaces the client is allowed to access
+type Grant struct {
+	authenticated bool
+	namespaces    []string
+}
+
+func (g Grant) IsAuthenticated() bool {
+	return g.authenticated
+}
+
+func (g Grant) Allows(namespace string) bool {
+	for _, pattern := range g.namespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Grant) add(namespaces []string) {
+	g.authenticated = true
+	g.namespaces = append(g.namespaces, namespaces...)
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (Grant, error)
+}
+
+type authenticator struct {
+	cfg Config
+}
+
+func New(cfg Config) Authenticator {
+	return &authenticator{
+		cfg: cfg,
+	}
+}
+
+func NewFromFile(filename string) (Authenticator, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading access config file")
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(err, "error decoding access config file")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return New(cfg), nil
+}
+
+func (c Config) Validate() error {
+	for idx, t := range c.Tokens {
+		if t.Token == "" {
+			return errors.Errorf("token #%d: token is required", idx)
+		}
+
+		if err := validateNamespaces(t.Namespaces); err != nil {
+			return errors.Wrapf(err, "token #%d", idx)
+		}
+	}
+
+	for idx, b := range c.BasicAuth {
+		if b.Username == "" {
+			return errors.Errorf("basic auth credential #%d: username is required", idx)
+		}
+
+		if _, err := bcrypt.Cost([]byte(b.PasswordHash)); err != nil {
+			return errors.Wrapf(err, "basic auth credential #%d: malformed bcrypt password hash", idx)
+		}
+
+		if err := validateNamespaces(b.Namespaces); err != nil {
+			return errors.Wrapf(err, "basic auth credential #%d", idx)
+		}
+	}
+
+	for idx, cc := range c.ClientCertificates {
+		if cc.CommonName == "" {
+			return errors.Errorf("client certificate #%d: common name is required", idx)
+		}
+
+		if err := validateNamespaces(cc.Namespaces); err != nil {
+			return errors.Wrapf(err, "client certificate #%d", idx)
+		}
+	}
+	return nil
+}
+
+func validateNamespaces(namespaces []string) error {
+	if len(namespaces) == 0 {
+		return errors.New("at least one namespace is required")
+	}
+
+	for _, pattern := range namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "malformed pattern `%s`", pattern)
+		}
+	}
+	return nil
+}
+
+func (a *authenticator) Authenticate(r *http.Request) (Grant, error) {
+	grant := Grant{}
+
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		commonName := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		for _, cc := range a.cfg.ClientCertificates {
+			if cc.CommonName == commonName {
+				grant.add(cc.Namespaces)
+			}
+		}
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return grant, nil
+	}
+
+	scheme, credentials, _ := strings.Cut(header, " ")
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		token := strings.TrimSpace(credentials)
+		for _, t := range a.cfg.Tokens {
+			if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+				grant.add(t.Namespaces)
+				return grant, nil
+			}
+		}
+	case "basic":
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			break
+		}
+
+		for _, b := range a.cfg.BasicAuth {
+			if b.Username != username {
+				continue
+			}
+
+			if err := bcrypt.CompareHashAndPassword([]byte(b.PasswordHash), []byte(password)); err == nil {
+				grant.add(b.Namespaces)
+				return grant, nil
+			}
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"remote_addr": r.RemoteAddr,
+		"scheme":      scheme,
+	}).Info("invalid credentials provided")
+
+	return Grant{}, ErrUnauthenticated
+}
@@ -0,0 +1,191 @@
+package access
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func (s *accessTestSuite) TestAuthenticate() {
+	type testCase struct {
+		name             string
+		setupFn          func(r *http.Request)
+		expErr           error
+		expAuthenticated bool
+		allowedNs        []string
+		disallowedNs     []string
+	}
+
+	tcs := []testCase{
+		{
+			name:         "anonymous",
+			setupFn:      func(r *http.Request) {},
+			disallowedNs: []string{"ci-builds", "internal"},
+		},
+		{
+			name: "valid token",
+			setupFn: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer test-token")
+			},
+			expAuthenticated: true,
+			allowedNs:        []string{"ci-builds"},
+			disallowedNs:     []string{"internal"},
+		},
+		{
+			name: "invalid token",
+			setupFn: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer wrong-token")
+			},
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "valid basic auth credentials",
+			setupFn: func(r *http.Request) {
+				r.SetBasicAuth("john", "secret")
+			},
+			expAuthenticated: true,
+			allowedNs:        []string{"internal"},
+			disallowedNs:     []string{"ci-builds"},
+		},
+		{
+			name: "invalid basic auth password",
+			setupFn: func(r *http.Request) {
+				r.SetBasicAuth("john", "wrong")
+			},
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "unknown basic auth user",
+			setupFn: func(r *http.Request) {
+				r.SetBasicAuth("jane", "secret")
+			},
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "unsupported authorization scheme",
+			setupFn: func(r *http.Request) {
+				r.Header.Set("Authorization", "Digest blah")
+			},
+			expErr: ErrUnauthenticated,
+		},
+		{
+			name: "trusted client certificate",
+			setupFn: func(r *http.Request) {
+				r.TLS = newConnectionState("mirror.example.com")
+			},
+			expAuthenticated: true,
+			allowedNs:        []string{"ci-builds", "internal"},
+		},
+		{
+			name: "untrusted client certificate",
+			setupFn: func(r *http.Request) {
+				r.TLS = newConnectionState("other.example.com")
+			},
+			disallowedNs: []string{"ci-builds", "internal"},
+		},
+	}
+
+	for _, tc := range tcs {
+		s.Run(tc.name, func() {
+			r, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			s.Require().NoError(err)
+
+			tc.setupFn(r)
+
+			grant, err := s.authenticator.Authenticate(r)
+			if tc.expErr != nil {
+				s.Require().Error(err)
+				s.Require().Equal(tc.expErr, err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expAuthenticated, grant.IsAuthenticated())
+
+			for _, ns := range tc.allowedNs {
+				s.Require().Truef(grant.Allows(ns), "namespace `%s` expected to be allowed", ns)
+			}
+
+			for _, ns := range tc.disallowedNs {
+				s.Require().Falsef(grant.Allows(ns), "namespace `%s` expected to be disallowed", ns)
+			}
+		})
+	}
+}
+
+func (s *accessTestSuite) TestValidate() {
+	type testCase struct {
+		name   string
+		cfg    Config
+		expErr string
+	}
+
+	tcs := []testCase{
+		{
+			name: "empty token",
+			cfg: Config{
+				Tokens: []Token{{Namespaces: []string{"*"}}},
+			},
+			expErr: "token #0: token is required",
+		},
+		{
+			name: "token without namespaces",
+			cfg: Config{
+				Tokens: []Token{{Token: "blah"}},
+			},
+			expErr: "token #0: at least one namespace is required",
+		},
+		{
+			name: "malformed password hash",
+			cfg: Config{
+				BasicAuth: []BasicAuthCredential{{Username: "john", PasswordHash: "secret", Namespaces: []string{"*"}}},
+			},
+			expErr: "basic auth credential #0: malformed bcrypt password hash: crypto/bcrypt: hashedSecret too short to be a bcrypted password",
+		},
+		{
+			name: "malformed namespace pattern",
+			cfg: Config{
+				ClientCertificates: []ClientCertificate{{CommonName: "test", Namespaces: []string{"[-"}}},
+			},
+			expErr: "client certificate #0: malformed pattern `[-`: syntax error in pattern",
+		},
+	}
+
+	for _, tc := range tcs {
+		s.Run(tc.name, func() {
+			err := tc.cfg.Validate()
+			s.Require().Error(err)
+			s.Require().Equal(tc.expErr, err.Error())
+		})
+	}
+}
+
+// Definitions ...
+type accessTestSuite struct {
+	suite.Suite
+
+	authenticator Authenticator
+}
+
+func (s *accessTestSuite) SetupTest() {
+	var err error
+	s.authenticator, err = NewFromFile("testdata/access.yaml")
+	s.Require().NoError(err)
+}
+
+func TestAccessTestSuite(t *testing.T) {
+	suite.Run(t, &accessTestSuite{})
+}
+
+func newConnectionState(commonName string) *tls.ConnectionState {
+	return &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{
+			{
+				{Subject: pkix.Name{CommonName: commonName}},
+			},
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package html
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+// checkNotModified sets ETag response header and reports whether the client
+// already has the actual copy of the resource according to If-None-Match
+// request header. Last-Modified is not used since the same URL could point
+// to older content once `latest` or an alias is moved back.
+func checkNotModified(c echo.Context, etag string) bool {
+	c.Response().Header().Set("ETag", etag)
+
+	req := c.Request()
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	for _, candidate := range strings.Split(req.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCacheable renders the template and responds with strong ETag
+// calculated from the page contents
+func renderCacheable(c echo.Context, name string, data any) error {
+	buf := &bytes.Buffer{}
+	if err := c.Echo().Renderer.Render(buf, name, data, c); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	if checkNotModified(c, `"`+hex.EncodeToString(sum[:])+`"`) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
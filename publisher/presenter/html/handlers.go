@@ -3,23 +3,32 @@ package html
 import (
 	"fmt"
 	"html/template"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strconv"
+	"time"
 
 	sprig "github.com/Masterminds/sprig/v3"
 	echo "github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/teran/archived/archive"
 	"github.com/teran/archived/models"
+	"github.com/teran/archived/publisher/access"
 	"github.com/teran/archived/service"
 )
 
 const (
-	notFoundTemplateFilename    = "404.html"
-	serverErrorTemplateFilename = "5xx.html"
+	unauthorizedTemplateFilename = "401.html"
+	notFoundTemplateFilename     = "404.html"
+	serverErrorTemplateFilename  = "5xx.html"
+
+	authenticateChallenge = `Basic realm="archived", charset="UTF-8"`
 )
 
 type Handlers interface {
@@ -36,6 +45,16 @@ const (
 	ThemeLight Theme = "light"
 )
 
+// ServingMode defines the way objects are served to the clients
+type ServingMode string
+
+const (
+	// ServingModeRedirect redirects clients to the blob URL
+	ServingModeRedirect ServingMode = "redirect"
+	// ServingModeProxy streams blobs to the clients via publisher itself
+	ServingModeProxy ServingMode = "proxy"
+)
+
 type DisplayConfig struct {
 	DefaultTheme         Theme
 	MaxPagesInPagination uint64
@@ -43,18 +62,22 @@ type DisplayConfig struct {
 
 type handlers struct {
 	svc                      service.Publisher
+	authenticator            access.Authenticator
 	staticDir                string
 	templateDir              string
 	preserveSchemeOnRedirect bool
+	servingMode              ServingMode
 	dc                       DisplayConfig
 }
 
-func New(svc service.Publisher, templateDir, staticDir string, preserveSchemeOnRedirect bool, dc DisplayConfig) Handlers {
+func New(svc service.Publisher, authenticator access.Authenticator, templateDir, staticDir string, preserveSchemeOnRedirect bool, servingMode ServingMode, dc DisplayConfig) Handlers {
 	return &handlers{
 		svc:                      svc,
+		authenticator:            authenticator,
 		staticDir:                staticDir,
 		templateDir:              templateDir,
 		preserveSchemeOnRedirect: preserveSchemeOnRedirect,
+		servingMode:              servingMode,
 		dc:                       dc,
 	}
 }
@@ -65,22 +88,47 @@ func (h *handlers) NamespaceIndex(c echo.Context) error {
 		return err
 	}
 
+	privateNamespaces, err := h.svc.ListPrivateNamespaces(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	grant, err := h.authenticate(c)
+	if err != nil {
+		return err
+	}
+
+	visibleNamespaces := []string{}
+	for _, namespace := range namespaces {
+		if slices.Contains(privateNamespaces, namespace) && !grant.Allows(namespace) {
+			continue
+		}
+		visibleNamespaces = append(visibleNamespaces, namespace)
+	}
+
 	type data struct {
 		Title        string
 		DefaultTheme Theme
 		Namespaces   []string
 	}
 
-	return c.Render(http.StatusOK, "namespace-list.html", &data{
+	return renderCacheable(c, "namespace-list.html", &data{
 		Title:        "Namespace index",
 		DefaultTheme: h.dc.DefaultTheme,
-		Namespaces:   namespaces,
+		Namespaces:   visibleNamespaces,
 	})
 }
 
 func (h *handlers) ContainerIndex(c echo.Context) error {
 	namespace := c.Param("namespace")
 
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.Render(http.StatusNotFound, notFoundTemplateFilename, nil)
+		}
+		return err
+	}
+
 	pageParam := c.QueryParam("page")
 	var page uint64 = 1
 
@@ -109,7 +157,7 @@ func (h *handlers) ContainerIndex(c echo.Context) error {
 		Containers   []models.Container
 	}
 
-	return c.Render(http.StatusOK, "container-list.html", &data{
+	return renderCacheable(c, "container-list.html", &data{
 		Title:        fmt.Sprintf("Container index (%s)", namespace),
 		DefaultTheme: h.dc.DefaultTheme,
 		Pagination:   NewPagination(pagesCount, page, h.dc.MaxPagesInPagination, fmt.Sprintf("/%s/", namespace)),
@@ -122,6 +170,13 @@ func (h *handlers) VersionIndex(c echo.Context) error {
 	namespace := c.Param("namespace")
 	container := c.Param("container")
 
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.Render(http.StatusNotFound, notFoundTemplateFilename, nil)
+		}
+		return err
+	}
+
 	pageParam := c.QueryParam("page")
 	var page uint64 = 1
 
@@ -151,7 +206,7 @@ func (h *handlers) VersionIndex(c echo.Context) error {
 		Versions     []models.Version
 	}
 
-	return c.Render(http.StatusOK, "version-list.html", &data{
+	return renderCacheable(c, "version-list.html", &data{
 		Title:        fmt.Sprintf("Version index (%s/%s)", namespace, container),
 		DefaultTheme: h.dc.DefaultTheme,
 		Pagination:   NewPagination(pagesCount, page, h.dc.MaxPagesInPagination, fmt.Sprintf("/%s/%s/", namespace, container)),
@@ -166,6 +221,13 @@ func (h *handlers) ObjectIndex(c echo.Context) error {
 	container := c.Param("container")
 	version := c.Param("version")
 
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.Render(http.StatusNotFound, notFoundTemplateFilename, nil)
+		}
+		return err
+	}
+
 	pageParam := c.QueryParam("page")
 	var page uint64 = 1
 
@@ -178,6 +240,18 @@ func (h *handlers) ObjectIndex(c echo.Context) error {
 		}
 	}
 
+	v, err := h.svc.GetVersion(c.Request().Context(), namespace, container, version)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.Render(http.StatusNotFound, notFoundTemplateFilename, nil)
+		}
+		return err
+	}
+
+	if format := c.QueryParam("format"); format != "" {
+		return h.exportVersion(c, namespace, container, v, format)
+	}
+
 	pagesCount, objects, err := h.svc.ListObjectsByPage(c.Request().Context(), namespace, container, version, page)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
@@ -193,9 +267,9 @@ func (h *handlers) ObjectIndex(c echo.Context) error {
 		Namespace    string
 		Container    string
 		Version      string
-		Objects      []string
+		Objects      []models.Object
 	}
-	return c.Render(http.StatusOK, "object-list.html", &data{
+	return renderCacheable(c, "object-list.html", &data{
 		Title:        fmt.Sprintf("Object index (%s/%s/%s)", namespace, container, version),
 		DefaultTheme: h.dc.DefaultTheme,
 		Pagination:   NewPagination(pagesCount, page, h.dc.MaxPagesInPagination, fmt.Sprintf("/%s/%s/%s/", namespace, container, version)),
@@ -211,17 +285,43 @@ func (h *handlers) GetObject(c echo.Context) error {
 	container := c.Param("container")
 	version := c.Param("version")
 
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return c.Render(http.StatusNotFound, notFoundTemplateFilename, nil)
+		}
+		return err
+	}
+
 	objectParam := c.Param("object")
 	key, err := url.PathUnescape(objectParam)
 	if err != nil {
 		return err
 	}
 
-	link, err := h.svc.GetObjectURL(c.Request().Context(), namespace, container, version, key)
+	blob, rsc, err := h.svc.GetObject(c.Request().Context(), namespace, container, version, key)
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			return c.Render(http.StatusNotFound, notFoundTemplateFilename, nil)
 		}
+		if errors.Is(err, service.ErrBroken) {
+			return echo.NewHTTPError(http.StatusGone)
+		}
+		return err
+	}
+	defer func() { _ = rsc.Close() }()
+
+	// Blobs are content addressed so checksum is a strong ETag and
+	// there's no need to reach blob storage to answer conditional requests
+	if checkNotModified(c, `"`+blob.Checksum+`"`) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	if h.servingMode == ServingModeProxy {
+		return serveBlob(c, key, blob, rsc)
+	}
+
+	link, err := h.svc.GetBlobURL(c.Request().Context(), blob, key)
+	if err != nil {
 		return err
 	}
 
@@ -255,6 +355,59 @@ func (h *handlers) GetObject(c echo.Context) error {
 	return c.Redirect(http.StatusFound, link)
 }
 
+// exportVersion streams the version objects as an archive. The response is
+// committed before the first object is read so the connection is aborted
+// on failure to let the client know the archive is incomplete.
+func (h *handlers) exportVersion(c echo.Context, namespace, container string, v models.Version, formatParam string) error {
+	format, err := archive.ParseFormat(formatParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	// objects of unpublished versions are not served
+	if !v.IsPublished {
+		return c.Render(http.StatusNotFound, notFoundTemplateFilename, nil)
+	}
+
+	filename := container + "-" + v.Name + format.Extension()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, format.ContentType())
+	w.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+
+	aw, err := archive.NewWriter(w, format)
+	if err == nil {
+		err = h.svc.ExportVersion(c.Request().Context(), namespace, container, v.Name, aw)
+	}
+	if err == nil {
+		err = aw.Close()
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"namespace": namespace,
+			"container": container,
+			"version":   v.Name,
+			"format":    format,
+			"error":     err,
+		}).Error("error exporting version")
+
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+func serveBlob(c echo.Context, key string, blob models.Blob, rsc io.ReadSeeker) error {
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, blob.MimeType)
+	w.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)}))
+
+	// ServeContent handles Content-Length, Range and conditional requests
+	http.ServeContent(w, c.Request(), "", time.Time{}, rsc)
+	return nil
+}
+
 func (h *handlers) ErrorHandler(err error, c echo.Context) {
 	code := 500
 	templateFilename := serverErrorTemplateFilename
@@ -262,9 +415,11 @@ func (h *handlers) ErrorHandler(err error, c echo.Context) {
 	v, ok := err.(*echo.HTTPError) //nolint:errorlint
 	if ok {
 		code = v.Code
-		if v.Code == http.StatusNotFound {
-			code = http.StatusNotFound
+		switch v.Code {
+		case http.StatusNotFound:
 			templateFilename = notFoundTemplateFilename
+		case http.StatusUnauthorized:
+			templateFilename = unauthorizedTemplateFilename
 		}
 	}
 
@@ -281,6 +436,56 @@ func (h *handlers) ErrorHandler(err error, c echo.Context) {
 	}
 }
 
+// checkNamespaceAccess returns nil for public namespaces and for private ones
+// the client is granted access to. Private namespaces are reported as not
+// existing to authenticated clients without access to avoid disclosure.
+func (h *handlers) checkNamespaceAccess(c echo.Context, namespace string) error {
+	privateNamespaces, err := h.svc.ListPrivateNamespaces(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(privateNamespaces, namespace) {
+		return nil
+	}
+
+	grant, err := h.authenticate(c)
+	if err != nil {
+		return err
+	}
+
+	if grant.Allows(namespace) {
+		return nil
+	}
+
+	if !grant.IsAuthenticated() {
+		return h.unauthorized(c)
+	}
+
+	log.WithFields(log.Fields{
+		"namespace":   namespace,
+		"remote_addr": c.Request().RemoteAddr,
+	}).Info("access to private namespace denied")
+
+	return service.ErrNotFound
+}
+
+func (h *handlers) authenticate(c echo.Context) (access.Grant, error) {
+	grant, err := h.authenticator.Authenticate(c.Request())
+	if err != nil {
+		if errors.Is(err, access.ErrUnauthenticated) {
+			return access.Grant{}, h.unauthorized(c)
+		}
+		return access.Grant{}, err
+	}
+	return grant, nil
+}
+
+func (h *handlers) unauthorized(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, authenticateChallenge)
+	return echo.NewHTTPError(http.StatusUnauthorized)
+}
+
 func (h *handlers) Register(e *echo.Echo) {
 	e.Renderer = &renderer{
 		templates: template.Must(
@@ -295,6 +500,7 @@ func (h *handlers) Register(e *echo.Echo) {
 	e.GET("/:namespace/:container/", h.VersionIndex)
 	e.GET("/:namespace/:container/:version/", h.ObjectIndex)
 	e.GET("/:namespace/:container/:version/:object", h.GetObject)
+	e.HEAD("/:namespace/:container/:version/:object", h.GetObject)
 
 	e.Static(h.staticDir, "static")
 }
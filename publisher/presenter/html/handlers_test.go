@@ -1,17 +1,25 @@
 package html
 
 import (
+	"archive/tar"
 	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	echo "github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+
+	"github.com/teran/archived/archive"
 	"github.com/teran/archived/models"
+	"github.com/teran/archived/publisher/access"
 	"github.com/teran/archived/service"
 )
 
@@ -38,13 +46,94 @@ func (s *handlersTestSuite) TestVersionIndex() {
 }
 
 func (s *handlersTestSuite) TestObjectIndex() {
-	s.serviceMock.On("ListObjectsByPage", defaultNamespace, "test-container-1", "20241011121314", uint64(1)).Return(uint64(100), []string{"test-object-dir/file.txt"}, nil).Once()
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20241011121314").Return(models.Version{
+		Name:        "20241011121314",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 10, 11, 12, 13, 14, 0, time.UTC),
+	}, nil).Once()
+	s.serviceMock.On("ListObjectsByPage", defaultNamespace, "test-container-1", "20241011121314", uint64(1)).Return(uint64(100), []models.Object{
+		{
+			Key:       "test-object-dir/file.txt",
+			Checksum:  "deadbeef",
+			Size:      1234,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 10, 11, 12, 13, 14, 0, time.UTC),
+		},
+	}, nil).Once()
 
 	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/20241011121314/", "testdata/objects.html.sample")
 }
 
+func (s *handlersTestSuite) TestExportVersion() {
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "stable").Return(models.Version{
+		Name:        "20241011121314",
+		IsPublished: true,
+	}, nil).Once()
+	s.serviceMock.On("ExportVersion", defaultNamespace, "test-container-1", "20241011121314", mock.Anything).Run(func(args mock.Arguments) {
+		w := args.Get(3).(archive.Writer)
+		err := w.WriteFile(archive.File{Name: "dir/file.txt", Size: 9}, strings.NewReader("test data"))
+		s.Require().NoError(err)
+	}).Return(nil).Once()
+
+	code, headers, body := s.doRequest(s.srv.URL+"/default/test-container-1/stable/?format=tar", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Equal("application/x-tar", headers.Get("Content-Type"))
+	s.Require().Equal(`attachment; filename=test-container-1-20241011121314.tar`, headers.Get("Content-Disposition"))
+
+	tr := tar.NewReader(strings.NewReader(body))
+	hdr, err := tr.Next()
+	s.Require().NoError(err)
+	s.Require().Equal("dir/file.txt", hdr.Name)
+
+	data, err := io.ReadAll(tr)
+	s.Require().NoError(err)
+	s.Require().Equal("test data", string(data))
+
+	_, err = tr.Next()
+	s.Require().Equal(io.EOF, err)
+}
+
+func (s *handlersTestSuite) TestExportVersionErrors() {
+	// Unsupported format
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20241011121314").Return(models.Version{
+		Name:        "20241011121314",
+		IsPublished: true,
+	}, nil).Once()
+
+	code, _, _ := s.doRequest(s.srv.URL+"/default/test-container-1/20241011121314/?format=rar", func(r *http.Request) {})
+	s.Require().Equal(http.StatusBadRequest, code)
+
+	// Unpublished version
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20241011121314").Return(models.Version{
+		Name: "20241011121314",
+	}, nil).Once()
+	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/20241011121314/?format=tar", "testdata/404.html.sample")
+
+	// Failure in the middle of the stream aborts the connection
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20241011121314").Return(models.Version{
+		Name:        "20241011121314",
+		IsPublished: true,
+	}, nil).Once()
+	s.serviceMock.On("ExportVersion", defaultNamespace, "test-container-1", "20241011121314", mock.Anything).Run(func(args mock.Arguments) {
+		w := args.Get(3).(archive.Writer)
+		err := w.WriteFile(archive.File{Name: "file.txt", Size: 1 << 16}, strings.NewReader(strings.Repeat("a", 1<<16)))
+		s.Require().NoError(err)
+	}).Return(errors.New("blob not found")).Once()
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.srv.URL+"/default/test-container-1/20241011121314/?format=tar", nil)
+	s.Require().NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		defer func() { _ = resp.Body.Close() }()
+		_, err = io.ReadAll(resp.Body)
+	}
+	s.Require().Error(err)
+}
+
 func (s *handlersTestSuite) TestGetObject() {
-	s.serviceMock.On("GetObjectURL", defaultNamespace, "test-container-1", "20241011121314", "test-dir/filename.txt").Return("https://example.com/some-addr", nil).Once()
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20241011121314", "test-dir/filename.txt").Return(models.Blob{Checksum: "deadbeef"}, readSeekNopCloser{strings.NewReader("")}, nil).Once()
+	s.serviceMock.On("GetBlobURL", models.Blob{Checksum: "deadbeef"}, "test-dir/filename.txt").Return("https://example.com/some-addr", nil).Once()
 
 	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.srv.URL+"/default/test-container-1/20241011121314/test-dir/filename.txt", nil)
 	s.Require().NoError(err)
@@ -63,8 +152,152 @@ func (s *handlersTestSuite) TestGetObject() {
 	s.Require().Equal("https://example.com/some-addr", v)
 }
 
+func (s *handlersTestSuite) TestGetObjectNotModified() {
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20241011121314", "file.txt").Return(models.Blob{Checksum: "deadbeef"}, readSeekNopCloser{strings.NewReader("")}, nil).Twice()
+
+	code, header, body := s.doRequest(s.srv.URL+"/default/test-container-1/20241011121314/file.txt", func(r *http.Request) {
+		r.Header.Set("If-None-Match", `"cafebabe", "deadbeef"`)
+	})
+	s.Require().Equal(http.StatusNotModified, code)
+	s.Require().Equal(`"deadbeef"`, header.Get("ETag"))
+	s.Require().Empty(body)
+
+	s.serviceMock.On("GetBlobURL", models.Blob{Checksum: "deadbeef"}, "file.txt").Return("https://example.com/some-addr", nil).Once()
+
+	code, header, _ = s.doRequest(s.srv.URL+"/default/test-container-1/20241011121314/file.txt", func(r *http.Request) {
+		r.Header.Set("If-None-Match", `"cafebabe"`)
+	})
+	s.Require().Equal(http.StatusFound, code)
+	s.Require().Equal(`"deadbeef"`, header.Get("ETag"))
+}
+
+func (s *handlersTestSuite) TestObjectIndexConditional() {
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20241011121314").Return(models.Version{
+		Name:        "20241011121314",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 10, 11, 12, 13, 14, 0, time.UTC),
+	}, nil).Times(3)
+	s.serviceMock.On("ListObjectsByPage", defaultNamespace, "test-container-1", "20241011121314", uint64(1)).Return(uint64(100), []models.Object{
+		{
+			Key:       "test-object-dir/file.txt",
+			Checksum:  "deadbeef",
+			Size:      1234,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 10, 11, 12, 13, 14, 0, time.UTC),
+		},
+	}, nil).Times(3)
+
+	code, header, _ := s.doRequest(s.srv.URL+"/default/test-container-1/20241011121314/", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Empty(header.Get("Last-Modified"))
+
+	etag := header.Get("ETag")
+	s.Require().NotEmpty(etag)
+
+	code, _, body := s.doRequest(s.srv.URL+"/default/test-container-1/20241011121314/", func(r *http.Request) {
+		r.Header.Set("If-None-Match", etag)
+	})
+	s.Require().Equal(http.StatusNotModified, code)
+	s.Require().Empty(body)
+
+	// `latest` or an alias could be moved to older version so the
+	// modification time is not trusted
+	code, _, _ = s.doRequest(s.srv.URL+"/default/test-container-1/20241011121314/", func(r *http.Request) {
+		r.Header.Set("If-Modified-Since", "Fri, 11 Oct 2024 12:13:14 GMT")
+	})
+	s.Require().Equal(http.StatusOK, code)
+}
+
+func (s *handlersTestSuite) TestVersionIndexConditional() {
+	s.serviceMock.On("ListPublishedVersionsByPage", defaultNamespace, "test-container-1", uint64(1)).Return(uint64(100), []models.Version{
+		{Name: "20241011121314", CreatedAt: time.Date(2024, 10, 11, 12, 13, 14, 0, time.UTC)},
+		{Name: "20241010121314", CreatedAt: time.Date(2024, 10, 10, 12, 13, 14, 0, time.UTC)},
+	}, nil).Times(3)
+
+	code, header, _ := s.doRequest(s.srv.URL+"/default/test-container-1/", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Empty(header.Get("Last-Modified"))
+
+	etag := header.Get("ETag")
+	s.Require().NotEmpty(etag)
+
+	code, _, _ = s.doRequest(s.srv.URL+"/default/test-container-1/", func(r *http.Request) {
+		r.Header.Set("If-Modified-Since", "Fri, 11 Oct 2024 12:13:14 GMT")
+	})
+	s.Require().Equal(http.StatusOK, code)
+
+	code, _, _ = s.doRequest(s.srv.URL+"/default/test-container-1/", func(r *http.Request) {
+		r.Header.Set("If-None-Match", etag)
+	})
+	s.Require().Equal(http.StatusNotModified, code)
+
+	// older version published later changes the listing and its ETag
+	s.serviceMock.On("ListPublishedVersionsByPage", defaultNamespace, "test-container-1", uint64(1)).Return(uint64(100), []models.Version{
+		{Name: "20241011121314", CreatedAt: time.Date(2024, 10, 11, 12, 13, 14, 0, time.UTC)},
+		{Name: "20241010121314", CreatedAt: time.Date(2024, 10, 10, 12, 13, 14, 0, time.UTC)},
+		{Name: "20241009121314", CreatedAt: time.Date(2024, 10, 9, 12, 13, 14, 0, time.UTC)},
+	}, nil).Once()
+
+	code, _, _ = s.doRequest(s.srv.URL+"/default/test-container-1/", func(r *http.Request) {
+		r.Header.Set("If-None-Match", etag)
+	})
+	s.Require().Equal(http.StatusOK, code)
+}
+
+func (s *handlersTestSuite) TestGetObjectProxyMode() {
+	srv := s.newServer(ServingModeProxy)
+	defer srv.Close()
+
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20241011121314", "test-dir/filename.txt").Return(models.Blob{
+		Checksum: "deadbeef",
+		Size:     9,
+		MimeType: "text/plain",
+	}, readSeekNopCloser{strings.NewReader("test data")}, nil).Twice()
+
+	code, header, body := s.doRequest(srv.URL+"/default/test-container-1/20241011121314/test-dir/filename.txt", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Equal("test data", body)
+	s.Require().Equal("text/plain", header.Get("Content-Type"))
+	s.Require().Equal("9", header.Get("Content-Length"))
+	s.Require().Equal(`"deadbeef"`, header.Get("ETag"))
+	s.Require().Equal(`attachment; filename=filename.txt`, header.Get("Content-Disposition"))
+	s.Require().Equal("bytes", header.Get("Accept-Ranges"))
+
+	code, header, body = s.doRequest(srv.URL+"/default/test-container-1/20241011121314/test-dir/filename.txt", func(r *http.Request) {
+		r.Header.Set("Range", "bytes=5-")
+	})
+	s.Require().Equal(http.StatusPartialContent, code)
+	s.Require().Equal("data", body)
+	s.Require().Equal("bytes 5-8/9", header.Get("Content-Range"))
+}
+
+func (s *handlersTestSuite) TestGetObjectProxyModeNonASCIIFilename() {
+	srv := s.newServer(ServingModeProxy)
+	defer srv.Close()
+
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20241011121314", "отчёт 1.txt").Return(models.Blob{
+		Checksum: "deadbeef",
+		Size:     9,
+		MimeType: "text/plain",
+	}, readSeekNopCloser{strings.NewReader("test data")}, nil).Once()
+
+	code, header, _ := s.doRequest(srv.URL+"/default/test-container-1/20241011121314/%D0%BE%D1%82%D1%87%D1%91%D1%82%201.txt", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Equal(`attachment; filename*=utf-8''%D0%BE%D1%82%D1%87%D1%91%D1%82%201.txt`, header.Get("Content-Disposition"))
+}
+
+func (s *handlersTestSuite) TestGetObjectProxyModeNotFound() {
+	srv := s.newServer(ServingModeProxy)
+	defer srv.Close()
+
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20241011121314", "test-object.txt").Return(models.Blob{}, nil, service.ErrNotFound).Once()
+
+	s.compareHTMLResponse(srv.URL+"/default/test-container-1/20241011121314/test-object.txt", "testdata/404.html.sample")
+}
+
 func (s *handlersTestSuite) TestGetObjectSchemeMismatchXForwardedScheme() {
-	s.serviceMock.On("GetObjectURL", defaultNamespace, "test-container-1", "20241011121314", "test-dir/filename.txt").Return("https://example.com/some-addr", nil).Once()
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20241011121314", "test-dir/filename.txt").Return(models.Blob{Checksum: "deadbeef"}, readSeekNopCloser{strings.NewReader("")}, nil).Once()
+	s.serviceMock.On("GetBlobURL", models.Blob{Checksum: "deadbeef"}, "test-dir/filename.txt").Return("https://example.com/some-addr", nil).Once()
 
 	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.srv.URL+"/default/test-container-1/20241011121314/test-dir/filename.txt", nil)
 	s.Require().NoError(err)
@@ -86,7 +319,8 @@ func (s *handlersTestSuite) TestGetObjectSchemeMismatchXForwardedScheme() {
 }
 
 func (s *handlersTestSuite) TestGetObjectSchemeMismatchXScheme() {
-	s.serviceMock.On("GetObjectURL", defaultNamespace, "test-container-1", "20241011121314", "test-dir/filename.txt").Return("https://example.com/some-addr", nil).Once()
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20241011121314", "test-dir/filename.txt").Return(models.Blob{Checksum: "deadbeef"}, readSeekNopCloser{strings.NewReader("")}, nil).Once()
+	s.serviceMock.On("GetBlobURL", models.Blob{Checksum: "deadbeef"}, "test-dir/filename.txt").Return("https://example.com/some-addr", nil).Once()
 
 	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.srv.URL+"/default/test-container-1/20241011121314/test-dir/filename.txt", nil)
 	s.Require().NoError(err)
@@ -114,10 +348,10 @@ func (s *handlersTestSuite) TestErrNotFound() {
 	s.serviceMock.On("ListPublishedVersionsByPage", defaultNamespace, "test-container-1", uint64(1)).Return(uint64(100), []models.Version(nil), service.ErrNotFound).Once()
 	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/", "testdata/404.html.sample")
 
-	s.serviceMock.On("ListObjectsByPage", defaultNamespace, "test-container-1", "20240101010101", uint64(1)).Return(uint64(100), []string(nil), service.ErrNotFound).Once()
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20240101010101").Return(models.Version{}, service.ErrNotFound).Once()
 	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/20240101010101/", "testdata/404.html.sample")
 
-	s.serviceMock.On("GetObjectURL", defaultNamespace, "test-container-1", "20240101010101", "test-object.txt").Return("", service.ErrNotFound).Once()
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20240101010101", "test-object.txt").Return(models.Blob{}, nil, service.ErrNotFound).Once()
 	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/20240101010101/test-object.txt", "testdata/404.html.sample")
 
 	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/20240101010101", "testdata/404.html.sample")
@@ -132,8 +366,19 @@ func (s *handlersTestSuite) TestErr5xx() {
 	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/", "testdata/5xx.html.sample")
 }
 
+func (s *handlersTestSuite) TestErrBroken() {
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20240101010101", "test-object.txt").Return(models.Blob{}, nil, service.ErrBroken).Once()
+
+	code, _, _ := s.doRequest(s.srv.URL+"/default/test-container-1/20240101010101/test-object.txt", func(r *http.Request) {})
+	s.Require().Equal(http.StatusGone, code)
+
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20240101010101", "test-object.txt").Return(models.Blob{}, nil, service.ErrBroken).Once()
+	s.compareHTMLResponse(s.srv.URL+"/default/test-container-1/20240101010101/test-object.txt", "testdata/410.html.sample")
+}
+
 func (s *handlersTestSuite) TestEscapedPath() {
-	s.serviceMock.On("GetObjectURL", defaultNamespace, "test-container-1", "20240101010101", "test object.txt").Return("https://example.com/some-addr", nil).Once()
+	s.serviceMock.On("GetObject", defaultNamespace, "test-container-1", "20240101010101", "test object.txt").Return(models.Blob{Checksum: "deadbeef"}, readSeekNopCloser{strings.NewReader("")}, nil).Once()
+	s.serviceMock.On("GetBlobURL", models.Blob{Checksum: "deadbeef"}, "test object.txt").Return("https://example.com/some-addr", nil).Once()
 
 	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.srv.URL+"/default/test-container-1/20240101010101/test%20object.txt", nil)
 	s.Require().NoError(err)
@@ -152,6 +397,76 @@ func (s *handlersTestSuite) TestEscapedPath() {
 	s.Require().Equal("https://example.com/some-addr", v)
 }
 
+func (s *handlersTestSuite) TestNamespaceIndexHidesPrivateNamespaces() {
+	s.serviceMock.On("ListNamespaces").Return([]string{"default", "private", "test-namespace-1"}, nil).Twice()
+
+	s.compareHTMLResponse(s.srv.URL, "testdata/namespaces.html.sample")
+
+	code, _, body := s.doRequest(s.srv.URL, func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer test-token")
+	})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().Contains(body, `<a href="/private/">private/</a>`)
+}
+
+func (s *handlersTestSuite) TestPrivateNamespaceAccess() {
+	unauthorizedSample, err := os.ReadFile("testdata/401.html.sample")
+	s.Require().NoError(err)
+
+	notFoundSample, err := os.ReadFile("testdata/404.html.sample")
+	s.Require().NoError(err)
+
+	// anonymous
+	code, header, body := s.doRequest(s.srv.URL+"/private/", func(r *http.Request) {})
+	s.Require().Equal(http.StatusUnauthorized, code)
+	s.Require().Equal(`Basic realm="archived", charset="UTF-8"`, header.Get("WWW-Authenticate"))
+	s.Require().Equal(string(unauthorizedSample), body)
+
+	// invalid token
+	code, _, body = s.doRequest(s.srv.URL+"/private/", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer invalid-token")
+	})
+	s.Require().Equal(http.StatusUnauthorized, code)
+	s.Require().Equal(string(unauthorizedSample), body)
+
+	// authenticated without access to the namespace
+	code, _, body = s.doRequest(s.srv.URL+"/private/", func(r *http.Request) {
+		r.SetBasicAuth("jane", "secret")
+	})
+	s.Require().Equal(http.StatusNotFound, code)
+	s.Require().Equal(string(notFoundSample), body)
+
+	// token
+	s.serviceMock.On("ListContainersByPage", "private", uint64(1)).Return(uint64(1), []models.Container{{Name: "test-container-1"}}, nil).Once()
+	code, _, _ = s.doRequest(s.srv.URL+"/private/", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer test-token")
+	})
+	s.Require().Equal(http.StatusOK, code)
+
+	// basic auth
+	s.serviceMock.On("ListPublishedVersionsByPage", "private", "test-container-1", uint64(1)).Return(uint64(1), []models.Version{
+		{Name: "20241011121314"},
+	}, nil).Once()
+	code, _, _ = s.doRequest(s.srv.URL+"/private/test-container-1/", func(r *http.Request) {
+		r.SetBasicAuth("john", "secret")
+	})
+	s.Require().Equal(http.StatusOK, code)
+}
+
+func (s *handlersTestSuite) TestGetObjectFromPrivateNamespace() {
+	code, _, _ := s.doRequest(s.srv.URL+"/private/test-container-1/20241011121314/file.txt", func(r *http.Request) {})
+	s.Require().Equal(http.StatusUnauthorized, code)
+
+	s.serviceMock.On("GetObject", "private", "test-container-1", "20241011121314", "file.txt").Return(models.Blob{Checksum: "deadbeef"}, readSeekNopCloser{strings.NewReader("")}, nil).Once()
+	s.serviceMock.On("GetBlobURL", models.Blob{Checksum: "deadbeef"}, "file.txt").Return("https://example.com/some-addr", nil).Once()
+
+	code, header, _ := s.doRequest(s.srv.URL+"/private/test-container-1/20241011121314/file.txt", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer test-token")
+	})
+	s.Require().Equal(http.StatusFound, code)
+	s.Require().Equal("https://example.com/some-addr", header.Get("Location"))
+}
+
 // Definitions ...
 type handlersTestSuite struct {
 	suite.Suite
@@ -167,19 +482,40 @@ type handlersTestSuite struct {
 func (s *handlersTestSuite) SetupTest() {
 	s.ctx = context.TODO()
 
+	s.serviceMock = service.NewMock()
+	s.serviceMock.On("ListPrivateNamespaces").Return([]string{"private"}, nil).Maybe()
+
+	s.srv = s.newServer(ServingModeRedirect)
+}
+
+func (s *handlersTestSuite) newServer(servingMode ServingMode) *httptest.Server {
 	e := echo.New()
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
-	s.serviceMock = service.NewMock()
-
-	s.handlers = New(s.serviceMock, "templates", "static", true, DisplayConfig{
+	s.handlers = New(s.serviceMock, access.New(access.Config{
+		Tokens: []access.Token{
+			{Token: "test-token", Namespaces: []string{"private"}},
+		},
+		BasicAuth: []access.BasicAuthCredential{
+			{
+				Username:     "john",
+				PasswordHash: "$2a$04$vzKZvhBZEr4Uan0Oeos1W.kVmryV0lTxRkfKcvhcYIfAito9MKfUq", // secret
+				Namespaces:   []string{"private"},
+			},
+			{
+				Username:     "jane",
+				PasswordHash: "$2a$04$vzKZvhBZEr4Uan0Oeos1W.kVmryV0lTxRkfKcvhcYIfAito9MKfUq", // secret
+				Namespaces:   []string{"other"},
+			},
+		},
+	}), "templates", "static", true, servingMode, DisplayConfig{
 		DefaultTheme:         ThemeDark,
 		MaxPagesInPagination: 5,
 	})
 	s.handlers.Register(e)
 
-	s.srv = httptest.NewServer(e)
+	return httptest.NewServer(e)
 }
 
 func (s *handlersTestSuite) TearDownTest() {
@@ -192,6 +528,14 @@ func TestHandlersTestSuite(t *testing.T) {
 	suite.Run(t, &handlersTestSuite{})
 }
 
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error {
+	return nil
+}
+
 func (s *handlersTestSuite) compareHTMLResponse(url, responseSamplePath string) {
 	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, url, nil)
 	s.Require().NoError(err)
@@ -208,3 +552,25 @@ func (s *handlersTestSuite) compareHTMLResponse(url, responseSamplePath string)
 
 	s.Require().Equal(string(sampleData), string(data))
 }
+
+func (s *handlersTestSuite) doRequest(url string, setupFn func(r *http.Request)) (int, http.Header, string) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, url, nil)
+	s.Require().NoError(err)
+
+	setupFn(req)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Do(req)
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	return resp.StatusCode, resp.Header, string(data)
+}
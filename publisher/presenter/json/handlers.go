@@ -0,0 +1,392 @@
+package json
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/publisher/access"
+	"github.com/teran/archived/service"
+)
+
+const authenticateChallenge = `Basic realm="archived", charset="UTF-8"`
+
+var errAccessDenied = errors.New("access denied")
+
+type Handlers interface {
+	ListNamespaces(c echo.Context) error
+	ListContainers(c echo.Context) error
+	ListVersions(c echo.Context) error
+	ListObjects(c echo.Context) error
+	DiffVersions(c echo.Context) error
+
+	Register(e *echo.Echo)
+}
+
+type Namespace struct {
+	Name string `json:"name"`
+}
+
+type Container struct {
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	TTLSeconds *int64    `json:"ttl_seconds,omitempty"`
+}
+
+type Version struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Object struct {
+	Key       string    `json:"key"`
+	Size      uint64    `json:"size"`
+	MimeType  string    `json:"mime_type"`
+	Checksum  string    `json:"checksum"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+type ObjectChange struct {
+	Key         string `json:"key"`
+	OldSize     uint64 `json:"old_size"`
+	OldChecksum string `json:"old_checksum"`
+	NewSize     uint64 `json:"new_size"`
+	NewChecksum string `json:"new_checksum"`
+}
+
+type Pagination struct {
+	Page       uint64 `json:"page"`
+	TotalPages uint64 `json:"total_pages"`
+}
+
+type NamespacesResponse struct {
+	Namespaces []Namespace `json:"namespaces"`
+}
+
+type ContainersResponse struct {
+	Pagination Pagination  `json:"pagination"`
+	Containers []Container `json:"containers"`
+}
+
+type VersionsResponse struct {
+	Pagination Pagination `json:"pagination"`
+	Versions   []Version  `json:"versions"`
+}
+
+type ObjectsResponse struct {
+	Pagination Pagination `json:"pagination"`
+	Version    Version    `json:"version"`
+	Objects    []Object   `json:"objects"`
+}
+
+type DiffResponse struct {
+	From            Version        `json:"from"`
+	To              Version        `json:"to"`
+	Added           []Object       `json:"added"`
+	Removed         []Object       `json:"removed"`
+	Changed         []ObjectChange `json:"changed"`
+	AddedBytes      uint64         `json:"added_bytes"`
+	RemovedBytes    uint64         `json:"removed_bytes"`
+	ChangedOldBytes uint64         `json:"changed_old_bytes"`
+	ChangedNewBytes uint64         `json:"changed_new_bytes"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type handlers struct {
+	svc           service.Publisher
+	authenticator access.Authenticator
+}
+
+func New(svc service.Publisher, authenticator access.Authenticator) Handlers {
+	return &handlers{
+		svc:           svc,
+		authenticator: authenticator,
+	}
+}
+
+func (h *handlers) ListNamespaces(c echo.Context) error {
+	namespaces, err := h.svc.ListNamespaces(c.Request().Context())
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	privateNamespaces, err := h.svc.ListPrivateNamespaces(c.Request().Context())
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	grant, err := h.authenticator.Authenticate(c.Request())
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	resp := NamespacesResponse{Namespaces: []Namespace{}}
+	for _, namespace := range namespaces {
+		if slices.Contains(privateNamespaces, namespace) && !grant.Allows(namespace) {
+			continue
+		}
+		resp.Namespaces = append(resp.Namespaces, Namespace{Name: namespace})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *handlers) ListContainers(c echo.Context) error {
+	namespace := c.Param("namespace")
+
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		return h.error(c, err)
+	}
+
+	page := pageParam(c)
+	pagesCount, containers, err := h.svc.ListContainersByPage(c.Request().Context(), namespace, page)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	resp := ContainersResponse{
+		Pagination: Pagination{Page: page, TotalPages: pagesCount},
+		Containers: []Container{},
+	}
+	for _, container := range containers {
+		cnt := Container{
+			Name:      container.Name,
+			CreatedAt: container.CreatedAt,
+		}
+
+		if container.VersionsTTL >= 0 {
+			ttl := int64(container.VersionsTTL.Seconds())
+			cnt.TTLSeconds = &ttl
+		}
+
+		resp.Containers = append(resp.Containers, cnt)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *handlers) ListVersions(c echo.Context) error {
+	namespace := c.Param("namespace")
+	container := c.Param("container")
+
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		return h.error(c, err)
+	}
+
+	page := pageParam(c)
+	pagesCount, versions, err := h.svc.ListPublishedVersionsByPage(c.Request().Context(), namespace, container, page)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	resp := VersionsResponse{
+		Pagination: Pagination{Page: page, TotalPages: pagesCount},
+		Versions:   []Version{},
+	}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, Version{
+			Name:      v.Name,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *handlers) ListObjects(c echo.Context) error {
+	namespace := c.Param("namespace")
+	container := c.Param("container")
+	version := c.Param("version")
+
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		return h.error(c, err)
+	}
+
+	// Resolve aliases and `latest` once so all the objects are taken from
+	// the very same version
+	v, err := h.svc.GetVersion(c.Request().Context(), namespace, container, version)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	if !v.IsPublished {
+		return h.error(c, service.ErrNotFound)
+	}
+
+	page := pageParam(c)
+	pagesCount, objects, err := h.svc.ListObjectsByPage(c.Request().Context(), namespace, container, v.Name, page)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	resp := ObjectsResponse{
+		Pagination: Pagination{Page: page, TotalPages: pagesCount},
+		Version: Version{
+			Name:      v.Name,
+			CreatedAt: v.CreatedAt,
+		},
+		Objects: []Object{},
+	}
+	for _, object := range objects {
+		resp.Objects = append(resp.Objects, Object{
+			Key:       object.Key,
+			Size:      object.Size,
+			MimeType:  object.MimeType,
+			Checksum:  object.Checksum,
+			CreatedAt: object.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *handlers) DiffVersions(c echo.Context) error {
+	namespace := c.Param("namespace")
+	container := c.Param("container")
+
+	if err := h.checkNamespaceAccess(c, namespace); err != nil {
+		return h.error(c, err)
+	}
+
+	versions := []Version{}
+	for _, version := range []string{c.Param("version"), c.Param("to")} {
+		v, err := h.svc.GetVersion(c.Request().Context(), namespace, container, version)
+		if err != nil {
+			return h.error(c, err)
+		}
+
+		if !v.IsPublished {
+			return h.error(c, service.ErrNotFound)
+		}
+
+		versions = append(versions, Version{
+			Name:      v.Name,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+
+	diff, err := h.svc.DiffVersions(c.Request().Context(), namespace, container, versions[0].Name, versions[1].Name)
+	if err != nil {
+		return h.error(c, err)
+	}
+
+	resp := DiffResponse{
+		From:            versions[0],
+		To:              versions[1],
+		Added:           []Object{},
+		Removed:         []Object{},
+		Changed:         []ObjectChange{},
+		AddedBytes:      diff.AddedBytes,
+		RemovedBytes:    diff.RemovedBytes,
+		ChangedOldBytes: diff.ChangedOldBytes,
+		ChangedNewBytes: diff.ChangedNewBytes,
+	}
+	for _, object := range diff.Added {
+		resp.Added = append(resp.Added, Object{
+			Key:      object.Key,
+			Size:     object.Size,
+			MimeType: object.MimeType,
+			Checksum: object.Checksum,
+		})
+	}
+	for _, object := range diff.Removed {
+		resp.Removed = append(resp.Removed, Object{
+			Key:      object.Key,
+			Size:     object.Size,
+			MimeType: object.MimeType,
+			Checksum: object.Checksum,
+		})
+	}
+	for _, change := range diff.Changed {
+		resp.Changed = append(resp.Changed, ObjectChange{
+			Key:         change.Key,
+			OldSize:     change.OldSize,
+			OldChecksum: change.OldChecksum,
+			NewSize:     change.NewSize,
+			NewChecksum: change.NewChecksum,
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *handlers) checkNamespaceAccess(c echo.Context, namespace string) error {
+	privateNamespaces, err := h.svc.ListPrivateNamespaces(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(privateNamespaces, namespace) {
+		return nil
+	}
+
+	grant, err := h.authenticator.Authenticate(c.Request())
+	if err != nil {
+		return err
+	}
+
+	if grant.Allows(namespace) {
+		return nil
+	}
+
+	if !grant.IsAuthenticated() {
+		return access.ErrUnauthenticated
+	}
+
+	log.WithFields(log.Fields{
+		"namespace":   namespace,
+		"remote_addr": c.Request().RemoteAddr,
+	}).Info("access to private namespace denied")
+
+	return errAccessDenied
+}
+
+func (h *handlers) error(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, access.ErrUnauthenticated):
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, authenticateChallenge)
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, errAccessDenied):
+		// Private namespaces must not be distinguishable from nonexistent ones
+		return c.JSON(http.StatusNotFound, ErrorResponse{Error: "not found"})
+	}
+
+	log.WithFields(log.Fields{
+		"error": err,
+		"path":  c.Request().URL.Path,
+	}).Error("error handling API request")
+
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+}
+
+func pageParam(c echo.Context) uint64 {
+	param := c.QueryParam("page")
+	if param == "" {
+		return 1
+	}
+
+	page, err := strconv.ParseUint(param, 10, 64)
+	if err != nil || page < 1 {
+		log.Warnf("malformed page parameter: `%s`", param)
+		return 1
+	}
+	return page
+}
+
+func (h *handlers) Register(e *echo.Echo) {
+	g := e.Group("/api/v1")
+
+	g.GET("/namespaces", h.ListNamespaces)
+	g.GET("/namespaces/:namespace/containers", h.ListContainers)
+	g.GET("/namespaces/:namespace/containers/:container/versions", h.ListVersions)
+	g.GET("/namespaces/:namespace/containers/:container/versions/:version/objects", h.ListObjects)
+	g.GET("/namespaces/:namespace/containers/:container/versions/:version/diff/:to", h.DiffVersions)
+}
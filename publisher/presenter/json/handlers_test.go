@@ -0,0 +1,276 @@
+package json
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/publisher/access"
+	"github.com/teran/archived/service"
+)
+
+const defaultNamespace = "default"
+
+func (s *handlersTestSuite) TestListNamespaces() {
+	s.serviceMock.On("ListNamespaces").Return([]string{"default", "private", "test-namespace-1"}, nil).Twice()
+
+	code, _, body := s.doRequest("/api/v1/namespaces", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{"namespaces":[{"name":"default"},{"name":"test-namespace-1"}]}`, body)
+
+	code, _, body = s.doRequest("/api/v1/namespaces", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer test-token")
+	})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{"namespaces":[{"name":"default"},{"name":"private"},{"name":"test-namespace-1"}]}`, body)
+}
+
+func (s *handlersTestSuite) TestListContainers() {
+	s.serviceMock.On("ListContainersByPage", defaultNamespace, uint64(2)).Return(uint64(3), []models.Container{
+		{
+			Name:        "test-container-1",
+			CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			VersionsTTL: 24 * time.Hour,
+		},
+		{
+			Name:        "test-container-2",
+			CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			VersionsTTL: -1,
+		},
+	}, nil).Once()
+
+	code, _, body := s.doRequest("/api/v1/namespaces/default/containers?page=2", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{
+		"pagination": {"page": 2, "total_pages": 3},
+		"containers": [
+			{"name": "test-container-1", "created_at": "2024-01-02T03:04:05Z", "ttl_seconds": 86400},
+			{"name": "test-container-2", "created_at": "2024-01-02T03:04:05Z"}
+		]
+	}`, body)
+}
+
+func (s *handlersTestSuite) TestListVersions() {
+	s.serviceMock.On("ListPublishedVersionsByPage", defaultNamespace, "test-container-1", uint64(1)).Return(uint64(1), []models.Version{
+		{
+			Name:        "20240102030405",
+			IsPublished: true,
+			CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}, nil).Once()
+
+	code, _, body := s.doRequest("/api/v1/namespaces/default/containers/test-container-1/versions", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{
+		"pagination": {"page": 1, "total_pages": 1},
+		"versions": [
+			{"name": "20240102030405", "created_at": "2024-01-02T03:04:05Z"}
+		]
+	}`, body)
+}
+
+func (s *handlersTestSuite) TestListObjects() {
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "stable").Return(models.Version{
+		Name:        "20240102030405",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil).Once()
+	s.serviceMock.On("ListObjectsByPage", defaultNamespace, "test-container-1", "20240102030405", uint64(1)).Return(uint64(1), []models.Object{
+		{
+			Key:       "dir/file.txt",
+			Checksum:  "deadbeef",
+			Size:      9,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+		},
+	}, nil).Once()
+
+	code, _, body := s.doRequest("/api/v1/namespaces/default/containers/test-container-1/versions/stable/objects", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{
+		"pagination": {"page": 1, "total_pages": 1},
+		"version": {"name": "20240102030405", "created_at": "2024-01-02T03:04:05Z"},
+		"objects": [
+			{"key": "dir/file.txt", "size": 9, "mime_type": "text/plain", "checksum": "deadbeef", "created_at": "2024-01-02T03:04:06Z"}
+		]
+	}`, body)
+}
+
+func (s *handlersTestSuite) TestListObjectsUnpublishedVersion() {
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20240102030405").Return(models.Version{
+		Name:      "20240102030405",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil).Once()
+
+	code, _, body := s.doRequest("/api/v1/namespaces/default/containers/test-container-1/versions/20240102030405/objects", func(r *http.Request) {})
+	s.Require().Equal(http.StatusNotFound, code)
+	s.Require().JSONEq(`{"error":"not found"}`, body)
+}
+
+func (s *handlersTestSuite) TestDiffVersions() {
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20240102030405").Return(models.Version{
+		Name:        "20240102030405",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil).Once()
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "latest").Return(models.Version{
+		Name:        "20240102030406",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+	}, nil).Once()
+	s.serviceMock.On("DiffVersions", defaultNamespace, "test-container-1", "20240102030405", "20240102030406").Return(models.VersionDiff{
+		FromVersion: "20240102030405",
+		ToVersion:   "20240102030406",
+		Added: []models.Object{
+			{Key: "dir/new.txt", Checksum: "cccc", Size: 30, MimeType: "text/plain"},
+		},
+		Removed: []models.Object{
+			{Key: "dir/old.txt", Checksum: "aaaa", Size: 10, MimeType: "text/plain"},
+		},
+		Changed: []models.ObjectChange{
+			{Key: "dir/file.txt", OldChecksum: "bbbb", OldSize: 20, NewChecksum: "dddd", NewSize: 40},
+		},
+		AddedBytes:      30,
+		RemovedBytes:    10,
+		ChangedOldBytes: 20,
+		ChangedNewBytes: 40,
+	}, nil).Once()
+
+	code, _, body := s.doRequest("/api/v1/namespaces/default/containers/test-container-1/versions/20240102030405/diff/latest", func(r *http.Request) {})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{
+		"from": {"name": "20240102030405", "created_at": "2024-01-02T03:04:05Z"},
+		"to": {"name": "20240102030406", "created_at": "2024-01-02T03:04:06Z"},
+		"added": [
+			{"key": "dir/new.txt", "size": 30, "mime_type": "text/plain", "checksum": "cccc"}
+		],
+		"removed": [
+			{"key": "dir/old.txt", "size": 10, "mime_type": "text/plain", "checksum": "aaaa"}
+		],
+		"changed": [
+			{"key": "dir/file.txt", "old_size": 20, "old_checksum": "bbbb", "new_size": 40, "new_checksum": "dddd"}
+		],
+		"added_bytes": 30,
+		"removed_bytes": 10,
+		"changed_old_bytes": 20,
+		"changed_new_bytes": 40
+	}`, body)
+}
+
+func (s *handlersTestSuite) TestDiffVersionsUnpublishedVersion() {
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20240102030405").Return(models.Version{
+		Name:        "20240102030405",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil).Once()
+	s.serviceMock.On("GetVersion", defaultNamespace, "test-container-1", "20240102030406").Return(models.Version{
+		Name:      "20240102030406",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+	}, nil).Once()
+
+	code, _, body := s.doRequest("/api/v1/namespaces/default/containers/test-container-1/versions/20240102030405/diff/20240102030406", func(r *http.Request) {})
+	s.Require().Equal(http.StatusNotFound, code)
+	s.Require().JSONEq(`{"error":"not found"}`, body)
+}
+
+func (s *handlersTestSuite) TestErrors() {
+	s.serviceMock.On("ListContainersByPage", defaultNamespace, uint64(1)).Return(uint64(0), []models.Container(nil), service.ErrNotFound).Once()
+
+	code, _, body := s.doRequest("/api/v1/namespaces/default/containers", func(r *http.Request) {})
+	s.Require().Equal(http.StatusNotFound, code)
+	s.Require().JSONEq(`{"error":"not found"}`, body)
+
+	s.serviceMock.On("ListPublishedVersionsByPage", defaultNamespace, "test-container-1", uint64(1)).Return(uint64(0), []models.Version(nil), io.ErrUnexpectedEOF).Once()
+
+	code, _, body = s.doRequest("/api/v1/namespaces/default/containers/test-container-1/versions", func(r *http.Request) {})
+	s.Require().Equal(http.StatusInternalServerError, code)
+	s.Require().JSONEq(`{"error":"internal server error"}`, body)
+}
+
+func (s *handlersTestSuite) TestPrivateNamespaceAccess() {
+	code, header, body := s.doRequest("/api/v1/namespaces/private/containers", func(r *http.Request) {})
+	s.Require().Equal(http.StatusUnauthorized, code)
+	s.Require().Equal(`Basic realm="archived", charset="UTF-8"`, header.Get("WWW-Authenticate"))
+	s.Require().JSONEq(`{"error":"unauthorized"}`, body)
+
+	code, _, body = s.doRequest("/api/v1/namespaces/private/containers", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer other-token")
+	})
+	s.Require().Equal(http.StatusNotFound, code)
+	s.Require().JSONEq(`{"error":"not found"}`, body)
+
+	s.serviceMock.On("ListContainersByPage", "private", uint64(1)).Return(uint64(1), []models.Container{}, nil).Once()
+
+	code, _, body = s.doRequest("/api/v1/namespaces/private/containers", func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer test-token")
+	})
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().JSONEq(`{"pagination":{"page":1,"total_pages":1},"containers":[]}`, body)
+}
+
+// Definitions ...
+type handlersTestSuite struct {
+	suite.Suite
+
+	ctx context.Context
+
+	srv *httptest.Server
+
+	serviceMock *service.Mock
+	handlers    Handlers
+}
+
+func (s *handlersTestSuite) SetupTest() {
+	s.ctx = context.TODO()
+
+	e := echo.New()
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+
+	s.serviceMock = service.NewMock()
+	s.serviceMock.On("ListPrivateNamespaces").Return([]string{"private"}, nil).Maybe()
+
+	s.handlers = New(s.serviceMock, access.New(access.Config{
+		Tokens: []access.Token{
+			{Token: "test-token", Namespaces: []string{"private"}},
+			{Token: "other-token", Namespaces: []string{"other"}},
+		},
+	}))
+	s.handlers.Register(e)
+
+	s.srv = httptest.NewServer(e)
+}
+
+func (s *handlersTestSuite) TearDownTest() {
+	s.serviceMock.AssertExpectations(s.T())
+
+	s.srv.Close()
+}
+
+func TestHandlersTestSuite(t *testing.T) {
+	suite.Run(t, &handlersTestSuite{})
+}
+
+func (s *handlersTestSuite) doRequest(path string, setupFn func(r *http.Request)) (int, http.Header, string) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.srv.URL+path, nil)
+	s.Require().NoError(err)
+
+	setupFn(req)
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	return resp.StatusCode, resp.Header, string(data)
+}
@@ -2,14 +2,18 @@ package aws
 
 import (
 	"context"
+	"io"
 	"net/url"
 	"path"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/pkg/errors"
 
+	"github.com/teran/archived/models"
 	"github.com/teran/archived/repositories/blob"
 )
 
@@ -58,3 +62,129 @@ func (s *s3driver) GetBlobURL(ctx context.Context, key, mimeType, filename strin
 	}
 	return result.URL, nil
 }
+
+func (s *s3driver) GetBlob(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	rng := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+	if length >= 0 {
+		rng += strconv.FormatInt(offset+length-1, 10)
+	}
+
+	out, err := s.cli.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(rng),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting object")
+	}
+	return out.Body, nil
+}
+
+func (s *s3driver) DeleteBlob(ctx context.Context, key string) error {
+	_, err := s.cli.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error deleting object")
+	}
+	return nil
+}
+
+func (s *s3driver) BlobSize(ctx context.Context, key string) (uint64, error) {
+	out, err := s.cli.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return 0, blob.ErrNotFound
+		}
+		return 0, errors.Wrap(err, "error performing HEAD request for object")
+	}
+	return uint64(aws.ToInt64(out.ContentLength)), nil
+}
+
+func (s *s3driver) ListBlobs(ctx context.Context, fn func(blob.Info) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.cli, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return errors.Wrap(err, "error listing objects")
+		}
+
+		for _, obj := range page.Contents {
+			if err := fn(blob.Info{
+				Key:        aws.ToString(obj.Key),
+				Size:       uint64(aws.ToInt64(obj.Size)),
+				ModifiedAt: aws.ToTime(obj.LastModified),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *s3driver) CreateMultipartUpload(ctx context.Context, key string) (string, error) {
+	out, err := s.cli.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "error creating multipart upload")
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+func (s *s3driver) PutBlobPartURL(ctx context.Context, key, uploadID string, partNumber int32) (string, error) {
+	result, err := s.presign.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucket),
+		Key:        aws.String(key),
+		UploadId:   aws.String(uploadID),
+		PartNumber: aws.Int32(partNumber),
+	}, s3.WithPresignExpires(s.ttl))
+	if err != nil {
+		return "", errors.Wrap(err, "error signing URL")
+	}
+	return result.URL, nil
+}
+
+func (s *s3driver) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.UploadPart) error {
+	completed := []types.CompletedPart{}
+	for _, p := range parts {
+		completed = append(completed, types.CompletedPart{
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int32(p.Number),
+		})
+	}
+
+	_, err := s.cli.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completed,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "error completing multipart upload")
+	}
+	return nil
+}
+
+func (s *s3driver) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := s.cli.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return errors.Wrap(err, "error aborting multipart upload")
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/suite"
+	"github.com/teran/archived/models"
 	"github.com/teran/archived/repositories/blob"
 	"github.com/teran/go-docker-testsuite/applications/minio"
 )
@@ -33,6 +34,106 @@ func (s *repoTestSuite) TestAll() {
 	s.Require().Equal("application/json", mimeType)
 	s.Require().Equal(`attachment; filename="test-file.txt"`, disposition)
 	s.Require().Equal("test data", string(data))
+
+	rc, err := s.driver.GetBlob(s.ctx, "blah/test/key.txt", 5, 2)
+	s.Require().NoError(err)
+	defer func() { _ = rc.Close() }()
+
+	data, err = io.ReadAll(rc)
+	s.Require().NoError(err)
+	s.Require().Equal("da", string(data))
+}
+
+func (s *repoTestSuite) TestDeleteBlob() {
+	url, err := s.driver.PutBlobURL(s.ctx, "blah/test/key.txt")
+	s.Require().NoError(err)
+
+	err = uploadToURL(s.ctx, url, []byte("test data"))
+	s.Require().NoError(err)
+
+	err = s.driver.DeleteBlob(s.ctx, "blah/test/key.txt")
+	s.Require().NoError(err)
+
+	out, err := s.cli.ListObjects(s.ctx, &s3.ListObjectsInput{
+		Bucket: aws.String("test-bucket"),
+	})
+	s.Require().NoError(err)
+	s.Require().Empty(out.Contents)
+}
+
+func (s *repoTestSuite) TestBlobSize() {
+	_, err := s.driver.BlobSize(s.ctx, "blah/test/key.txt")
+	s.Require().Equal(blob.ErrNotFound, err)
+
+	url, err := s.driver.PutBlobURL(s.ctx, "blah/test/key.txt")
+	s.Require().NoError(err)
+
+	err = uploadToURL(s.ctx, url, []byte("test data"))
+	s.Require().NoError(err)
+
+	size, err := s.driver.BlobSize(s.ctx, "blah/test/key.txt")
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(9), size)
+}
+
+func (s *repoTestSuite) TestListBlobs() {
+	for _, key := range []string{"blah/test/key2.txt", "blah/test/key1.txt"} {
+		url, err := s.driver.PutBlobURL(s.ctx, key)
+		s.Require().NoError(err)
+
+		err = uploadToURL(s.ctx, url, []byte("test data"))
+		s.Require().NoError(err)
+	}
+
+	keys := []string{}
+	err := s.driver.ListBlobs(s.ctx, func(b blob.Info) error {
+		s.Require().Equal(uint64(9), b.Size)
+		s.Require().False(b.ModifiedAt.IsZero())
+		keys = append(keys, b.Key)
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"blah/test/key1.txt", "blah/test/key2.txt"}, keys)
+}
+
+func (s *repoTestSuite) TestMultipartUpload() {
+	uploadID, err := s.driver.CreateMultipartUpload(s.ctx, "blah/test/multipart.txt")
+	s.Require().NoError(err)
+
+	url, err := s.driver.PutBlobPartURL(s.ctx, "blah/test/multipart.txt", uploadID, 1)
+	s.Require().NoError(err)
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPut, url, bytes.NewReader([]byte("test data")))
+	s.Require().NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	err = s.driver.CompleteMultipartUpload(s.ctx, "blah/test/multipart.txt", uploadID, []models.UploadPart{
+		{Number: 1, ETag: resp.Header.Get("ETag")},
+	})
+	s.Require().NoError(err)
+
+	rc, err := s.driver.GetBlob(s.ctx, "blah/test/multipart.txt", 0, -1)
+	s.Require().NoError(err)
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+	s.Require().NoError(err)
+	s.Require().Equal("test data", string(data))
+}
+
+func (s *repoTestSuite) TestAbortMultipartUpload() {
+	uploadID, err := s.driver.CreateMultipartUpload(s.ctx, "blah/test/multipart.txt")
+	s.Require().NoError(err)
+
+	err = s.driver.AbortMultipartUpload(s.ctx, "blah/test/multipart.txt", uploadID)
+	s.Require().NoError(err)
+
+	_, err = s.driver.BlobSize(s.ctx, "blah/test/multipart.txt")
+	s.Require().Equal(blob.ErrNotFound, err)
 }
 
 // Definitions ...
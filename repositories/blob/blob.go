@@ -2,9 +2,44 @@ package blob
 
 import (
 	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/teran/archived/models"
+)
+
+var (
+	ErrNotFound     = errors.New("blob not found")
+	ErrNotSupported = errors.New("operation is not supported by blob driver")
 )
 
+// Info describes the blob actually stored under the key
+type Info struct {
+	Key        string
+	Size       uint64
+	ModifiedAt time.Time
+}
+
 type Repository interface {
 	PutBlobURL(ctx context.Context, key string) (string, error)
 	GetBlobURL(ctx context.Context, key, mimeType, filename string) (string, error)
+	// GetBlob returns reader for length bytes of blob starting at offset,
+	// negative length means reading until the end of blob
+	GetBlob(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	DeleteBlob(ctx context.Context, key string) error
+	// BlobSize returns size of the blob data actually stored under the key
+	// or ErrNotFound if there's no such blob
+	BlobSize(ctx context.Context, key string) (uint64, error)
+	// ListBlobs calls fn for every blob stored in ascending key order
+	// and stops on the first error returned by fn
+	ListBlobs(ctx context.Context, fn func(blob Info) error) error
+
+	// Multipart uploads are optional so drivers which don't support them
+	// return ErrNotSupported
+	CreateMultipartUpload(ctx context.Context, key string) (uploadID string, err error)
+	PutBlobPartURL(ctx context.Context, key, uploadID string, partNumber int32) (string, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []models.UploadPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
 }
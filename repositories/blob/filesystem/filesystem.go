@@ -0,0 +1,371 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/blob"
+)
+
+var (
+	_ blob.Repository = (*repository)(nil)
+
+	ErrMalformedKey = errors.New("malformed key")
+
+	keyRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9._-]{3,}$`)
+)
+
+const (
+	expiresParam     = "expires"
+	signatureParam   = "signature"
+	contentTypeParam = "content_type"
+	filenameParam    = "filename"
+)
+
+// Repository stores blobs on local filesystem and serves them with
+// the signed expiring URLs via its own HTTP handler
+type Repository interface {
+	blob.Repository
+	http.Handler
+}
+
+type repository struct {
+	root       string
+	baseURL    *url.URL
+	signingKey []byte
+	ttl        time.Duration
+	tp         func() time.Time
+}
+
+func New(root, baseURL string, signingKey []byte, ttl time.Duration) (Repository, error) {
+	return newWithTimeProvider(root, baseURL, signingKey, ttl, time.Now)
+}
+
+func newWithTimeProvider(root, baseURL string, signingKey []byte, ttl time.Duration, tp func() time.Time) (Repository, error) {
+	if root == "" {
+		return nil, errors.New("root directory is required")
+	}
+
+	if len(signingKey) == 0 {
+		return nil, errors.New("signing key is required")
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing base URL")
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, errors.Wrap(err, "error creating root directory")
+	}
+
+	return &repository{
+		root:       root,
+		baseURL:    u,
+		signingKey: signingKey,
+		ttl:        ttl,
+		tp:         tp,
+	}, nil
+}
+
+func (r *repository) PutBlobURL(_ context.Context, key string) (string, error) {
+	if !keyRegexp.MatchString(key) {
+		return "", ErrMalformedKey
+	}
+
+	return r.signedURL(http.MethodPut, key, url.Values{}), nil
+}
+
+func (r *repository) GetBlobURL(_ context.Context, key, mimeType, filename string) (string, error) {
+	if !keyRegexp.MatchString(key) {
+		return "", ErrMalformedKey
+	}
+
+	return r.signedURL(http.MethodGet, key, url.Values{
+		contentTypeParam: []string{mimeType},
+		filenameParam:    []string{filename},
+	}), nil
+}
+
+func (r *repository) GetBlob(_ context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if !keyRegexp.MatchString(key) {
+		return nil, ErrMalformedKey
+	}
+
+	fp, err := os.Open(r.blobPath(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening blob")
+	}
+
+	if _, err := fp.Seek(offset, io.SeekStart); err != nil {
+		_ = fp.Close()
+		return nil, errors.Wrap(err, "error seeking blob")
+	}
+
+	if length < 0 {
+		return fp, nil
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(fp, length), fp}, nil
+}
+
+func (r *repository) DeleteBlob(_ context.Context, key string) error {
+	if !keyRegexp.MatchString(key) {
+		return ErrMalformedKey
+	}
+
+	if err := os.Remove(r.blobPath(key)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "error deleting blob")
+	}
+	return nil
+}
+
+func (r *repository) BlobSize(_ context.Context, key string) (uint64, error) {
+	if !keyRegexp.MatchString(key) {
+		return 0, ErrMalformedKey
+	}
+
+	st, err := os.Stat(r.blobPath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, blob.ErrNotFound
+		}
+		return 0, errors.Wrap(err, "error performing stat on blob")
+	}
+	return uint64(st.Size()), nil
+}
+
+func (r *repository) ListBlobs(ctx context.Context, fn func(blob.Info) error) error {
+	// WalkDir visits entries in lexical order and shard directories are
+	// the key prefixes so blobs are listed in ascending key order
+	err := filepath.WalkDir(r.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		// temporary upload files and anything not placed as a blob
+		// are not blobs
+		key := d.Name()
+		if !keyRegexp.MatchString(key) || r.blobPath(key) != p {
+			return nil
+		}
+
+		st, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		return fn(blob.Info{
+			Key:        key,
+			Size:       uint64(st.Size()),
+			ModifiedAt: st.ModTime(),
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "error listing blobs")
+	}
+	return nil
+}
+
+func (r *repository) CreateMultipartUpload(_ context.Context, _ string) (string, error) {
+	return "", blob.ErrNotSupported
+}
+
+func (r *repository) PutBlobPartURL(_ context.Context, _, _ string, _ int32) (string, error) {
+	return "", blob.ErrNotSupported
+}
+
+func (r *repository) CompleteMultipartUpload(_ context.Context, _, _ string, _ []models.UploadPart) error {
+	return blob.ErrNotSupported
+}
+
+func (r *repository) AbortMultipartUpload(_ context.Context, _, _ string) error {
+	return blob.ErrNotSupported
+}
+
+func (r *repository) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	key := path.Base(req.URL.Path)
+	if !keyRegexp.MatchString(key) {
+		http.NotFound(w, req)
+		return
+	}
+
+	method := req.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	if method != http.MethodGet && method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !r.verify(method, key, req.URL.Query()) {
+		http.Error(w, "signature mismatch or link expired", http.StatusForbidden)
+		return
+	}
+
+	switch method {
+	case http.MethodGet:
+		r.serveBlob(w, req, key)
+	case http.MethodPut:
+		r.storeBlob(w, req, key)
+	}
+}
+
+func (r *repository) serveBlob(w http.ResponseWriter, req *http.Request, key string) {
+	fp, err := os.Open(r.blobPath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.NotFound(w, req)
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"key":   key,
+			"error": err,
+		}).Error("error opening blob")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = fp.Close() }()
+
+	st, err := fp.Stat()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	q := req.URL.Query()
+	if ct := q.Get(contentTypeParam); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+
+	if fn := q.Get(filenameParam); fn != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+url.QueryEscape(path.Base(fn))+`"`)
+	}
+
+	http.ServeContent(w, req, "", st.ModTime(), fp)
+}
+
+func (r *repository) storeBlob(w http.ResponseWriter, req *http.Request, key string) {
+	blobPath := r.blobPath(key)
+
+	if err := r.writeFile(blobPath, req.Body); err != nil {
+		log.WithFields(log.Fields{
+			"key":   key,
+			"error": err,
+		}).Error("error storing blob")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *repository) writeFile(blobPath string, rd io.Reader) error {
+	dir := filepath.Dir(blobPath)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return errors.Wrap(err, "error creating shard directory")
+	}
+
+	// Write to the temporary file first and rename it afterwards to avoid
+	// serving partially written blobs
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return errors.Wrap(err, "error creating temporary file")
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := io.Copy(tmp, rd); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "error writing blob data")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "error closing temporary file")
+	}
+
+	if err := os.Rename(tmp.Name(), blobPath); err != nil {
+		return errors.Wrap(err, "error renaming temporary file")
+	}
+	return nil
+}
+
+func (r *repository) blobPath(key string) string {
+	return filepath.Join(r.root, key[0:2], key[2:4], key)
+}
+
+func (r *repository) signedURL(method, key string, params url.Values) string {
+	expires := strconv.FormatInt(r.tp().Add(r.ttl).Unix(), 10)
+
+	params.Set(expiresParam, expires)
+	params.Set(signatureParam, r.sign(method, key, expires, params.Get(contentTypeParam), params.Get(filenameParam)))
+
+	u := *r.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawQuery = params.Encode()
+
+	return u.String()
+}
+
+func (r *repository) verify(method, key string, params url.Values) bool {
+	expires := params.Get(expiresParam)
+	ts, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if r.tp().Unix() > ts {
+		return false
+	}
+
+	signature, err := hex.DecodeString(params.Get(signatureParam))
+	if err != nil {
+		return false
+	}
+
+	expected, err := hex.DecodeString(r.sign(method, key, expires, params.Get(contentTypeParam), params.Get(filenameParam)))
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(signature, expected)
+}
+
+func (r *repository) sign(method, key, expires, contentType, filename string) string {
+	mac := hmac.New(sha256.New, r.signingKey)
+	_, _ = mac.Write([]byte(strings.Join([]string{method, key, expires, contentType, filename}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
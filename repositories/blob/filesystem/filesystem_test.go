@@ -0,0 +1,225 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/teran/archived/repositories/blob"
+)
+
+const testKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func (s *repoTestSuite) TestAll() {
+	url, err := s.repo.PutBlobURL(s.ctx, testKey)
+	s.Require().NoError(err)
+
+	code := s.upload(url, []byte("test data"))
+	s.Require().Equal(http.StatusOK, code)
+
+	_, err = os.Stat(filepath.Join(s.root, "01", "23", testKey))
+	s.Require().NoError(err)
+
+	url, err = s.repo.GetBlobURL(s.ctx, testKey, "application/json", "some/dir/test-file.txt")
+	s.Require().NoError(err)
+
+	resp, err := http.Get(url)
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	s.Require().NoError(err)
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal("application/json", resp.Header.Get("Content-Type"))
+	s.Require().Equal(`attachment; filename="test-file.txt"`, resp.Header.Get("Content-Disposition"))
+	s.Require().Equal("test data", string(data))
+}
+
+func (s *repoTestSuite) TestGetBlob() {
+	url, err := s.repo.PutBlobURL(s.ctx, testKey)
+	s.Require().NoError(err)
+
+	code := s.upload(url, []byte("test data"))
+	s.Require().Equal(http.StatusOK, code)
+
+	rc, err := s.repo.GetBlob(s.ctx, testKey, 5, 2)
+	s.Require().NoError(err)
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+	s.Require().NoError(err)
+	s.Require().Equal("da", string(data))
+
+	rc2, err := s.repo.GetBlob(s.ctx, testKey, 5, -1)
+	s.Require().NoError(err)
+	defer func() { _ = rc2.Close() }()
+
+	data, err = io.ReadAll(rc2)
+	s.Require().NoError(err)
+	s.Require().Equal("data", string(data))
+}
+
+func (s *repoTestSuite) TestDeleteBlob() {
+	url, err := s.repo.PutBlobURL(s.ctx, testKey)
+	s.Require().NoError(err)
+
+	code := s.upload(url, []byte("test data"))
+	s.Require().Equal(http.StatusOK, code)
+
+	err = s.repo.DeleteBlob(s.ctx, testKey)
+	s.Require().NoError(err)
+
+	_, err = os.Stat(filepath.Join(s.root, "01", "23", testKey))
+	s.Require().True(os.IsNotExist(err))
+
+	// deleting non-existent blob is not an error
+	err = s.repo.DeleteBlob(s.ctx, testKey)
+	s.Require().NoError(err)
+}
+
+func (s *repoTestSuite) TestBlobSize() {
+	_, err := s.repo.BlobSize(s.ctx, testKey)
+	s.Require().Equal(blob.ErrNotFound, err)
+
+	url, err := s.repo.PutBlobURL(s.ctx, testKey)
+	s.Require().NoError(err)
+
+	code := s.upload(url, []byte("test data"))
+	s.Require().Equal(http.StatusOK, code)
+
+	size, err := s.repo.BlobSize(s.ctx, testKey)
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(9), size)
+
+	_, err = s.repo.BlobSize(s.ctx, "../key")
+	s.Require().Equal(ErrMalformedKey, err)
+}
+
+func (s *repoTestSuite) TestListBlobs() {
+	const otherKey = "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"
+
+	for _, key := range []string{otherKey, testKey} {
+		url, err := s.repo.PutBlobURL(s.ctx, key)
+		s.Require().NoError(err)
+
+		code := s.upload(url, []byte("test data"))
+		s.Require().Equal(http.StatusOK, code)
+	}
+
+	// leftovers of interrupted upload and misplaced files are ignored
+	err := os.WriteFile(filepath.Join(s.root, "01", "23", ".upload-12345"), []byte("partial"), 0o600)
+	s.Require().NoError(err)
+
+	err = os.WriteFile(filepath.Join(s.root, "01", otherKey), []byte("misplaced"), 0o600)
+	s.Require().NoError(err)
+
+	keys := []string{}
+	err = s.repo.ListBlobs(s.ctx, func(b blob.Info) error {
+		s.Require().Equal(uint64(9), b.Size)
+		s.Require().False(b.ModifiedAt.IsZero())
+		keys = append(keys, b.Key)
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{testKey, otherKey}, keys)
+
+	err = s.repo.ListBlobs(s.ctx, func(b blob.Info) error {
+		return io.ErrUnexpectedEOF
+	})
+	s.Require().ErrorIs(err, io.ErrUnexpectedEOF)
+}
+
+func (s *repoTestSuite) TestMultipartUploadNotSupported() {
+	_, err := s.repo.CreateMultipartUpload(s.ctx, testKey)
+	s.Require().Equal(blob.ErrNotSupported, err)
+}
+
+func (s *repoTestSuite) TestExpiredURL() {
+	url, err := s.repo.PutBlobURL(s.ctx, testKey)
+	s.Require().NoError(err)
+
+	s.now = s.now.Add(10 * time.Minute)
+
+	code := s.upload(url, []byte("test data"))
+	s.Require().Equal(http.StatusForbidden, code)
+}
+
+func (s *repoTestSuite) TestSignatureMismatch() {
+	url, err := s.repo.GetBlobURL(s.ctx, testKey, "application/json", "test-file.txt")
+	s.Require().NoError(err)
+
+	// GET link is not valid for uploads
+	code := s.upload(url, []byte("test data"))
+	s.Require().Equal(http.StatusForbidden, code)
+
+	resp, err := http.Get(url + "x")
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+	s.Require().Equal(http.StatusForbidden, resp.StatusCode)
+}
+
+func (s *repoTestSuite) TestMalformedKey() {
+	_, err := s.repo.PutBlobURL(s.ctx, "../../etc/passwd")
+	s.Require().Error(err)
+	s.Require().Equal(ErrMalformedKey, err)
+
+	err = s.repo.DeleteBlob(s.ctx, "..")
+	s.Require().Error(err)
+	s.Require().Equal(ErrMalformedKey, err)
+}
+
+// Definitions ...
+type repoTestSuite struct {
+	suite.Suite
+
+	ctx  context.Context
+	root string
+	now  time.Time
+	srv  *httptest.Server
+	repo Repository
+}
+
+func (s *repoTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.root = s.T().TempDir()
+	s.now = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mux := http.NewServeMux()
+	s.srv = httptest.NewServer(mux)
+
+	var err error
+	s.repo, err = newWithTimeProvider(s.root, s.srv.URL+"/blobs/", []byte("test-key"), 5*time.Minute, func() time.Time {
+		return s.now
+	})
+	s.Require().NoError(err)
+
+	mux.Handle("/blobs/", s.repo)
+}
+
+func (s *repoTestSuite) TearDownTest() {
+	s.srv.Close()
+}
+
+func TestRepoTestSuite(t *testing.T) {
+	suite.Run(t, &repoTestSuite{})
+}
+
+func (s *repoTestSuite) upload(url string, data []byte) int {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPut, url, bytes.NewReader(data))
+	s.Require().NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer func() { _ = resp.Body.Close() }()
+
+	return resp.StatusCode
+}
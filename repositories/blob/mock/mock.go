@@ -2,9 +2,11 @@ package mock
 
 import (
 	"context"
+	"io"
 
 	"github.com/stretchr/testify/mock"
 
+	"github.com/teran/archived/models"
 	"github.com/teran/archived/repositories/blob"
 )
 
@@ -27,3 +29,49 @@ func (m *Mock) GetBlobURL(_ context.Context, key, mimeType, filename string) (st
 	args := m.Called(key, mimeType, filename)
 	return args.String(0), args.Error(1)
 }
+
+func (m *Mock) GetBlob(_ context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	args := m.Called(key, offset, length)
+	rc, _ := args.Get(0).(io.ReadCloser)
+	return rc, args.Error(1)
+}
+
+func (m *Mock) DeleteBlob(_ context.Context, key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *Mock) BlobSize(_ context.Context, key string) (uint64, error) {
+	args := m.Called(key)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *Mock) ListBlobs(_ context.Context, fn func(blob.Info) error) error {
+	args := m.Called()
+	for _, b := range args.Get(0).([]blob.Info) {
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *Mock) CreateMultipartUpload(_ context.Context, key string) (string, error) {
+	args := m.Called(key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *Mock) PutBlobPartURL(_ context.Context, key, uploadID string, partNumber int32) (string, error) {
+	args := m.Called(key, uploadID, partNumber)
+	return args.String(0), args.Error(1)
+}
+
+func (m *Mock) CompleteMultipartUpload(_ context.Context, key, uploadID string, parts []models.UploadPart) error {
+	args := m.Called(key, uploadID, parts)
+	return args.Error(0)
+}
+
+func (m *Mock) AbortMultipartUpload(_ context.Context, key, uploadID string) error {
+	args := m.Called(key, uploadID)
+	return args.Error(0)
+}
@@ -86,6 +86,16 @@ func (m *memcache) ListNamespaces(ctx context.Context) ([]string, error) {
 	return retrievedValue, nil
 }
 
+// ListPrivateNamespaces is not cached since the cache is not invalidated on
+// SetNamespacePrivate and stale list would expose private namespaces
+func (m *memcache) ListPrivateNamespaces(ctx context.Context) ([]string, error) {
+	return m.repo.ListPrivateNamespaces(ctx)
+}
+
+func (m *memcache) SetNamespacePrivate(ctx context.Context, name string, isPrivate bool) error {
+	return m.repo.SetNamespacePrivate(ctx, name, isPrivate)
+}
+
 func (m *memcache) DeleteNamespace(ctx context.Context, name string) error {
 	return m.repo.DeleteNamespace(ctx, name)
 }
@@ -98,8 +108,8 @@ func (m *memcache) RenameContainer(ctx context.Context, namespace, oldName, newN
 	return m.repo.RenameContainer(ctx, namespace, oldName, newNamespace, newName)
 }
 
-func (m *memcache) SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration) error {
-	return m.repo.SetContainerParameters(ctx, namespace, name, ttl)
+func (m *memcache) SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error {
+	return m.repo.SetContainerParameters(ctx, namespace, name, ttl, retention)
 }
 
 func (m *memcache) ListContainers(ctx context.Context, namespace string) ([]models.Container, error) {
@@ -199,6 +209,10 @@ func (m *memcache) CreateVersion(ctx context.Context, namespace, container strin
 	return m.repo.CreateVersion(ctx, namespace, container)
 }
 
+func (m *memcache) CloneVersion(ctx context.Context, namespace, container, version, destNamespace, destContainer string) (string, error) {
+	return m.repo.CloneVersion(ctx, namespace, container, version, destNamespace, destContainer)
+}
+
 func (m *memcache) GetLatestPublishedVersionByContainer(ctx context.Context, namespace, container string) (string, error) {
 	cacheKey := strings.Join([]string{
 		m.keyPrefix,
@@ -241,6 +255,12 @@ func (m *memcache) GetLatestPublishedVersionByContainer(ctx context.Context, nam
 	return retrievedValue, nil
 }
 
+// GetVersion is not cached since publication and protection state of the
+// version changes and the cache is not invalidated
+func (m *memcache) GetVersion(ctx context.Context, namespace, container, version string) (models.Version, error) {
+	return m.repo.GetVersion(ctx, namespace, container, version)
+}
+
 func (m *memcache) ListAllVersionsByContainer(ctx context.Context, namespace, container string) ([]models.Version, error) {
 	cacheKey := strings.Join([]string{
 		m.keyPrefix,
@@ -382,6 +402,10 @@ func (m *memcache) MarkVersionPublished(ctx context.Context, namespace, containe
 	return m.repo.MarkVersionPublished(ctx, namespace, container, version)
 }
 
+func (m *memcache) SetVersionProtected(ctx context.Context, namespace, container, version string, isProtected bool) error {
+	return m.repo.SetVersionProtected(ctx, namespace, container, version, isProtected)
+}
+
 func (m *memcache) DeleteVersion(ctx context.Context, namespace, container, version string) error {
 	return m.repo.DeleteVersion(ctx, namespace, container, version)
 }
@@ -390,19 +414,51 @@ func (m *memcache) DeleteExpiredVersionsWithObjects(ctx context.Context, unpubli
 	return m.repo.DeleteExpiredVersionsWithObjects(ctx, unpublishedVersionsMaxAge)
 }
 
+func (m *memcache) ListExpiredVersionsWithObjects(ctx context.Context, unpublishedVersionsMaxAge time.Duration) ([]models.ExpiredVersion, error) {
+	return m.repo.ListExpiredVersionsWithObjects(ctx, unpublishedVersionsMaxAge)
+}
+
+func (m *memcache) CreateAlias(ctx context.Context, namespace, container, name, version string) error {
+	return m.repo.CreateAlias(ctx, namespace, container, name, version)
+}
+
+func (m *memcache) MoveAlias(ctx context.Context, namespace, container, name, version string) error {
+	return m.repo.MoveAlias(ctx, namespace, container, name, version)
+}
+
+func (m *memcache) DeleteAlias(ctx context.Context, namespace, container, name string) error {
+	return m.repo.DeleteAlias(ctx, namespace, container, name)
+}
+
+func (m *memcache) ListAliases(ctx context.Context, namespace, container string) ([]models.Alias, error) {
+	return m.repo.ListAliases(ctx, namespace, container)
+}
+
+// ResolveAlias is not cached since the cache is not invalidated on MoveAlias
+// and the alias would keep pointing to the previous version
+func (m *memcache) ResolveAlias(ctx context.Context, namespace, container, name string) (string, error) {
+	return m.repo.ResolveAlias(ctx, namespace, container, name)
+}
+
 func (m *memcache) CreateObject(ctx context.Context, namespace, container, version, key, casKey string) error {
 	return m.repo.CreateObject(ctx, namespace, container, version, key, casKey)
 }
 
-func (m *memcache) ListObjects(ctx context.Context, namespace, container, version string, offset, limit uint64) (uint64, []string, error) {
+func (m *memcache) CreateObjects(ctx context.Context, namespace, container, version string, objects []models.Object) ([]string, error) {
+	return m.repo.CreateObjects(ctx, namespace, container, version, objects)
+}
+
+func (m *memcache) ListObjects(ctx context.Context, namespace, container, version string, offset, limit uint64) (uint64, []models.Object, error) {
 	type proxy struct {
 		Total   uint64
-		Objects []string
+		Objects []models.Object
 	}
 
+	// v2 is used to avoid decoding of the cached keys-only listings
 	cacheKey := strings.Join([]string{
 		m.keyPrefix,
 		"ListObjects",
+		"v2",
 		namespace,
 		container,
 		version,
@@ -452,6 +508,12 @@ func (m *memcache) RemapObject(ctx context.Context, namespace, container, versio
 	return m.repo.RemapObject(ctx, namespace, container, version, key, newCASKey)
 }
 
+// DiffVersions is not cached since diffs of large versions could easily
+// exceed memcached item size limit
+func (m *memcache) DiffVersions(ctx context.Context, namespace, container, fromVersion, toVersion string) (models.VersionDiff, error) {
+	return m.repo.DiffVersions(ctx, namespace, container, fromVersion, toVersion)
+}
+
 func (m *memcache) CreateBLOB(ctx context.Context, checksum string, size uint64, mimeType string) error {
 	return m.repo.CreateBLOB(ctx, checksum, size, mimeType)
 }
@@ -548,6 +610,52 @@ func (m *memcache) EnsureBlobKey(ctx context.Context, key string, size uint64) e
 	return m.repo.EnsureBlobKey(ctx, key, size)
 }
 
+func (m *memcache) ConfirmBlob(ctx context.Context, key string, size uint64) error {
+	return m.repo.ConfirmBlob(ctx, key, size)
+}
+
+func (m *memcache) ListOrphanedBlobs(ctx context.Context, gracePeriod time.Duration, offset, limit uint64) ([]models.Blob, error) {
+	return m.repo.ListOrphanedBlobs(ctx, gracePeriod, offset, limit)
+}
+
+func (m *memcache) MarkOrphanedBlobsDeleting(ctx context.Context, gracePeriod time.Duration, limit uint64) ([]models.Blob, error) {
+	return m.repo.MarkOrphanedBlobsDeleting(ctx, gracePeriod, limit)
+}
+
+func (m *memcache) DeleteBlobs(ctx context.Context, checksum ...string) error {
+	return m.repo.DeleteBlobs(ctx, checksum...)
+}
+
+// MarkBlobsBroken is not reflected by cached GetBlobByObject results until
+// they expire
+func (m *memcache) MarkBlobsBroken(ctx context.Context, checksum ...string) error {
+	return m.repo.MarkBlobsBroken(ctx, checksum...)
+}
+
+func (m *memcache) ListBlobsAfter(ctx context.Context, cursor string, limit uint64) ([]models.Blob, error) {
+	return m.repo.ListBlobsAfter(ctx, cursor, limit)
+}
+
+func (m *memcache) CreateScrubResult(ctx context.Context, result models.ScrubResult) error {
+	return m.repo.CreateScrubResult(ctx, result)
+}
+
+func (m *memcache) GetScrubCursor(ctx context.Context) (string, error) {
+	return m.repo.GetScrubCursor(ctx)
+}
+
+func (m *memcache) SetScrubCursor(ctx context.Context, cursor string) error {
+	return m.repo.SetScrubCursor(ctx, cursor)
+}
+
+func (m *memcache) CreateAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	return m.repo.CreateAuditEvent(ctx, event)
+}
+
+func (m *memcache) ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, error) {
+	return m.repo.ListAuditEvents(ctx, filter)
+}
+
 func (m *memcache) CountStats(ctx context.Context) (*emodels.Stats, error) {
 	return m.repo.CountStats(ctx)
 }
@@ -90,6 +90,19 @@ func (s *memcacheTestSuite) TestGetLatestPublishedVersionByContainerError() {
 	s.Require().Equal("some error", err.Error())
 }
 
+func (s *memcacheTestSuite) TestResolveAlias() {
+	s.repoMock.On("ResolveAlias", defaultNamespace, "test-container", "stable").Return("test-version", nil).Once()
+	s.repoMock.On("ResolveAlias", defaultNamespace, "test-container", "stable").Return("test-version2", nil).Once()
+
+	version, err := s.cache.ResolveAlias(s.ctx, defaultNamespace, "test-container", "stable")
+	s.Require().NoError(err)
+	s.Require().Equal("test-version", version)
+
+	version, err = s.cache.ResolveAlias(s.ctx, defaultNamespace, "test-container", "stable")
+	s.Require().NoError(err)
+	s.Require().Equal("test-version2", version)
+}
+
 func (s *memcacheTestSuite) TestListAllVersionsByContainer() {
 	s.repoMock.On("ListAllVersionsByContainer", defaultNamespace, "test-container").Return([]models.Version{{Name: "test-version"}}, nil).Once()
 
@@ -153,21 +166,45 @@ func (s *memcacheTestSuite) TestListPublishedVersionsByContainerAndPageError() {
 }
 
 func (s *memcacheTestSuite) TestListObjects() {
-	s.repoMock.On("ListObjects", defaultNamespace, "test-container", "test-version", uint64(0), uint64(30)).Return(uint64(500), []string{"obj1"}, nil).Once()
+	s.repoMock.On("ListObjects", defaultNamespace, "test-container", "test-version", uint64(0), uint64(30)).Return(uint64(500), []models.Object{
+		{
+			Key:       "obj1",
+			Checksum:  "deadbeef",
+			Size:      1234,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}, nil).Once()
 
 	total, objects, err := s.cache.ListObjects(s.ctx, defaultNamespace, "test-container", "test-version", 0, 30)
 	s.Require().NoError(err)
-	s.Require().Equal([]string{"obj1"}, objects)
+	s.Require().Equal([]models.Object{
+		{
+			Key:       "obj1",
+			Checksum:  "deadbeef",
+			Size:      1234,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}, objects)
 	s.Require().Equal(uint64(500), total)
 
 	total, objects, err = s.cache.ListObjects(s.ctx, defaultNamespace, "test-container", "test-version", 0, 30)
 	s.Require().NoError(err)
-	s.Require().Equal([]string{"obj1"}, objects)
+	s.Require().Equal([]models.Object{
+		{
+			Key:       "obj1",
+			Checksum:  "deadbeef",
+			Size:      1234,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}, objects)
 	s.Require().Equal(uint64(500), total)
 }
 
 func (s *memcacheTestSuite) TestListObjectsError() {
-	s.repoMock.On("ListObjects", defaultNamespace, "test-container", "test-version", uint64(0), uint64(30)).Return(uint64(0), []string{}, errors.New("some error")).Once()
+	s.repoMock.On("ListObjects", defaultNamespace, "test-container", "test-version", uint64(0), uint64(30)).Return(uint64(0), []models.Object{}, errors.New("some error")).Once()
 
 	_, _, err := s.cache.ListObjects(s.ctx, defaultNamespace, "test-container", "test-version", 0, 30)
 	s.Require().Error(err)
@@ -249,6 +286,43 @@ func (s *memcacheTestSuite) TestDeleteNamespace() {
 	s.Require().NoError(err)
 }
 
+func (s *memcacheTestSuite) TestListPrivateNamespaces() {
+	s.repoMock.On("ListPrivateNamespaces").Return([]string{"namespace1"}, nil).Once()
+	s.repoMock.On("ListPrivateNamespaces").Return([]string{"namespace1", "namespace2"}, nil).Once()
+
+	namespaces, err := s.cache.ListPrivateNamespaces(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"namespace1"}, namespaces)
+
+	namespaces, err = s.cache.ListPrivateNamespaces(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"namespace1", "namespace2"}, namespaces)
+}
+
+func (s *memcacheTestSuite) TestGetVersion() {
+	s.repoMock.On("GetVersion", defaultNamespace, "container", "20240102030405").Return(models.Version{
+		Name:      "20240102030405",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil).Once()
+	s.repoMock.On("GetVersion", defaultNamespace, "container", "20240102030405").Return(models.Version{
+		Name:        "20240102030405",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil).Once()
+
+	v, err := s.cache.GetVersion(s.ctx, defaultNamespace, "container", "20240102030405")
+	s.Require().NoError(err)
+	s.Require().False(v.IsPublished)
+
+	v, err = s.cache.GetVersion(s.ctx, defaultNamespace, "container", "20240102030405")
+	s.Require().NoError(err)
+	s.Require().Equal(models.Version{
+		Name:        "20240102030405",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, v)
+}
+
 func (s *memcacheTestSuite) TestCreateContainer() {
 	s.repoMock.On("CreateContainer", defaultNamespace, "container1", time.Duration(-1)).Return(nil).Twice()
 
@@ -280,12 +354,12 @@ func (s *memcacheTestSuite) TestDeleteContainer() {
 }
 
 func (s *memcacheTestSuite) TestSetContainerVersionsParameters() {
-	s.repoMock.On("SetContainerParameters", defaultNamespace, "container1", 1*time.Hour).Return(nil).Twice()
+	s.repoMock.On("SetContainerParameters", defaultNamespace, "container1", 1*time.Hour, &models.RetentionPolicy{KeepLast: 3}).Return(nil).Twice()
 
-	err := s.cache.SetContainerParameters(s.ctx, defaultNamespace, "container1", 1*time.Hour)
+	err := s.cache.SetContainerParameters(s.ctx, defaultNamespace, "container1", 1*time.Hour, &models.RetentionPolicy{KeepLast: 3})
 	s.Require().NoError(err)
 
-	err = s.cache.SetContainerParameters(s.ctx, defaultNamespace, "container1", 1*time.Hour)
+	err = s.cache.SetContainerParameters(s.ctx, defaultNamespace, "container1", 1*time.Hour, &models.RetentionPolicy{KeepLast: 3})
 	s.Require().NoError(err)
 }
 
@@ -11,42 +11,87 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("not found")
-	ErrConflict = errors.New("entity with given identifier already exists")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("entity with given identifier already exists")
+	ErrProtected    = errors.New("version is protected")
+	ErrAliased      = errors.New("version is referenced by alias")
+	ErrNotPublished = errors.New("version is not published")
+	ErrNotConfirmed = errors.New("BLOB upload is not confirmed")
+	ErrDeleting     = errors.New("BLOB is being deleted")
 )
 
 type Repository interface {
 	CreateNamespace(ctx context.Context, name string) error
 	RenameNamespace(ctx context.Context, oldName, newName string) error
 	ListNamespaces(ctx context.Context) ([]string, error)
+	ListPrivateNamespaces(ctx context.Context) ([]string, error)
+	SetNamespacePrivate(ctx context.Context, name string, isPrivate bool) error
 	DeleteNamespace(ctx context.Context, name string) error
 
 	CreateContainer(ctx context.Context, namespace, name string, ttl time.Duration) error
 	RenameContainer(ctx context.Context, namespace, oldName, newNamespace, newName string) error
-	SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration) error
+	// SetContainerParameters leaves the retention policy unchanged if nil
+	SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error
 	ListContainers(ctx context.Context, namespace string) ([]models.Container, error)
 	ListContainersByPage(ctx context.Context, namespace string, offset, limit uint64) (uint64, []models.Container, error)
 	DeleteContainer(ctx context.Context, namespace, name string) error
 
 	CreateVersion(ctx context.Context, namespace, container string) (string, error)
+	CloneVersion(ctx context.Context, namespace, container, version, destNamespace, destContainer string) (string, error)
 	GetLatestPublishedVersionByContainer(ctx context.Context, namespace, container string) (string, error)
+	GetVersion(ctx context.Context, namespace, container, version string) (models.Version, error)
 	ListAllVersionsByContainer(ctx context.Context, namespace, container string) ([]models.Version, error)
 	ListPublishedVersionsByContainer(ctx context.Context, namespace, container string) ([]models.Version, error)
 	ListPublishedVersionsByContainerAndPage(ctx context.Context, namespace, container string, offset, limit uint64) (uint64, []models.Version, error)
 	ListUnpublishedVersionsByContainer(ctx context.Context, namespace, container string) ([]models.Version, error)
 	MarkVersionPublished(ctx context.Context, namespace, container, version string) error
+	SetVersionProtected(ctx context.Context, namespace, container, version string, isProtected bool) error
 	DeleteVersion(ctx context.Context, namespace, container, version string) error
 	DeleteExpiredVersionsWithObjects(ctx context.Context, unpublishedVersionsMaxAge time.Duration) error
+	ListExpiredVersionsWithObjects(ctx context.Context, unpublishedVersionsMaxAge time.Duration) ([]models.ExpiredVersion, error)
+
+	CreateAlias(ctx context.Context, namespace, container, name, version string) error
+	MoveAlias(ctx context.Context, namespace, container, name, version string) error
+	DeleteAlias(ctx context.Context, namespace, container, name string) error
+	ListAliases(ctx context.Context, namespace, container string) ([]models.Alias, error)
+	ResolveAlias(ctx context.Context, namespace, container, name string) (string, error)
 
 	CreateObject(ctx context.Context, namespace, container, version, key, casKey string) error
-	ListObjects(ctx context.Context, namespace, container, version string, offset, limit uint64) (uint64, []string, error)
+	// CreateObjects creates objects along with their blobs if missing and
+	// returns checksums of the blobs which upload is not confirmed yet
+	CreateObjects(ctx context.Context, namespace, container, version string, objects []models.Object) ([]string, error)
+	ListObjects(ctx context.Context, namespace, container, version string, offset, limit uint64) (uint64, []models.Object, error)
 	DeleteObject(ctx context.Context, namespace, container, version string, key ...string) error
 	RemapObject(ctx context.Context, namespace, container, version, key, newCASKey string) error
+	DiffVersions(ctx context.Context, namespace, container, fromVersion, toVersion string) (models.VersionDiff, error)
 
 	CreateBLOB(ctx context.Context, checksum string, size uint64, mimeType string) error
 	GetBlobKeyByObject(ctx context.Context, namespace, container, version, key string) (string, error)
 	GetBlobByObject(ctx context.Context, namespace, container, version, key string) (models.Blob, error)
 	EnsureBlobKey(ctx context.Context, key string, size uint64) error
+	// ConfirmBlob marks the blob as actually uploaded to the storage
+	// and resets the broken flag if it was uploaded again
+	ConfirmBlob(ctx context.Context, key string, size uint64) error
+	ListOrphanedBlobs(ctx context.Context, gracePeriod time.Duration, offset, limit uint64) ([]models.Blob, error)
+	// MarkOrphanedBlobsDeleting marks up to limit orphaned blobs as being
+	// deleted so they couldn't be referenced or uploaded again until their
+	// data is removed and DeleteBlobs is called
+	MarkOrphanedBlobsDeleting(ctx context.Context, gracePeriod time.Duration, limit uint64) ([]models.Blob, error)
+	// DeleteBlobs removes metadata of the blobs marked as being deleted
+	DeleteBlobs(ctx context.Context, checksum ...string) error
+	// MarkBlobsBroken marks blobs which data is lost so the objects
+	// referencing them can't be served
+	MarkBlobsBroken(ctx context.Context, checksum ...string) error
+
+	// ListBlobsAfter lists blobs ordered by checksum starting right after
+	// the cursor checksum
+	ListBlobsAfter(ctx context.Context, cursor string, limit uint64) ([]models.Blob, error)
+	CreateScrubResult(ctx context.Context, result models.ScrubResult) error
+	GetScrubCursor(ctx context.Context) (string, error)
+	SetScrubCursor(ctx context.Context, cursor string) error
+
+	CreateAuditEvent(ctx context.Context, event models.AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, error)
 
 	CountStats(ctx context.Context) (*emodels.Stats, error)
 }
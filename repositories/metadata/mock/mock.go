@@ -36,6 +36,16 @@ func (m *Mock) ListNamespaces(ctx context.Context) ([]string, error) {
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *Mock) ListPrivateNamespaces(ctx context.Context) ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *Mock) SetNamespacePrivate(ctx context.Context, name string, isPrivate bool) error {
+	args := m.Called(name, isPrivate)
+	return args.Error(0)
+}
+
 func (m *Mock) DeleteNamespace(ctx context.Context, name string) error {
 	args := m.Called(name)
 	return args.Error(0)
@@ -51,8 +61,8 @@ func (m *Mock) RenameContainer(_ context.Context, namespace, oldName, newNamespa
 	return args.Error(0)
 }
 
-func (m *Mock) SetContainerParameters(_ context.Context, namespace, name string, ttl time.Duration) error {
-	args := m.Called(namespace, name, ttl)
+func (m *Mock) SetContainerParameters(_ context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error {
+	args := m.Called(namespace, name, ttl, retention)
 	return args.Error(0)
 }
 
@@ -76,6 +86,16 @@ func (m *Mock) CreateVersion(_ context.Context, namespace, container string) (st
 	return args.String(0), args.Error(1)
 }
 
+func (m *Mock) CloneVersion(_ context.Context, namespace, container, version, destNamespace, destContainer string) (string, error) {
+	args := m.Called(namespace, container, version, destNamespace, destContainer)
+	return args.String(0), args.Error(1)
+}
+
+func (m *Mock) GetVersion(_ context.Context, namespace, container, version string) (models.Version, error) {
+	args := m.Called(namespace, container, version)
+	return args.Get(0).(models.Version), args.Error(1)
+}
+
 func (m *Mock) GetLatestPublishedVersionByContainer(_ context.Context, namespace, container string) (string, error) {
 	args := m.Called(namespace, container)
 	return args.String(0), args.Error(1)
@@ -106,6 +126,11 @@ func (m *Mock) MarkVersionPublished(_ context.Context, namespace, container, ver
 	return args.Error(0)
 }
 
+func (m *Mock) SetVersionProtected(_ context.Context, namespace, container, version string, isProtected bool) error {
+	args := m.Called(namespace, container, version, isProtected)
+	return args.Error(0)
+}
+
 func (m *Mock) DeleteVersion(ctx context.Context, namespace, container, version string) error {
 	args := m.Called(namespace, container, version)
 	return args.Error(0)
@@ -116,14 +141,49 @@ func (m *Mock) DeleteExpiredVersionsWithObjects(ctx context.Context, unpublished
 	return args.Error(0)
 }
 
+func (m *Mock) ListExpiredVersionsWithObjects(_ context.Context, unpublishedVersionsMaxAge time.Duration) ([]models.ExpiredVersion, error) {
+	args := m.Called(unpublishedVersionsMaxAge)
+	return args.Get(0).([]models.ExpiredVersion), args.Error(1)
+}
+
+func (m *Mock) CreateAlias(_ context.Context, namespace, container, name, version string) error {
+	args := m.Called(namespace, container, name, version)
+	return args.Error(0)
+}
+
+func (m *Mock) MoveAlias(_ context.Context, namespace, container, name, version string) error {
+	args := m.Called(namespace, container, name, version)
+	return args.Error(0)
+}
+
+func (m *Mock) DeleteAlias(_ context.Context, namespace, container, name string) error {
+	args := m.Called(namespace, container, name)
+	return args.Error(0)
+}
+
+func (m *Mock) ListAliases(_ context.Context, namespace, container string) ([]models.Alias, error) {
+	args := m.Called(namespace, container)
+	return args.Get(0).([]models.Alias), args.Error(1)
+}
+
+func (m *Mock) ResolveAlias(_ context.Context, namespace, container, name string) (string, error) {
+	args := m.Called(namespace, container, name)
+	return args.String(0), args.Error(1)
+}
+
 func (m *Mock) CreateObject(_ context.Context, namespace, container, version, key, casKey string) error {
 	args := m.Called(namespace, container, version, key, casKey)
 	return args.Error(0)
 }
 
-func (m *Mock) ListObjects(_ context.Context, namespace, container, version string, offset, limit uint64) (uint64, []string, error) {
+func (m *Mock) CreateObjects(_ context.Context, namespace, container, version string, objects []models.Object) ([]string, error) {
+	args := m.Called(namespace, container, version, objects)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *Mock) ListObjects(_ context.Context, namespace, container, version string, offset, limit uint64) (uint64, []models.Object, error) {
 	args := m.Called(namespace, container, version, offset, limit)
-	return args.Get(0).(uint64), args.Get(1).([]string), args.Error(2)
+	return args.Get(0).(uint64), args.Get(1).([]models.Object), args.Error(2)
 }
 
 func (m *Mock) DeleteObject(_ context.Context, namespace, container, version string, key ...string) error {
@@ -136,6 +196,11 @@ func (m *Mock) RemapObject(_ context.Context, namespace, container, version, key
 	return args.Error(0)
 }
 
+func (m *Mock) DiffVersions(_ context.Context, namespace, container, fromVersion, toVersion string) (models.VersionDiff, error) {
+	args := m.Called(namespace, container, fromVersion, toVersion)
+	return args.Get(0).(models.VersionDiff), args.Error(1)
+}
+
 func (m *Mock) CreateBLOB(_ context.Context, checksum string, size uint64, mimeType string) error {
 	args := m.Called(checksum, size, mimeType)
 	return args.Error(0)
@@ -156,6 +221,61 @@ func (m *Mock) EnsureBlobKey(_ context.Context, key string, size uint64) error {
 	return args.Error(0)
 }
 
+func (m *Mock) ConfirmBlob(_ context.Context, key string, size uint64) error {
+	args := m.Called(key, size)
+	return args.Error(0)
+}
+
+func (m *Mock) ListOrphanedBlobs(_ context.Context, gracePeriod time.Duration, offset, limit uint64) ([]models.Blob, error) {
+	args := m.Called(gracePeriod, offset, limit)
+	return args.Get(0).([]models.Blob), args.Error(1)
+}
+
+func (m *Mock) MarkOrphanedBlobsDeleting(_ context.Context, gracePeriod time.Duration, limit uint64) ([]models.Blob, error) {
+	args := m.Called(gracePeriod, limit)
+	return args.Get(0).([]models.Blob), args.Error(1)
+}
+
+func (m *Mock) DeleteBlobs(_ context.Context, checksum ...string) error {
+	args := m.Called(checksum)
+	return args.Error(0)
+}
+
+func (m *Mock) MarkBlobsBroken(_ context.Context, checksum ...string) error {
+	args := m.Called(checksum)
+	return args.Error(0)
+}
+
+func (m *Mock) ListBlobsAfter(_ context.Context, cursor string, limit uint64) ([]models.Blob, error) {
+	args := m.Called(cursor, limit)
+	return args.Get(0).([]models.Blob), args.Error(1)
+}
+
+func (m *Mock) CreateScrubResult(_ context.Context, result models.ScrubResult) error {
+	args := m.Called(result)
+	return args.Error(0)
+}
+
+func (m *Mock) GetScrubCursor(ctx context.Context) (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *Mock) SetScrubCursor(_ context.Context, cursor string) error {
+	args := m.Called(cursor)
+	return args.Error(0)
+}
+
+func (m *Mock) CreateAuditEvent(_ context.Context, event models.AuditEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *Mock) ListAuditEvents(_ context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
 func (m *Mock) CountStats(ctx context.Context) (*emodels.Stats, error) {
 	args := m.Called()
 	return args.Get(0).(*emodels.Stats), args.Error(1)
@@ -0,0 +1,247 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+func (r *repository) CreateAlias(ctx context.Context, namespace, container, name, version string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("error rolling back")
+		}
+	}()
+
+	containerID, versionID, err := getPublishedVersionID(ctx, tx, namespace, container, version)
+	if err != nil {
+		return err
+	}
+
+	now := r.tp().UTC()
+	_, err = insertQuery(ctx, tx, psql.
+		Insert("aliases").
+		Columns(
+			"container_id",
+			"version_id",
+			"name",
+			"created_at",
+			"updated_at",
+		).
+		Values(
+			containerID,
+			versionID,
+			name,
+			now,
+			now,
+		))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapSQLErrors(err)
+	}
+	return nil
+}
+
+func (r *repository) MoveAlias(ctx context.Context, namespace, container, name, version string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("error rolling back")
+		}
+	}()
+
+	containerID, versionID, err := getPublishedVersionID(ctx, tx, namespace, container, version)
+	if err != nil {
+		return err
+	}
+
+	res, err := updateQuery(ctx, tx, psql.
+		Update("aliases").
+		Set("version_id", versionID).
+		Set("updated_at", r.tp().UTC()).
+		Where(sq.Eq{
+			"container_id": containerID,
+			"name":         name,
+		}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if n == 0 {
+		return metadata.ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapSQLErrors(err)
+	}
+	return nil
+}
+
+func (r *repository) DeleteAlias(ctx context.Context, namespace, container, name string) error {
+	row, err := selectQueryRow(ctx, r.db, psql.
+		Select("a.id").
+		From("aliases a").
+		Join("containers c ON a.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": namespace,
+			"c.name": container,
+			"a.name": name,
+		}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	var aliasID uint64
+	if err := row.Scan(&aliasID); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	_, err = deleteQuery(ctx, r.db, psql.
+		Delete("aliases").
+		Where(sq.Eq{"id": aliasID}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+	return nil
+}
+
+func (r *repository) ListAliases(ctx context.Context, namespace, container string) ([]models.Alias, error) {
+	row, err := selectQueryRow(ctx, r.db, psql.
+		Select("c.id").
+		From("containers c").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": namespace,
+			"c.name": container,
+		}))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	var containerID uint64
+	if err := row.Scan(&containerID); err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	rows, err := selectQuery(ctx, r.db, psql.
+		Select(
+			"a.name",
+			"v.name",
+			"a.created_at",
+			"a.updated_at",
+		).
+		From("aliases a").
+		Join("versions v ON a.version_id = v.id").
+		Where(sq.Eq{"a.container_id": containerID}).
+		OrderBy("a.name"))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := []models.Alias{}
+	for rows.Next() {
+		var (
+			r         models.Alias
+			createdAt time.Time
+			updatedAt time.Time
+		)
+
+		if err := rows.Scan(&r.Name, &r.Version, &createdAt, &updatedAt); err != nil {
+			return nil, mapSQLErrors(err)
+		}
+
+		r.CreatedAt = time.Date(
+			createdAt.Year(), createdAt.Month(), createdAt.Day(),
+			createdAt.Hour(), createdAt.Minute(), createdAt.Second(), createdAt.Nanosecond(),
+			time.UTC,
+		)
+		r.UpdatedAt = time.Date(
+			updatedAt.Year(), updatedAt.Month(), updatedAt.Day(),
+			updatedAt.Hour(), updatedAt.Minute(), updatedAt.Second(), updatedAt.Nanosecond(),
+			time.UTC,
+		)
+
+		result = append(result, r)
+	}
+
+	return result, mapSQLErrors(rows.Err())
+}
+
+func (r *repository) ResolveAlias(ctx context.Context, namespace, container, name string) (string, error) {
+	row, err := selectQueryRow(ctx, r.db, psql.
+		Select("v.name").
+		From("aliases a").
+		Join("versions v ON a.version_id = v.id").
+		Join("containers c ON a.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": namespace,
+			"c.name": container,
+			"a.name": name,
+		}))
+	if err != nil {
+		return "", mapSQLErrors(err)
+	}
+
+	var versionName string
+	if err := row.Scan(&versionName); err != nil {
+		return "", mapSQLErrors(err)
+	}
+
+	return versionName, nil
+}
+
+func getPublishedVersionID(ctx context.Context, db queryRunner, namespace, container, version string) (containerID, versionID uint64, err error) {
+	row, err := selectQueryRow(ctx, db, psql.
+		Select("c.id", "v.id", "v.is_published").
+		From("versions v").
+		Join("containers c ON v.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": namespace,
+			"c.name": container,
+			"v.name": version,
+		}))
+	if err != nil {
+		return 0, 0, mapSQLErrors(err)
+	}
+
+	var isPublished bool
+	if err := row.Scan(&containerID, &versionID, &isPublished); err != nil {
+		return 0, 0, mapSQLErrors(err)
+	}
+
+	if !isPublished {
+		return 0, 0, metadata.ErrNotPublished
+	}
+
+	return containerID, versionID, nil
+}
@@ -0,0 +1,150 @@
+package postgresql
+
+import (
+	"time"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+func (s *postgreSQLRepositoryTestSuite) TestAliases() {
+	// CreateContainer (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:14Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:15Z").Once()
+
+	// CreateAlias (created_at, updated_at)
+	s.tp.On("Now").Return("2024-07-08T10:00:00Z").Once()
+
+	// MoveAlias (updated_at)
+	s.tp.On("Now").Return("2024-07-09T10:00:00Z").Once()
+
+	// DeleteVersion (blobs unreferenced_at)
+	s.tp.On("Now").Return("2024-07-10T10:00:00Z").Once()
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, "test-container", -1)
+	s.Require().NoError(err)
+
+	version1, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	version2, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	version3, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	for _, v := range []string{version1, version2} {
+		err = s.repo.MarkVersionPublished(s.ctx, defaultNamespace, "test-container", v)
+		s.Require().NoError(err)
+	}
+
+	err = s.repo.CreateAlias(s.ctx, defaultNamespace, "test-container", "stable", version3)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotPublished, err)
+
+	err = s.repo.CreateAlias(s.ctx, defaultNamespace, "test-container", "stable", "20010101010101")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	err = s.repo.CreateAlias(s.ctx, defaultNamespace, "test-container", "stable", version1)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateAlias(s.ctx, defaultNamespace, "test-container", "stable", version2)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrConflict, err)
+
+	version, err := s.repo.ResolveAlias(s.ctx, defaultNamespace, "test-container", "stable")
+	s.Require().NoError(err)
+	s.Require().Equal(version1, version)
+
+	err = s.repo.MoveAlias(s.ctx, defaultNamespace, "test-container", "stable", version2)
+	s.Require().NoError(err)
+
+	err = s.repo.MoveAlias(s.ctx, defaultNamespace, "test-container", "testing", version2)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	err = s.repo.DeleteVersion(s.ctx, defaultNamespace, "test-container", version2)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrAliased, err)
+
+	aliases, err := s.repo.ListAliases(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Alias{
+		{
+			Name:      "stable",
+			Version:   version2,
+			CreatedAt: time.Date(2024, 7, 8, 10, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2024, 7, 9, 10, 0, 0, 0, time.UTC),
+		},
+	}, aliases)
+
+	err = s.repo.DeleteAlias(s.ctx, defaultNamespace, "test-container", "stable")
+	s.Require().NoError(err)
+
+	_, err = s.repo.ResolveAlias(s.ctx, defaultNamespace, "test-container", "stable")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	err = s.repo.DeleteAlias(s.ctx, defaultNamespace, "test-container", "stable")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	err = s.repo.DeleteVersion(s.ctx, defaultNamespace, "test-container", version2)
+	s.Require().NoError(err)
+}
+
+func (s *postgreSQLRepositoryTestSuite) TestExpiredVersionsSkipAliased() {
+	// CreateContainer (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:14Z").Once()
+
+	// CreateAlias (created_at, updated_at)
+	s.tp.On("Now").Return("2024-07-08T10:00:00Z").Once()
+
+	// DeleteExpiredVersionsWithObjects (now())
+	s.tp.On("Now").Return("2024-10-08T11:11:11Z").Once()
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, "test-container", 48*time.Hour)
+	s.Require().NoError(err)
+
+	version1, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	version2, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	for _, v := range []string{version1, version2} {
+		err = s.repo.MarkVersionPublished(s.ctx, defaultNamespace, "test-container", v)
+		s.Require().NoError(err)
+	}
+
+	err = s.repo.CreateAlias(s.ctx, defaultNamespace, "test-container", "stable", version1)
+	s.Require().NoError(err)
+
+	err = s.repo.DeleteExpiredVersionsWithObjects(s.ctx, 24*time.Hour)
+	s.Require().NoError(err)
+
+	versions, err := s.repo.ListAllVersionsByContainer(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Version{
+		{
+			Name:        version1,
+			IsPublished: true,
+			CreatedAt:   time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC),
+		},
+	}, versions)
+}
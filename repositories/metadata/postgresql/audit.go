@@ -0,0 +1,121 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/teran/archived/models"
+)
+
+func (r *repository) CreateAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	parameters := event.Parameters
+	if parameters == nil {
+		parameters = map[string]string{}
+	}
+
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return errors.Wrap(err, "error marshaling audit event parameters")
+	}
+
+	_, err = insertQuery(ctx, r.db, psql.
+		Insert("audit_events").
+		Columns(
+			"actor",
+			"operation",
+			"namespace",
+			"parameters",
+			"result",
+			"error",
+			"created_at",
+		).
+		Values(
+			event.Actor,
+			event.Operation,
+			event.Namespace,
+			string(data),
+			string(event.Result),
+			event.Error,
+			r.tp().UTC(),
+		))
+	return mapSQLErrors(err)
+}
+
+func (r *repository) ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, error) {
+	q := psql.
+		Select(
+			"id",
+			"actor",
+			"operation",
+			"namespace",
+			"parameters",
+			"result",
+			"error",
+			"created_at",
+		).
+		From("audit_events").
+		OrderBy("id DESC")
+
+	if filter.Actor != "" {
+		q = q.Where(sq.Eq{"actor": filter.Actor})
+	}
+
+	if filter.Operation != "" {
+		q = q.Where(sq.Eq{"operation": filter.Operation})
+	}
+
+	if filter.Namespace != "" {
+		q = q.Where(sq.Eq{"namespace": filter.Namespace})
+	}
+
+	if !filter.Since.IsZero() {
+		q = q.Where(sq.GtOrEq{"created_at": filter.Since.UTC()})
+	}
+
+	if !filter.Until.IsZero() {
+		q = q.Where(sq.Lt{"created_at": filter.Until.UTC()})
+	}
+
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+
+	rows, err := selectQuery(ctx, r.db, q)
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := []models.AuditEvent{}
+	for rows.Next() {
+		var (
+			r          models.AuditEvent
+			parameters []byte
+			eventRes   string
+			createdAt  time.Time
+		)
+
+		if err := rows.Scan(&r.ID, &r.Actor, &r.Operation, &r.Namespace, &parameters, &eventRes, &r.Error, &createdAt); err != nil {
+			return nil, mapSQLErrors(err)
+		}
+
+		if err := json.Unmarshal(parameters, &r.Parameters); err != nil {
+			return nil, errors.Wrap(err, "error unmarshaling audit event parameters")
+		}
+
+		r.Result = models.AuditEventResult(eventRes)
+		r.CreatedAt = time.Date(
+			createdAt.Year(), createdAt.Month(), createdAt.Day(),
+			createdAt.Hour(), createdAt.Minute(), createdAt.Second(), createdAt.Nanosecond(),
+			time.UTC,
+		)
+
+		result = append(result, r)
+	}
+
+	return result, mapSQLErrors(rows.Err())
+}
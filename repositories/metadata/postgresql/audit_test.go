@@ -0,0 +1,91 @@
+package postgresql
+
+import (
+	"time"
+
+	"github.com/teran/archived/models"
+)
+
+func (s *postgreSQLRepositoryTestSuite) TestAuditEvents() {
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Once()
+	s.tp.On("Now").Return("2024-07-08T10:11:12Z").Once()
+	s.tp.On("Now").Return("2024-07-09T10:11:12Z").Once()
+
+	err := s.repo.CreateAuditEvent(s.ctx, models.AuditEvent{
+		Actor:     "john",
+		Operation: "CreateContainer",
+		Namespace: defaultNamespace,
+		Parameters: map[string]string{
+			"container": "test-container",
+		},
+		Result: models.AuditEventResultSuccess,
+	})
+	s.Require().NoError(err)
+
+	err = s.repo.CreateAuditEvent(s.ctx, models.AuditEvent{
+		Actor:     "jane",
+		Operation: "DeleteContainer",
+		Namespace: defaultNamespace,
+		Parameters: map[string]string{
+			"container": "test-container",
+		},
+		Result: models.AuditEventResultFailure,
+		Error:  "entity is protected",
+	})
+	s.Require().NoError(err)
+
+	err = s.repo.CreateAuditEvent(s.ctx, models.AuditEvent{
+		Actor:     "john",
+		Operation: "CreateNamespace",
+		Namespace: "test-namespace",
+		Result:    models.AuditEventResultSuccess,
+	})
+	s.Require().NoError(err)
+
+	events, err := s.repo.ListAuditEvents(s.ctx, models.AuditEventFilter{})
+	s.Require().NoError(err)
+	s.Require().Len(events, 3)
+	s.Require().Equal([]string{"CreateNamespace", "DeleteContainer", "CreateContainer"}, []string{
+		events[0].Operation, events[1].Operation, events[2].Operation,
+	})
+
+	events, err = s.repo.ListAuditEvents(s.ctx, models.AuditEventFilter{
+		Actor:     "jane",
+		Namespace: defaultNamespace,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(events, 1)
+	events[0].ID = 0
+	s.Require().Equal(models.AuditEvent{
+		Actor:     "jane",
+		Operation: "DeleteContainer",
+		Namespace: defaultNamespace,
+		Parameters: map[string]string{
+			"container": "test-container",
+		},
+		Result:    models.AuditEventResultFailure,
+		Error:     "entity is protected",
+		CreatedAt: time.Date(2024, 7, 8, 10, 11, 12, 0, time.UTC),
+	}, events[0])
+
+	events, err = s.repo.ListAuditEvents(s.ctx, models.AuditEventFilter{
+		Since: time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 7, 9, 0, 0, 0, 0, time.UTC),
+	})
+	s.Require().NoError(err)
+	s.Require().Len(events, 1)
+	s.Require().Equal("DeleteContainer", events[0].Operation)
+
+	events, err = s.repo.ListAuditEvents(s.ctx, models.AuditEventFilter{
+		Operation: "CreateNamespace",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(events, 1)
+	s.Require().Equal(map[string]string{}, events[0].Parameters)
+
+	events, err = s.repo.ListAuditEvents(s.ctx, models.AuditEventFilter{
+		Limit: 2,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(events, 2)
+}
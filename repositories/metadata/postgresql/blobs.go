@@ -2,9 +2,13 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+
 	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
 )
 
 func (r *repository) CreateBLOB(ctx context.Context, checksum string, size uint64, mimeType string) error {
@@ -14,12 +18,14 @@ func (r *repository) CreateBLOB(ctx context.Context, checksum string, size uint6
 			"checksum",
 			"size",
 			"mime_type",
+			"is_confirmed",
 			"created_at",
 		).
 		Values(
 			checksum,
 			size,
 			mimeType,
+			false,
 			r.tp().UTC(),
 		))
 	return mapSQLErrors(err)
@@ -59,6 +65,8 @@ func (r *repository) GetBlobByObject(ctx context.Context, namespace, container,
 			"b.checksum AS checksum",
 			"b.size AS size",
 			"b.mime_type AS mime_type",
+			"b.is_confirmed AS is_confirmed",
+			"b.is_broken AS is_broken",
 		).
 		From("blobs b").
 		Join("objects o ON o.blob_id = b.id").
@@ -78,7 +86,7 @@ func (r *repository) GetBlobByObject(ctx context.Context, namespace, container,
 	}
 
 	var b models.Blob
-	if err := row.Scan(&b.Checksum, &b.Size, &b.MimeType); err != nil {
+	if err := row.Scan(&b.Checksum, &b.Size, &b.MimeType, &b.IsConfirmed, &b.IsBroken); err != nil {
 		return models.Blob{}, mapSQLErrors(err)
 	}
 
@@ -87,7 +95,7 @@ func (r *repository) GetBlobByObject(ctx context.Context, namespace, container,
 
 func (r *repository) EnsureBlobKey(ctx context.Context, key string, size uint64) error {
 	row, err := selectQueryRow(ctx, r.db, psql.
-		Select("id").
+		Select("is_confirmed", "is_broken", "is_deleting").
 		From("blobs").
 		Where(sq.Eq{
 			"checksum": key,
@@ -97,10 +105,205 @@ func (r *repository) EnsureBlobKey(ctx context.Context, key string, size uint64)
 		return mapSQLErrors(err)
 	}
 
-	var blobID uint
-	if err := row.Scan(&blobID); err != nil {
+	var isConfirmed, isBroken, isDeleting bool
+	if err := row.Scan(&isConfirmed, &isBroken, &isDeleting); err != nil {
 		return mapSQLErrors(err)
 	}
 
+	if isDeleting {
+		return metadata.ErrDeleting
+	}
+
+	// broken BLOB data is lost so it's treated as pending to allow
+	// uploading it again
+	if !isConfirmed || isBroken {
+		return metadata.ErrNotConfirmed
+	}
 	return nil
 }
+
+func (r *repository) ConfirmBlob(ctx context.Context, key string, size uint64) error {
+	row, err := selectQueryRow(ctx, r.db, psql.
+		Select("size", "is_deleting").
+		From("blobs").
+		Where(sq.Eq{"checksum": key}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	var (
+		blobSize   uint64
+		isDeleting bool
+	)
+	if err := row.Scan(&blobSize, &isDeleting); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if blobSize != size {
+		return metadata.ErrConflict
+	}
+
+	if isDeleting {
+		return metadata.ErrDeleting
+	}
+
+	_, err = updateQuery(ctx, r.db, psql.
+		Update("blobs").
+		Set("is_confirmed", true).
+		Set("is_broken", false).
+		Where(sq.Eq{
+			"checksum":    key,
+			"is_deleting": false,
+		}))
+	return mapSQLErrors(err)
+}
+
+func (r *repository) MarkBlobsBroken(ctx context.Context, checksum ...string) error {
+	if len(checksum) == 0 {
+		return nil
+	}
+
+	return mapSQLErrors(indexChunks(len(checksum), expiredVersionsBatchSize, func(start, end int) error {
+		_, err := updateQuery(ctx, r.db, psql.
+			Update("blobs").
+			Set("is_broken", true).
+			Where(sq.Eq{"checksum": checksum[start:end]}))
+		return err
+	}))
+}
+
+func (r *repository) ListOrphanedBlobs(ctx context.Context, gracePeriod time.Duration, offset, limit uint64) ([]models.Blob, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	rows, err := selectQuery(ctx, r.db, psql.
+		Select(
+			"b.checksum AS checksum",
+			"b.size AS size",
+			"b.mime_type AS mime_type",
+		).
+		From("blobs b").
+		Where(orphanedBlobsCondition(r.tp().UTC(), gracePeriod)).
+		OrderBy("b.id").
+		Offset(offset).
+		Limit(limit))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanBlobs(rows)
+}
+
+func (r *repository) MarkOrphanedBlobsDeleting(ctx context.Context, gracePeriod time.Duration, limit uint64) ([]models.Blob, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	// the objects check is repeated for the locked rows so the blob
+	// referenced again in between is never marked, the blobs left marked by
+	// the interrupted run are picked up again
+	rows, err := updateQueryRows(ctx, r.db, psql.
+		Update("blobs b").
+		Set("is_deleting", true).
+		Where(sq.Expr("b.id IN (?)", sq.
+			Select("b.id").
+			From("blobs b").
+			Where(orphanedBlobsCondition(r.tp().UTC(), gracePeriod)).
+			OrderBy("b.id").
+			Limit(limit).
+			Suffix("FOR UPDATE SKIP LOCKED"))).
+		Where(noObjectsCondition).
+		Suffix("RETURNING b.checksum, b.size, b.mime_type"))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanBlobs(rows)
+}
+
+func (r *repository) DeleteBlobs(ctx context.Context, checksum ...string) error {
+	if len(checksum) == 0 {
+		return nil
+	}
+
+	return mapSQLErrors(indexChunks(len(checksum), expiredVersionsBatchSize, func(start, end int) error {
+		_, err := deleteQuery(ctx, r.db, psql.
+			Delete("blobs").
+			Where(sq.Eq{
+				"checksum":    checksum[start:end],
+				"is_deleting": true,
+			}))
+		return err
+	}))
+}
+
+var noObjectsCondition = sq.Expr("NOT EXISTS (SELECT 1 FROM objects o WHERE o.blob_id = b.id)")
+
+// orphanedBlobsCondition matches the blobs which are not referenced by any
+// object for at least grace period: since the moment the last object
+// referencing it was removed or since the creation for never used ones
+func orphanedBlobsCondition(now time.Time, gracePeriod time.Duration) sq.Sqlizer {
+	return sq.And{
+		noObjectsCondition,
+		sq.Expr("COALESCE(b.unreferenced_at, b.created_at) <= ?::timestamp", now.Add(-1*gracePeriod).Format(time.RFC3339)),
+	}
+}
+
+// markBlobsUnreferenced must be called before the objects matching the
+// condition are removed or remapped to start the orphaned blobs grace period
+// from the moment they lost the reference
+func markBlobsUnreferenced(ctx context.Context, db execRunner, now time.Time, objectsCondition sq.Sqlizer) error {
+	_, err := updateQuery(ctx, db, psql.
+		Update("blobs").
+		Set("unreferenced_at", now).
+		Where(sq.Expr("id IN (?)", sq.
+			Select("blob_id").
+			From("objects").
+			Where(objectsCondition))))
+	return err
+}
+
+// lockBlob returns the blob ID and confirmation state locking it against the
+// orphaned blobs removal till the end of the transaction, blobs being deleted
+// can't be referenced
+func lockBlob(ctx context.Context, db queryRunner, checksum string) (uint, bool, error) {
+	row, err := selectQueryRow(ctx, db, psql.
+		Select("id", "is_confirmed", "is_deleting").
+		From("blobs").
+		Where(sq.Eq{"checksum": checksum}).
+		Suffix("FOR SHARE"))
+	if err != nil {
+		return 0, false, err
+	}
+
+	var (
+		blobID      uint
+		isConfirmed bool
+		isDeleting  bool
+	)
+	if err := row.Scan(&blobID, &isConfirmed, &isDeleting); err != nil {
+		return 0, false, err
+	}
+
+	if isDeleting {
+		return 0, false, metadata.ErrDeleting
+	}
+	return blobID, isConfirmed, nil
+}
+
+func scanBlobs(rows *sql.Rows) ([]models.Blob, error) {
+	result := []models.Blob{}
+	for rows.Next() {
+		var b models.Blob
+		if err := rows.Scan(&b.Checksum, &b.Size, &b.MimeType); err != nil {
+			return nil, mapSQLErrors(err)
+		}
+
+		result = append(result, b)
+	}
+
+	return result, mapSQLErrors(rows.Err())
+}
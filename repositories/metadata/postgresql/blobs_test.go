@@ -1,6 +1,8 @@
 package postgresql
 
 import (
+	"time"
+
 	"github.com/teran/archived/models"
 	"github.com/teran/archived/repositories/metadata"
 )
@@ -26,6 +28,21 @@ func (s *postgreSQLRepositoryTestSuite) TestBlobs() {
 	err = s.repo.CreateBLOB(s.ctx, checksum, 15, "text/plain")
 	s.Require().NoError(err)
 
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, versionID, "test-object.txt", checksum)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotConfirmed, err)
+
+	err = s.repo.ConfirmBlob(s.ctx, checksum, 10)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrConflict, err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "not-existent", 15)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	err = s.repo.ConfirmBlob(s.ctx, checksum, 15)
+	s.Require().NoError(err)
+
 	err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, versionID, "test-object.txt", checksum)
 	s.Require().NoError(err)
 
@@ -39,9 +56,10 @@ func (s *postgreSQLRepositoryTestSuite) TestBlobs() {
 	blob, err := s.repo.GetBlobByObject(s.ctx, defaultNamespace, containerName, versionID, "test-object.txt")
 	s.Require().NoError(err)
 	s.Require().Equal(models.Blob{
-		Checksum: checksum,
-		Size:     15,
-		MimeType: "text/plain",
+		Checksum:    checksum,
+		Size:        15,
+		MimeType:    "text/plain",
+		IsConfirmed: true,
 	}, blob)
 }
 
@@ -125,6 +143,163 @@ func (s *postgreSQLRepositoryTestSuite) TestEnsureBlobKey() {
 	err = s.repo.CreateBLOB(s.ctx, "deadbeef", 1234, "application/octet-stream")
 	s.Require().NoError(err)
 
+	err = s.repo.EnsureBlobKey(s.ctx, "deadbeef", 1234)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotConfirmed, err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 1234)
+	s.Require().NoError(err)
+
 	err = s.repo.EnsureBlobKey(s.ctx, "deadbeef", 1234)
 	s.Require().NoError(err)
+
+	// broken blob has to be uploaded again
+	err = s.repo.MarkBlobsBroken(s.ctx, "deadbeef")
+	s.Require().NoError(err)
+
+	err = s.repo.EnsureBlobKey(s.ctx, "deadbeef", 1234)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotConfirmed, err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 1234)
+	s.Require().NoError(err)
+
+	err = s.repo.EnsureBlobKey(s.ctx, "deadbeef", 1234)
+	s.Require().NoError(err)
+}
+
+func (s *postgreSQLRepositoryTestSuite) TestOrphanedBlobs() {
+	const (
+		containerName = "test-container"
+		checksum1     = "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+		checksum2     = "cafebabecafebabecafebabecafebabecafebabecafebabecafebabecafebabe"
+		checksum3     = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	)
+
+	s.tp.On("Now").Return("2024-01-02T01:02:03Z").Times(5)
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, containerName, -1)
+	s.Require().NoError(err)
+
+	versionID, err := s.repo.CreateVersion(s.ctx, defaultNamespace, containerName)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateBLOB(s.ctx, checksum1, 15, "text/plain")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, checksum1, 15)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateBLOB(s.ctx, checksum2, 20, "application/json")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, checksum2, 20)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, versionID, "test-object.txt", checksum1)
+	s.Require().NoError(err)
+
+	s.tp.On("Now").Return("2024-01-02T10:02:03Z").Once()
+
+	err = s.repo.CreateBLOB(s.ctx, checksum3, 25, "text/plain")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, checksum3, 25)
+	s.Require().NoError(err)
+
+	s.tp.On("Now").Return("2024-01-02T12:02:03Z").Times(3)
+
+	blobs, err := s.repo.ListOrphanedBlobs(s.ctx, 5*time.Hour, 0, 100)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Blob{
+		{
+			Checksum: checksum2,
+			Size:     20,
+			MimeType: "application/json",
+		},
+	}, blobs)
+
+	blobs, err = s.repo.MarkOrphanedBlobsDeleting(s.ctx, 5*time.Hour, 100)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Blob{
+		{
+			Checksum: checksum2,
+			Size:     20,
+			MimeType: "application/json",
+		},
+	}, blobs)
+
+	// blob being deleted couldn't be uploaded or referenced again
+	err = s.repo.EnsureBlobKey(s.ctx, checksum2, 20)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrDeleting, err)
+
+	err = s.repo.ConfirmBlob(s.ctx, checksum2, 20)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrDeleting, err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, versionID, "another-object.txt", checksum2)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrDeleting, err)
+
+	_, err = s.repo.CreateObjects(s.ctx, defaultNamespace, containerName, versionID, []models.Object{
+		{Key: "another-object.txt", Checksum: checksum2, Size: 20, MimeType: "application/json"},
+	})
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrDeleting, err)
+
+	err = s.repo.DeleteBlobs(s.ctx, checksum2)
+	s.Require().NoError(err)
+
+	err = s.repo.EnsureBlobKey(s.ctx, checksum2, 20)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	// DeleteObject (unreferenced_at)
+	s.tp.On("Now").Return("2024-01-02T12:30:00Z").Once()
+
+	err = s.repo.DeleteObject(s.ctx, defaultNamespace, containerName, versionID, "test-object.txt")
+	s.Require().NoError(err)
+
+	// the grace period for the just unreferenced blob is counted since
+	// the object removal instead of the blob creation
+	s.tp.On("Now").Return("2024-01-02T16:00:00Z").Once()
+
+	blobs, err = s.repo.ListOrphanedBlobs(s.ctx, 5*time.Hour, 0, 100)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Blob{
+		{
+			Checksum: checksum3,
+			Size:     25,
+			MimeType: "text/plain",
+		},
+	}, blobs)
+
+	s.tp.On("Now").Return("2024-01-03T12:02:03Z").Once()
+
+	blobs, err = s.repo.MarkOrphanedBlobsDeleting(s.ctx, 5*time.Hour, 100)
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([]models.Blob{
+		{
+			Checksum: checksum1,
+			Size:     15,
+			MimeType: "text/plain",
+		},
+		{
+			Checksum: checksum3,
+			Size:     25,
+			MimeType: "text/plain",
+		},
+	}, blobs)
+
+	err = s.repo.DeleteBlobs(s.ctx, checksum1, checksum3)
+	s.Require().NoError(err)
+
+	err = s.repo.EnsureBlobKey(s.ctx, checksum1, 15)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	err = s.repo.EnsureBlobKey(s.ctx, checksum3, 25)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
 }
@@ -8,6 +8,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
 )
 
 func (r *repository) CreateContainer(ctx context.Context, namespace, name string, ttl time.Duration) error {
@@ -135,7 +136,7 @@ func (r *repository) RenameContainer(ctx context.Context, namespace, oldName, ne
 	return nil
 }
 
-func (r *repository) SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration) error {
+func (r *repository) SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return mapSQLErrors(err)
@@ -178,13 +179,23 @@ func (r *repository) SetContainerParameters(ctx context.Context, namespace, name
 		return mapSQLErrors(err)
 	}
 
-	_, err = updateQuery(ctx, tx, psql.
+	q := psql.
 		Update("containers").
 		Set("version_ttl_seconds", ttl.Seconds()).
 		Where(sq.Eq{
 			"name":         name,
 			"namespace_id": namespaceID,
-		}))
+		})
+
+	if retention != nil {
+		q = q.
+			Set("retention_keep_last", retention.KeepLast).
+			Set("retention_keep_daily", retention.KeepDaily).
+			Set("retention_keep_weekly", retention.KeepWeekly).
+			Set("retention_keep_monthly", retention.KeepMonthly)
+	}
+
+	_, err = updateQuery(ctx, tx, q)
 	if err != nil {
 		return mapSQLErrors(err)
 	}
@@ -210,7 +221,15 @@ func (r *repository) ListContainers(ctx context.Context, namespace string) ([]mo
 	}
 
 	rows, err := selectQuery(ctx, r.db, psql.
-		Select("name", "created_at", "version_ttl_seconds").
+		Select(
+			"name",
+			"created_at",
+			"version_ttl_seconds",
+			"retention_keep_last",
+			"retention_keep_daily",
+			"retention_keep_weekly",
+			"retention_keep_monthly",
+		).
 		From("containers").
 		Where(sq.Eq{
 			"namespace_id": namespaceID,
@@ -229,7 +248,11 @@ func (r *repository) ListContainers(ctx context.Context, namespace string) ([]mo
 			ttl       int64
 		)
 
-		if err := rows.Scan(&r.Name, &createdAt, &ttl); err != nil {
+		if err := rows.Scan(
+			&r.Name, &createdAt, &ttl,
+			&r.RetentionPolicy.KeepLast, &r.RetentionPolicy.KeepDaily,
+			&r.RetentionPolicy.KeepWeekly, &r.RetentionPolicy.KeepMonthly,
+		); err != nil {
 			return nil, mapSQLErrors(err)
 		}
 
@@ -285,6 +308,10 @@ func (r *repository) ListContainersByPage(ctx context.Context, namespace string,
 			"name",
 			"version_ttl_seconds",
 			"created_at",
+			"retention_keep_last",
+			"retention_keep_daily",
+			"retention_keep_weekly",
+			"retention_keep_monthly",
 		).
 		From("containers").
 		Where(sq.Eq{
@@ -305,7 +332,11 @@ func (r *repository) ListContainersByPage(ctx context.Context, namespace string,
 			createdAt time.Time
 			ttl       int64
 		)
-		if err := rows.Scan(&r.Name, &ttl, &createdAt); err != nil {
+		if err := rows.Scan(
+			&r.Name, &ttl, &createdAt,
+			&r.RetentionPolicy.KeepLast, &r.RetentionPolicy.KeepDaily,
+			&r.RetentionPolicy.KeepWeekly, &r.RetentionPolicy.KeepMonthly,
+		); err != nil {
 			return 0, nil, mapSQLErrors(err)
 		}
 
@@ -354,7 +385,40 @@ func (r *repository) DeleteContainer(ctx context.Context, namespace, name string
 		return mapSQLErrors(err)
 	}
 
-	_, err = deleteQuery(ctx, r.db, psql.
+	// versions are locked to prevent them from being protected
+	// until the container is deleted
+	rows, err := selectQuery(ctx, tx, psql.
+		Select("v.is_protected").
+		From("versions v").
+		Join("containers c ON v.container_id = c.id").
+		Where(sq.Eq{
+			"c.namespace_id": namespaceID,
+			"c.name":         name,
+		}).
+		Suffix("FOR UPDATE OF v"))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var isProtected bool
+	for rows.Next() {
+		var p bool
+		if err := rows.Scan(&p); err != nil {
+			return mapSQLErrors(err)
+		}
+		isProtected = isProtected || p
+	}
+
+	if err := rows.Err(); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if isProtected {
+		return metadata.ErrProtected
+	}
+
+	_, err = deleteQuery(ctx, tx, psql.
 		Delete("containers").
 		Where(sq.Eq{
 			"name":         name,
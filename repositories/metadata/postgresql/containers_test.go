@@ -27,17 +27,32 @@ func (s *postgreSQLRepositoryTestSuite) TestContainerOperations() {
 	s.Require().Error(err)
 	s.Require().Equal(metadata.ErrConflict, err)
 
-	err = s.repo.SetContainerParameters(s.ctx, defaultNamespace, "test-container5", 1*time.Hour)
+	err = s.repo.SetContainerParameters(s.ctx, defaultNamespace, "test-container5", 1*time.Hour, &models.RetentionPolicy{})
 	s.Require().NoError(err)
 
-	err = s.repo.SetContainerParameters(s.ctx, defaultNamespace, "test-container9", 2*time.Hour)
+	err = s.repo.SetContainerParameters(s.ctx, defaultNamespace, "test-container9", 2*time.Hour, &models.RetentionPolicy{
+		KeepLast:    10,
+		KeepMonthly: 12,
+	})
+	s.Require().NoError(err)
+
+	// retention policy is kept as is if not passed
+	err = s.repo.SetContainerParameters(s.ctx, defaultNamespace, "test-container9", 2*time.Hour, nil)
 	s.Require().NoError(err)
 
 	list, err = s.repo.ListContainers(s.ctx, defaultNamespace)
 	s.Require().NoError(err)
 	s.Require().Equal([]models.Container{
 		{Name: "test-container5", CreatedAt: time.Date(2024, 1, 2, 1, 2, 3, 0, time.UTC), VersionsTTL: 3600 * time.Second},
-		{Name: "test-container9", CreatedAt: time.Date(2024, 1, 2, 1, 2, 3, 0, time.UTC), VersionsTTL: 7200 * time.Second},
+		{
+			Name:        "test-container9",
+			CreatedAt:   time.Date(2024, 1, 2, 1, 2, 3, 0, time.UTC),
+			VersionsTTL: 7200 * time.Second,
+			RetentionPolicy: models.RetentionPolicy{
+				KeepLast:    10,
+				KeepMonthly: 12,
+			},
+		},
 	}, list)
 
 	err = s.repo.DeleteContainer(s.ctx, defaultNamespace, "test-container9")
@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/teran/archived/models"
+)
+
+func (r *repository) DiffVersions(ctx context.Context, namespace, container, fromVersion, toVersion string) (models.VersionDiff, error) {
+	fromVersionID, err := getVersionID(ctx, r.db, namespace, container, fromVersion)
+	if err != nil {
+		return models.VersionDiff{}, err
+	}
+
+	toVersionID, err := getVersionID(ctx, r.db, namespace, container, toVersion)
+	if err != nil {
+		return models.VersionDiff{}, err
+	}
+
+	// Both versions are joined by object key so only the keys which are
+	// missing on one of the sides or point to different blobs are returned
+	rows, err := selectQuery(ctx, r.db, psql.
+		Select(
+			"ok.key",
+			"fb.checksum",
+			"fb.size",
+			"fb.mime_type",
+			"tb.checksum",
+			"tb.size",
+			"tb.mime_type",
+		).
+		Prefix(
+			"WITH f AS (SELECT key_id, blob_id FROM objects WHERE version_id = ?), "+
+				"t AS (SELECT key_id, blob_id FROM objects WHERE version_id = ?)",
+			fromVersionID, toVersionID,
+		).
+		From("f").
+		JoinClause("FULL OUTER JOIN t ON t.key_id = f.key_id").
+		Join("object_keys ok ON ok.id = COALESCE(f.key_id, t.key_id)").
+		LeftJoin("blobs fb ON fb.id = f.blob_id").
+		LeftJoin("blobs tb ON tb.id = t.blob_id").
+		Where(sq.Expr("f.blob_id IS DISTINCT FROM t.blob_id")).
+		OrderBy("ok.key"))
+	if err != nil {
+		return models.VersionDiff{}, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	diff := models.VersionDiff{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Added:       []models.Object{},
+		Removed:     []models.Object{},
+		Changed:     []models.ObjectChange{},
+	}
+	for rows.Next() {
+		var (
+			key                        string
+			fromChecksum, fromMimeType sql.NullString
+			toChecksum, toMimeType     sql.NullString
+			fromSize, toSize           sql.NullInt64
+		)
+		if err := rows.Scan(&key, &fromChecksum, &fromSize, &fromMimeType, &toChecksum, &toSize, &toMimeType); err != nil {
+			return models.VersionDiff{}, mapSQLErrors(err)
+		}
+
+		switch {
+		case !fromChecksum.Valid:
+			diff.Added = append(diff.Added, models.Object{
+				Key:      key,
+				Checksum: toChecksum.String,
+				Size:     uint64(toSize.Int64),
+				MimeType: toMimeType.String,
+			})
+			diff.AddedBytes += uint64(toSize.Int64)
+		case !toChecksum.Valid:
+			diff.Removed = append(diff.Removed, models.Object{
+				Key:      key,
+				Checksum: fromChecksum.String,
+				Size:     uint64(fromSize.Int64),
+				MimeType: fromMimeType.String,
+			})
+			diff.RemovedBytes += uint64(fromSize.Int64)
+		default:
+			diff.Changed = append(diff.Changed, models.ObjectChange{
+				Key:         key,
+				OldChecksum: fromChecksum.String,
+				OldSize:     uint64(fromSize.Int64),
+				NewChecksum: toChecksum.String,
+				NewSize:     uint64(toSize.Int64),
+			})
+			diff.ChangedOldBytes += uint64(fromSize.Int64)
+			diff.ChangedNewBytes += uint64(toSize.Int64)
+		}
+	}
+
+	return diff, mapSQLErrors(rows.Err())
+}
+
+func getVersionID(ctx context.Context, db queryRunner, namespace, container, version string) (uint64, error) {
+	row, err := selectQueryRow(ctx, db, psql.
+		Select("v.id").
+		From("versions v").
+		Join("containers c ON v.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": namespace,
+			"c.name": container,
+			"v.name": version,
+		}))
+	if err != nil {
+		return 0, mapSQLErrors(err)
+	}
+
+	var versionID uint64
+	if err := row.Scan(&versionID); err != nil {
+		return 0, mapSQLErrors(err)
+	}
+	return versionID, nil
+}
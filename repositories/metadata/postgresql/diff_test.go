@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+func (s *postgreSQLRepositoryTestSuite) TestDiffVersions() {
+	const containerName = "test-container-1"
+
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Times(8)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Times(5)
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, containerName, -1)
+	s.Require().NoError(err)
+
+	version1ID, err := s.repo.CreateVersion(s.ctx, defaultNamespace, containerName)
+	s.Require().NoError(err)
+
+	for checksum, size := range map[string]uint64{"aaaa": 10, "bbbb": 20, "cccc": 30} {
+		err = s.repo.CreateBLOB(s.ctx, checksum, size, "text/plain")
+		s.Require().NoError(err)
+
+		err = s.repo.ConfirmBlob(s.ctx, checksum, size)
+		s.Require().NoError(err)
+	}
+
+	for key, checksum := range map[string]string{"key1": "aaaa", "key2": "bbbb", "key3": "aaaa"} {
+		err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, version1ID, key, checksum)
+		s.Require().NoError(err)
+	}
+
+	version2ID, err := s.repo.CreateVersion(s.ctx, defaultNamespace, containerName)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateBLOB(s.ctx, "dddd", 40, "application/json")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "dddd", 40)
+	s.Require().NoError(err)
+
+	for key, checksum := range map[string]string{"key1": "aaaa", "key2": "dddd", "key4": "cccc"} {
+		err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, version2ID, key, checksum)
+		s.Require().NoError(err)
+	}
+
+	diff, err := s.repo.DiffVersions(s.ctx, defaultNamespace, containerName, version1ID, version2ID)
+	s.Require().NoError(err)
+	s.Require().Equal(models.VersionDiff{
+		FromVersion: version1ID,
+		ToVersion:   version2ID,
+		Added: []models.Object{
+			{Key: "key4", Checksum: "cccc", Size: 30, MimeType: "text/plain"},
+		},
+		Removed: []models.Object{
+			{Key: "key3", Checksum: "aaaa", Size: 10, MimeType: "text/plain"},
+		},
+		Changed: []models.ObjectChange{
+			{Key: "key2", OldChecksum: "bbbb", OldSize: 20, NewChecksum: "dddd", NewSize: 40},
+		},
+		AddedBytes:      30,
+		RemovedBytes:    10,
+		ChangedOldBytes: 20,
+		ChangedNewBytes: 40,
+	}, diff)
+
+	diff, err = s.repo.DiffVersions(s.ctx, defaultNamespace, containerName, version1ID, version1ID)
+	s.Require().NoError(err)
+	s.Require().Empty(diff.Added)
+	s.Require().Empty(diff.Removed)
+	s.Require().Empty(diff.Changed)
+
+	_, err = s.repo.DiffVersions(s.ctx, defaultNamespace, containerName, version1ID, "20000101000000")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+}
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE containers
+    DROP COLUMN retention_keep_last,
+    DROP COLUMN retention_keep_daily,
+    DROP COLUMN retention_keep_weekly,
+    DROP COLUMN retention_keep_monthly;
+
+COMMIT;
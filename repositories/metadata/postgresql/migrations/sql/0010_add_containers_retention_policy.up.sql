@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE containers
+    ADD COLUMN retention_keep_last INT NOT NULL DEFAULT 0,
+    ADD COLUMN retention_keep_daily INT NOT NULL DEFAULT 0,
+    ADD COLUMN retention_keep_weekly INT NOT NULL DEFAULT 0,
+    ADD COLUMN retention_keep_monthly INT NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE versions
+    DROP COLUMN is_protected;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE versions
+    ADD COLUMN is_protected BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
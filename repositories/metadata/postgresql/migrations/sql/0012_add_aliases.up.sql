@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE aliases (
+    id SERIAL PRIMARY KEY,
+    container_id INT NOT NULL,
+    version_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE aliases ADD FOREIGN KEY (container_id) REFERENCES containers (id) ON DELETE CASCADE;
+ALTER TABLE aliases ADD FOREIGN KEY (version_id) REFERENCES versions (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX aliases_container_id_name_key ON aliases (container_id, name);
+CREATE INDEX aliases_version_id_idx ON aliases (version_id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE namespaces
+    DROP COLUMN is_private;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE namespaces
+    ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
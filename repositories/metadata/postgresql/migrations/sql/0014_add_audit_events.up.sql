@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    operation VARCHAR(255) NOT NULL,
+    namespace VARCHAR(255) NOT NULL,
+    parameters JSONB NOT NULL,
+    result VARCHAR(32) NOT NULL,
+    error TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor);
+CREATE INDEX audit_events_namespace_idx ON audit_events (namespace);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- blobs uploaded before the column was introduced are considered confirmed
+ALTER TABLE blobs ADD COLUMN is_confirmed BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE blobs ALTER COLUMN is_confirmed SET DEFAULT false;
+
+COMMIT;
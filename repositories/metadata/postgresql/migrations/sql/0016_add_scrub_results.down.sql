@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE scrub_cursor;
+DROP TABLE scrub_results;
+
+COMMIT;
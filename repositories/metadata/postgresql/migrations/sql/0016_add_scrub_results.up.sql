@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE scrub_results (
+    id BIGSERIAL PRIMARY KEY,
+    checksum CHAR(64) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    expected_size BIGINT NOT NULL,
+    actual_size BIGINT NOT NULL,
+    actual_checksum VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX scrub_results_checksum_idx ON scrub_results (checksum);
+
+-- single row table to keep scrubber progress between runs
+CREATE TABLE scrub_cursor (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    checksum CHAR(64) NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE blobs DROP COLUMN is_broken;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE blobs DROP COLUMN unreferenced_at;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE blobs ADD COLUMN unreferenced_at TIMESTAMP;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE blobs ADD COLUMN is_deleting BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE aliases DROP CONSTRAINT aliases_version_id_fkey;
+ALTER TABLE aliases ADD FOREIGN KEY (version_id) REFERENCES versions (id) ON DELETE RESTRICT;
+
+COMMIT;
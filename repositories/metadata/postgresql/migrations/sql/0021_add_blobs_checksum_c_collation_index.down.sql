@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX blobs_checksum_c_idx;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX blobs_checksum_c_idx ON blobs (checksum COLLATE "C");
+
+COMMIT;
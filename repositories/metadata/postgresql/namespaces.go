@@ -5,6 +5,8 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/repositories/metadata"
 )
 
 func (r *repository) CreateNamespace(ctx context.Context, name string) error {
@@ -88,9 +90,106 @@ func (r *repository) ListNamespaces(ctx context.Context) ([]string, error) {
 	return result, mapSQLErrors(rows.Err())
 }
 
+func (r *repository) ListPrivateNamespaces(ctx context.Context) ([]string, error) {
+	rows, err := selectQuery(ctx, r.db, psql.
+		Select("name").
+		From("namespaces").
+		Where(sq.Eq{"is_private": true}).
+		OrderBy("name"))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := []string{}
+	for rows.Next() {
+		var r string
+		if err := rows.Scan(&r); err != nil {
+			return nil, mapSQLErrors(err)
+		}
+
+		result = append(result, r)
+	}
+
+	return result, mapSQLErrors(rows.Err())
+}
+
+func (r *repository) SetNamespacePrivate(ctx context.Context, name string, isPrivate bool) error {
+	res, err := updateQuery(ctx, r.db, psql.
+		Update("namespaces").
+		Set("is_private", isPrivate).
+		Where(sq.Eq{"name": name}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if n == 0 {
+		return metadata.ErrNotFound
+	}
+	return nil
+}
+
 func (r *repository) DeleteNamespace(ctx context.Context, name string) error {
-	_, err := deleteQuery(ctx, r.db, psql.
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("error rolling back")
+		}
+	}()
+
+	// versions are locked to prevent them from being protected
+	// until the namespace is deleted
+	rows, err := selectQuery(ctx, tx, psql.
+		Select("v.is_protected").
+		From("versions v").
+		Join("containers c ON v.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": name,
+		}).
+		Suffix("FOR UPDATE OF v"))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var isProtected bool
+	for rows.Next() {
+		var p bool
+		if err := rows.Scan(&p); err != nil {
+			return mapSQLErrors(err)
+		}
+		isProtected = isProtected || p
+	}
+
+	if err := rows.Err(); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if isProtected {
+		return metadata.ErrProtected
+	}
+
+	_, err = deleteQuery(ctx, tx, psql.
 		Delete("namespaces").
 		Where(sq.Eq{"name": name}))
-	return mapSQLErrors(err)
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapSQLErrors(err)
+	}
+	return nil
 }
@@ -54,3 +54,35 @@ func (s *postgreSQLRepositoryTestSuite) TestNamespaceOperations() {
 		"default",
 	}, list)
 }
+
+func (s *postgreSQLRepositoryTestSuite) TestPrivateNamespaces() {
+	s.tp.On("Now").Return("2024-01-02T01:02:03Z").Twice()
+
+	err := s.repo.CreateNamespace(s.ctx, "private-namespace")
+	s.Require().NoError(err)
+
+	err = s.repo.CreateNamespace(s.ctx, "public-namespace")
+	s.Require().NoError(err)
+
+	list, err := s.repo.ListPrivateNamespaces(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{}, list)
+
+	err = s.repo.SetNamespacePrivate(s.ctx, "private-namespace", true)
+	s.Require().NoError(err)
+
+	err = s.repo.SetNamespacePrivate(s.ctx, "not-existent", true)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	list, err = s.repo.ListPrivateNamespaces(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"private-namespace"}, list)
+
+	err = s.repo.SetNamespacePrivate(s.ctx, "private-namespace", false)
+	s.Require().NoError(err)
+
+	list, err = s.repo.ListPrivateNamespaces(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{}, list)
+}
@@ -2,11 +2,19 @@ package postgresql
 
 import (
 	"context"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
 )
 
+// createObjectsBatchSize keeps the amount of the arguments per query
+// far below the lib/pq limit of 65k
+const createObjectsBatchSize = 1000
+
 func (r *repository) CreateObject(ctx context.Context, namespace, container, version, key, casKey string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -53,17 +61,13 @@ func (r *repository) CreateObject(ctx context.Context, namespace, container, ver
 		return mapSQLErrors(err)
 	}
 
-	row, err = selectQueryRow(ctx, tx, psql.
-		Select("id").
-		From("blobs").
-		Where(sq.Eq{"checksum": casKey}))
+	blobID, isConfirmed, err := lockBlob(ctx, tx, casKey)
 	if err != nil {
 		return mapSQLErrors(err)
 	}
 
-	var blobID uint
-	if err := row.Scan(&blobID); err != nil {
-		return mapSQLErrors(err)
+	if !isConfirmed {
+		return metadata.ErrNotConfirmed
 	}
 
 	objectTimestamp := r.tp().UTC()
@@ -115,7 +119,197 @@ func (r *repository) CreateObject(ctx context.Context, namespace, container, ver
 	return nil
 }
 
-func (r *repository) ListObjects(ctx context.Context, namespace, container, version string, offset, limit uint64) (uint64, []string, error) {
+func (r *repository) CreateObjects(ctx context.Context, namespace, container, version string, objects []models.Object) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("error rolling back")
+		}
+	}()
+
+	row, err := selectQueryRow(ctx, tx, psql.
+		Select("v.id").
+		From("versions v").
+		Join("containers c ON v.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name":         namespace,
+			"c.name":         container,
+			"v.name":         version,
+			"v.is_published": false,
+		}))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	var versionID uint
+	if err := row.Scan(&versionID); err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	// Multiple entries of the same key or blob within the single INSERT
+	// statement are not allowed by ON CONFLICT DO UPDATE so deduplicate
+	// them first. The first entry wins for the key as it would with
+	// sequential CreateObject calls.
+	keyIdx := map[string]int{}
+	keys := []string{}
+	blobs := []models.Object{}
+	blobSeen := map[string]struct{}{}
+	for idx, o := range objects {
+		if _, ok := keyIdx[o.Key]; !ok {
+			keyIdx[o.Key] = idx
+			keys = append(keys, o.Key)
+		}
+
+		if _, ok := blobSeen[o.Checksum]; !ok {
+			blobSeen[o.Checksum] = struct{}{}
+			blobs = append(blobs, o)
+		}
+	}
+
+	objectTimestamp := r.tp().UTC()
+
+	pendingBlobs := []string{}
+	blobIDs := map[string]uint{}
+	if err := indexChunks(len(blobs), createObjectsBatchSize, func(start, end int) error {
+		q := psql.
+			Insert("blobs").
+			Columns(
+				"checksum",
+				"size",
+				"mime_type",
+				"is_confirmed",
+				"created_at",
+			).
+			Suffix("ON CONFLICT (checksum) DO NOTHING")
+		checksums := []string{}
+		for _, b := range blobs[start:end] {
+			q = q.Values(b.Checksum, b.Size, b.MimeType, false, objectTimestamp)
+			checksums = append(checksums, b.Checksum)
+		}
+
+		if _, err := insertQuery(ctx, tx, q); err != nil {
+			return err
+		}
+
+		// the blobs are locked against the orphaned blobs removal
+		rows, err := selectQuery(ctx, tx, psql.
+			Select("id", "checksum", "size", "is_confirmed", "is_broken", "is_deleting").
+			From("blobs").
+			Where(sq.Eq{"checksum": checksums}).
+			Suffix("FOR SHARE"))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+
+		sizes := map[string]uint64{}
+		for rows.Next() {
+			var (
+				id          uint
+				checksum    string
+				size        uint64
+				isConfirmed bool
+				isBroken    bool
+				isDeleting  bool
+			)
+			if err := rows.Scan(&id, &checksum, &size, &isConfirmed, &isBroken, &isDeleting); err != nil {
+				return err
+			}
+			if isDeleting {
+				return metadata.ErrDeleting
+			}
+			blobIDs[checksum] = id
+			sizes[checksum] = size
+			// broken BLOB data is lost so it has to be uploaded again
+			// the same way EnsureBlobKey treats it
+			if !isConfirmed || isBroken {
+				pendingBlobs = append(pendingBlobs, checksum)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// Existing blob with the same checksum but different size is the
+		// same kind of error as the one CreateBLOB returns
+		for _, b := range blobs[start:end] {
+			if sizes[b.Checksum] != b.Size {
+				return metadata.ErrConflict
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	keyIDs := map[string]uint{}
+	if err := indexChunks(len(keys), createObjectsBatchSize, func(start, end int) error {
+		q := psql.
+			Insert("object_keys").
+			Columns(
+				"key",
+				"created_at",
+			).
+			Suffix("ON CONFLICT (key) DO UPDATE SET key=excluded.key RETURNING id, key")
+		for _, key := range keys[start:end] {
+			q = q.Values(key, objectTimestamp)
+		}
+
+		rows, err := insertQueryRows(ctx, tx, q)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+
+		for rows.Next() {
+			var (
+				id  uint
+				key string
+			)
+			if err := rows.Scan(&id, &key); err != nil {
+				return err
+			}
+			keyIDs[key] = id
+		}
+		return rows.Err()
+	}); err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	if err := indexChunks(len(keys), createObjectsBatchSize, func(start, end int) error {
+		q := psql.
+			Insert("objects").
+			Columns(
+				"version_id",
+				"key_id",
+				"blob_id",
+				"created_at",
+			).
+			Suffix("ON CONFLICT (version_id, key_id) DO NOTHING")
+		for _, key := range keys[start:end] {
+			q = q.Values(versionID, keyIDs[key], blobIDs[objects[keyIdx[key]].Checksum], objectTimestamp)
+		}
+
+		_, err := insertQuery(ctx, tx, q)
+		return err
+	}); err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	return pendingBlobs, nil
+}
+
+func (r *repository) ListObjects(ctx context.Context, namespace, container, version string, offset, limit uint64) (uint64, []models.Object, error) {
 	row, err := selectQueryRow(ctx, r.db, psql.
 		Select("id").
 		From("namespaces").
@@ -175,11 +369,18 @@ func (r *repository) ListObjects(ctx context.Context, namespace, container, vers
 	}
 
 	rows, err := selectQuery(ctx, r.db, psql.
-		Select("ok.key").
+		Select(
+			"ok.key AS key",
+			"b.checksum AS checksum",
+			"b.size AS size",
+			"b.mime_type AS mime_type",
+			"o.created_at AS created_at",
+		).
 		From("object_keys ok").
 		Join("objects o ON ok.id = o.key_id").
+		Join("blobs b ON b.id = o.blob_id").
 		Where(sq.Eq{
-			"version_id": versionID,
+			"o.version_id": versionID,
 		}).
 		OrderBy("ok.key").
 		Offset(offset).
@@ -189,14 +390,22 @@ func (r *repository) ListObjects(ctx context.Context, namespace, container, vers
 	}
 	defer func() { _ = rows.Close() }()
 
-	result := []string{}
+	result := []models.Object{}
 	for rows.Next() {
-		var r string
-		if err := rows.Scan(&r); err != nil {
+		var (
+			o         models.Object
+			createdAt time.Time
+		)
+		if err := rows.Scan(&o.Key, &o.Checksum, &o.Size, &o.MimeType, &createdAt); err != nil {
 			return 0, nil, mapSQLErrors(err)
 		}
+		o.CreatedAt = time.Date(
+			createdAt.Year(), createdAt.Month(), createdAt.Day(),
+			createdAt.Hour(), createdAt.Minute(), createdAt.Second(), createdAt.Nanosecond(),
+			time.UTC,
+		)
 
-		result = append(result, r)
+		result = append(result, o)
 	}
 
 	return objectsTotal, result, mapSQLErrors(rows.Err())
@@ -261,12 +470,18 @@ func (r *repository) DeleteObject(ctx context.Context, namespace, container, ver
 		return mapSQLErrors(err)
 	}
 
+	objectCondition := sq.Eq{
+		"version_id": versionID,
+		"key_id":     okID,
+	}
+
+	if err := markBlobsUnreferenced(ctx, tx, r.tp().UTC(), objectCondition); err != nil {
+		return mapSQLErrors(err)
+	}
+
 	_, err = deleteQuery(ctx, tx, psql.
 		Delete("objects").
-		Where(sq.Eq{
-			"version_id": versionID,
-			"key_id":     okID,
-		}))
+		Where(objectCondition))
 	if err != nil {
 		return mapSQLErrors(err)
 	}
@@ -322,19 +537,11 @@ func (r *repository) RemapObject(ctx context.Context, namespace, container, vers
 		return mapSQLErrors(err)
 	}
 
-	row, err = selectQueryRow(ctx, tx, psql.
-		Select("id").
-		From("blobs").
-		Where(sq.Eq{"checksum": newCASKey}))
+	blobID, _, err := lockBlob(ctx, tx, newCASKey)
 	if err != nil {
 		return mapSQLErrors(err)
 	}
 
-	var blobID uint
-	if err := row.Scan(&blobID); err != nil {
-		return mapSQLErrors(err)
-	}
-
 	row, err = selectQueryRow(ctx, tx, psql.
 		Select("id").
 		From("object_keys").
@@ -350,13 +557,19 @@ func (r *repository) RemapObject(ctx context.Context, namespace, container, vers
 		return mapSQLErrors(err)
 	}
 
+	objectCondition := sq.Eq{
+		"version_id": versionID,
+		"key_id":     okID,
+	}
+
+	if err := markBlobsUnreferenced(ctx, tx, r.tp().UTC(), objectCondition); err != nil {
+		return mapSQLErrors(err)
+	}
+
 	_, err = updateQuery(ctx, tx, psql.
 		Update("objects").
 		Set("blob_id", blobID).
-		Where(sq.Eq{
-			"version_id": versionID,
-			"key_id":     okID,
-		}))
+		Where(objectCondition))
 	if err != nil {
 		return mapSQLErrors(err)
 	}
@@ -1,12 +1,17 @@
 package postgresql
 
-import "github.com/teran/archived/repositories/metadata"
+import (
+	"time"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
+)
 
 func (s *postgreSQLRepositoryTestSuite) TestObjects() {
 	const containerName = "test-container-1"
 
 	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Times(4)
-	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Times(4)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Times(6)
 	// s.tp.On("Now").Return("2024-07-07T10:11:14Z").Times(4)
 
 	err := s.repo.CreateContainer(s.ctx, defaultNamespace, containerName, -1)
@@ -18,17 +23,31 @@ func (s *postgreSQLRepositoryTestSuite) TestObjects() {
 	err = s.repo.CreateBLOB(s.ctx, "deadbeef", 10, "text/plain")
 	s.Require().NoError(err)
 
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 10)
+	s.Require().NoError(err)
+
 	err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, versionID, "data/some-key.txt", "deadbeef")
 	s.Require().NoError(err)
 
 	total, objects, err := s.repo.ListObjects(s.ctx, defaultNamespace, containerName, versionID, 0, 100)
 	s.Require().NoError(err)
-	s.Require().Equal([]string{"data/some-key.txt"}, objects)
+	s.Require().Equal([]models.Object{
+		{
+			Key:       "data/some-key.txt",
+			Checksum:  "deadbeef",
+			Size:      10,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 7, 7, 10, 11, 12, 0, time.UTC),
+		},
+	}, objects)
 	s.Require().Equal(uint64(1), total)
 
 	err = s.repo.CreateBLOB(s.ctx, "deadbeef2", 10, "text/plain")
 	s.Require().NoError(err)
 
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef2", 10)
+	s.Require().NoError(err)
+
 	err = s.repo.RemapObject(s.ctx, defaultNamespace, containerName, versionID, "data/some-key.txt", "deadbeef2")
 	s.Require().NoError(err)
 
@@ -37,7 +56,7 @@ func (s *postgreSQLRepositoryTestSuite) TestObjects() {
 
 	total, objects, err = s.repo.ListObjects(s.ctx, defaultNamespace, containerName, versionID, 0, 100)
 	s.Require().NoError(err)
-	s.Require().Equal([]string{}, objects)
+	s.Require().Equal([]models.Object{}, objects)
 	s.Require().Equal(uint64(0), total)
 
 	version2ID, err := s.repo.CreateVersion(s.ctx, defaultNamespace, containerName)
@@ -51,10 +70,123 @@ func (s *postgreSQLRepositoryTestSuite) TestObjects() {
 
 	total, objects, err = s.repo.ListObjects(s.ctx, defaultNamespace, containerName, version2ID, 0, 100)
 	s.Require().NoError(err)
-	s.Require().Equal([]string{"data/some-key2.txt", "data/some-key3.txt"}, objects)
+	s.Require().Len(objects, 2)
+	s.Require().Equal("data/some-key2.txt", objects[0].Key)
+	s.Require().Equal("data/some-key3.txt", objects[1].Key)
+	s.Require().Equal("deadbeef", objects[1].Checksum)
 	s.Require().Equal(uint64(2), total)
 }
 
+func (s *postgreSQLRepositoryTestSuite) TestCreateObjects() {
+	const containerName = "test-container-1"
+
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Times(4)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Times(4)
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, containerName, -1)
+	s.Require().NoError(err)
+
+	versionID, err := s.repo.CreateVersion(s.ctx, defaultNamespace, containerName)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateBLOB(s.ctx, "deadbeef", 10, "text/plain")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 10)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, versionID, "data/key1.txt", "deadbeef")
+	s.Require().NoError(err)
+
+	created, err := s.repo.CreateObjects(s.ctx, defaultNamespace, containerName, versionID, []models.Object{
+		{Key: "data/key1.txt", Checksum: "deadbeef", Size: 10, MimeType: "text/plain"},
+		{Key: "data/key2.txt", Checksum: "cafebabe", Size: 20, MimeType: "application/json"},
+		{Key: "data/key3.txt", Checksum: "cafebabe", Size: 20, MimeType: "application/json"},
+		{Key: "data/key4.txt", Checksum: "deadbeef", Size: 10, MimeType: "text/plain"},
+		// the first entry wins for the duplicate key
+		{Key: "data/key4.txt", Checksum: "cafebabe", Size: 20, MimeType: "application/json"},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"cafebabe"}, created)
+
+	total, objects, err := s.repo.ListObjects(s.ctx, defaultNamespace, containerName, versionID, 0, 100)
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(4), total)
+	s.Require().Equal([]models.Object{
+		{
+			Key:       "data/key1.txt",
+			Checksum:  "deadbeef",
+			Size:      10,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 7, 7, 10, 11, 12, 0, time.UTC),
+		},
+		{
+			Key:       "data/key2.txt",
+			Checksum:  "cafebabe",
+			Size:      20,
+			MimeType:  "application/json",
+			CreatedAt: time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC),
+		},
+		{
+			Key:       "data/key3.txt",
+			Checksum:  "cafebabe",
+			Size:      20,
+			MimeType:  "application/json",
+			CreatedAt: time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC),
+		},
+		{
+			Key:       "data/key4.txt",
+			Checksum:  "deadbeef",
+			Size:      10,
+			MimeType:  "text/plain",
+			CreatedAt: time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC),
+		},
+	}, objects)
+
+	// The same checksum with different size
+	_, err = s.repo.CreateObjects(s.ctx, defaultNamespace, containerName, versionID, []models.Object{
+		{Key: "data/key5.txt", Checksum: "deadbeef", Size: 11, MimeType: "text/plain"},
+	})
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrConflict, err)
+
+	// Pending blob is reported again until confirmed
+	pending, err := s.repo.CreateObjects(s.ctx, defaultNamespace, containerName, versionID, []models.Object{
+		{Key: "data/key2.txt", Checksum: "cafebabe", Size: 20, MimeType: "application/json"},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"cafebabe"}, pending)
+
+	err = s.repo.MarkVersionPublished(s.ctx, defaultNamespace, containerName, versionID)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotConfirmed, err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "cafebabe", 20)
+	s.Require().NoError(err)
+
+	// Broken blob has to be uploaded again
+	err = s.repo.MarkBlobsBroken(s.ctx, "cafebabe")
+	s.Require().NoError(err)
+
+	pending, err = s.repo.CreateObjects(s.ctx, defaultNamespace, containerName, versionID, []models.Object{
+		{Key: "data/key2.txt", Checksum: "cafebabe", Size: 20, MimeType: "application/json"},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"cafebabe"}, pending)
+
+	err = s.repo.ConfirmBlob(s.ctx, "cafebabe", 20)
+	s.Require().NoError(err)
+
+	err = s.repo.MarkVersionPublished(s.ctx, defaultNamespace, containerName, versionID)
+	s.Require().NoError(err)
+
+	_, err = s.repo.CreateObjects(s.ctx, defaultNamespace, containerName, versionID, []models.Object{
+		{Key: "data/key5.txt", Checksum: "deadbeef", Size: 10, MimeType: "text/plain"},
+	})
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+}
+
 func (s *postgreSQLRepositoryTestSuite) TestListObjectsErrors() {
 	s.tp.On("Now").Return("2024-01-02T01:02:03Z").Once()
 
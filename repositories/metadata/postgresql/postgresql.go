@@ -170,6 +170,29 @@ func insertQueryRow(ctx context.Context, db queryRunner, q sq.InsertBuilder) (sq
 	return db.QueryRowContext(ctx, sql, args...), nil
 }
 
+func insertQueryRows(ctx context.Context, db queryRunner, q sq.InsertBuilder) (*sql.Rows, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		since := time.Since(start)
+
+		queryCountTotal.WithLabelValues("insert").Inc()
+		queryTimeTotal.WithLabelValues("insert").Add(since.Seconds())
+
+		log.WithFields(log.Fields{
+			"query":    sql,
+			"args":     args,
+			"duration": since,
+		}).Debug("SQL query executed")
+	}()
+
+	return db.QueryContext(ctx, sql, args...) //nolint:sqlclosecheck
+}
+
 func updateQuery(ctx context.Context, db execRunner, q sq.UpdateBuilder) (sql.Result, error) { //nolint:unparam
 	sql, args, err := q.ToSql()
 	if err != nil {
@@ -193,6 +216,29 @@ func updateQuery(ctx context.Context, db execRunner, q sq.UpdateBuilder) (sql.Re
 	return db.ExecContext(ctx, sql, args...)
 }
 
+func updateQueryRows(ctx context.Context, db queryRunner, q sq.UpdateBuilder) (*sql.Rows, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		since := time.Since(start)
+
+		queryCountTotal.WithLabelValues("update").Inc()
+		queryTimeTotal.WithLabelValues("update").Add(since.Seconds())
+
+		log.WithFields(log.Fields{
+			"query":    sql,
+			"args":     args,
+			"duration": since,
+		}).Debug("SQL query executed")
+	}()
+
+	return db.QueryContext(ctx, sql, args...) //nolint:sqlclosecheck
+}
+
 func deleteQuery(ctx context.Context, db execRunner, q sq.DeleteBuilder) (sql.Result, error) { //nolint:unparam
 	sql, args, err := q.ToSql()
 	if err != nil {
@@ -0,0 +1,101 @@
+package postgresql
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/teran/archived/models"
+)
+
+func (r *repository) ListBlobsAfter(ctx context.Context, cursor string, limit uint64) ([]models.Blob, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	rows, err := selectQuery(ctx, r.db, psql.
+		Select(
+			"checksum",
+			"size",
+			"mime_type",
+			"is_confirmed",
+			"is_broken",
+		).
+		From("blobs").
+		// byte order to match the order of BLOB storage listing,
+		// served by blobs_checksum_c_idx index
+		Where(sq.Expr(`checksum > ? COLLATE "C"`, cursor)).
+		// data of the blobs being deleted is expected to disappear
+		Where(sq.Eq{"is_deleting": false}).
+		OrderBy(`checksum COLLATE "C"`).
+		Limit(limit))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := []models.Blob{}
+	for rows.Next() {
+		var b models.Blob
+		if err := rows.Scan(&b.Checksum, &b.Size, &b.MimeType, &b.IsConfirmed, &b.IsBroken); err != nil {
+			return nil, mapSQLErrors(err)
+		}
+
+		result = append(result, b)
+	}
+
+	return result, mapSQLErrors(rows.Err())
+}
+
+func (r *repository) CreateScrubResult(ctx context.Context, result models.ScrubResult) error {
+	_, err := insertQuery(ctx, r.db, psql.
+		Insert("scrub_results").
+		Columns(
+			"checksum",
+			"status",
+			"expected_size",
+			"actual_size",
+			"actual_checksum",
+			"created_at",
+		).
+		Values(
+			result.Checksum,
+			string(result.Status),
+			result.ExpectedSize,
+			result.ActualSize,
+			result.ActualChecksum,
+			r.tp().UTC(),
+		))
+	return mapSQLErrors(err)
+}
+
+func (r *repository) GetScrubCursor(ctx context.Context) (string, error) {
+	row, err := selectQueryRow(ctx, r.db, psql.
+		Select("checksum").
+		From("scrub_cursor"))
+	if err != nil {
+		return "", mapSQLErrors(err)
+	}
+
+	var cursor string
+	if err := row.Scan(&cursor); err != nil {
+		return "", mapSQLErrors(err)
+	}
+
+	return cursor, nil
+}
+
+func (r *repository) SetScrubCursor(ctx context.Context, cursor string) error {
+	_, err := insertQuery(ctx, r.db, psql.
+		Insert("scrub_cursor").
+		Columns(
+			"checksum",
+			"updated_at",
+		).
+		Values(
+			cursor,
+			r.tp().UTC(),
+		).
+		Suffix("ON CONFLICT (id) DO UPDATE SET checksum = excluded.checksum, updated_at = excluded.updated_at"))
+	return mapSQLErrors(err)
+}
@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+func (s *postgreSQLRepositoryTestSuite) TestScrub() {
+	const (
+		checksum1 = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		checksum2 = "cafebabecafebabecafebabecafebabecafebabecafebabecafebabecafebabe"
+		checksum3 = "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+	)
+
+	s.tp.On("Now").Return("2024-01-02T01:02:03Z").Times(6)
+
+	for _, checksum := range []string{checksum3, checksum1, checksum2} {
+		err := s.repo.CreateBLOB(s.ctx, checksum, 10, "text/plain")
+		s.Require().NoError(err)
+	}
+
+	err := s.repo.ConfirmBlob(s.ctx, checksum1, 10)
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, checksum3, 10)
+	s.Require().NoError(err)
+
+	err = s.repo.MarkBlobsBroken(s.ctx, checksum3)
+	s.Require().NoError(err)
+
+	blobs, err := s.repo.ListBlobsAfter(s.ctx, "", 1)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Blob{
+		{Checksum: checksum1, Size: 10, MimeType: "text/plain", IsConfirmed: true},
+	}, blobs)
+
+	blobs, err = s.repo.ListBlobsAfter(s.ctx, checksum1, 10)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Blob{
+		{Checksum: checksum2, Size: 10, MimeType: "text/plain"},
+		{Checksum: checksum3, Size: 10, MimeType: "text/plain", IsConfirmed: true, IsBroken: true},
+	}, blobs)
+
+	blobs, err = s.repo.ListBlobsAfter(s.ctx, checksum3, 10)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Blob{}, blobs)
+
+	_, err = s.repo.GetScrubCursor(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	err = s.repo.SetScrubCursor(s.ctx, checksum1)
+	s.Require().NoError(err)
+
+	err = s.repo.SetScrubCursor(s.ctx, checksum3)
+	s.Require().NoError(err)
+
+	cursor, err := s.repo.GetScrubCursor(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(checksum3, cursor)
+
+	err = s.repo.CreateScrubResult(s.ctx, models.ScrubResult{
+		Checksum:       checksum1,
+		Status:         models.ScrubStatusMismatch,
+		ExpectedSize:   10,
+		ActualSize:     10,
+		ActualChecksum: checksum2,
+	})
+	s.Require().NoError(err)
+
+	var (
+		status         string
+		actualChecksum string
+	)
+	err = s.db.QueryRowContext(s.ctx, "SELECT status, actual_checksum FROM scrub_results WHERE checksum = $1", checksum1).Scan(&status, &actualChecksum)
+	s.Require().NoError(err)
+	s.Require().Equal("mismatch", status)
+	s.Require().Equal(checksum2, actualChecksum)
+}
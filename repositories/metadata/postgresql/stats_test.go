@@ -46,6 +46,9 @@ func (s *postgreSQLRepositoryTestSuite) TestCountStats() {
 	err = s.repo.CreateBLOB(s.ctx, "deadbeef", 10, "text/plain")
 	s.Require().NoError(err)
 
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 10)
+	s.Require().NoError(err)
+
 	err = s.repo.CreateObject(s.ctx, defaultNamespace, containerName, versionID, "data/some-key.txt", "deadbeef")
 	s.Require().NoError(err)
 
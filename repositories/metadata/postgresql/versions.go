@@ -2,10 +2,12 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 	"github.com/teran/go-collection/types/ptr"
 
@@ -89,6 +91,52 @@ func (r *repository) CreateVersion(ctx context.Context, namespace, container str
 	return versionID, nil
 }
 
+func (r *repository) CloneVersion(ctx context.Context, namespace, container, version, destNamespace, destContainer string) (string, error) {
+	versionTimestamp := r.tp().UTC()
+	versionID := versionTimestamp.Format("20060102150405")
+
+	// Version and all of its objects are created by the single statement so
+	// no partially cloned version could ever be observed. Objects point to
+	// the same blobs as the source version ones, so no data is copied.
+	row, err := selectQueryRow(ctx, r.db, psql.
+		Select("nv.name").
+		Prefix(
+			"WITH src AS ("+
+				"SELECT v.id FROM versions v "+
+				"JOIN containers c ON v.container_id = c.id "+
+				"JOIN namespaces n ON c.namespace_id = n.id "+
+				"WHERE n.name = ? AND c.name = ? AND v.name = ?"+
+				"), dst AS ("+
+				"SELECT c.id FROM containers c "+
+				"JOIN namespaces n ON c.namespace_id = n.id "+
+				"WHERE n.name = ? AND c.name = ?"+
+				"), nv AS ("+
+				"INSERT INTO versions (container_id, name, is_published, created_at) "+
+				"SELECT dst.id, ?::varchar, false, ?::timestamp FROM dst, src "+
+				"RETURNING id, name"+
+				"), nobj AS ("+
+				"INSERT INTO objects (version_id, key_id, blob_id, created_at) "+
+				"SELECT nv.id, o.key_id, o.blob_id, ?::timestamp FROM nv, src "+
+				"JOIN objects o ON o.version_id = src.id"+
+				")",
+			namespace, container, version,
+			destNamespace, destContainer,
+			versionID, versionTimestamp,
+			versionTimestamp,
+		).
+		From("nv"))
+	if err != nil {
+		return "", mapSQLErrors(err)
+	}
+
+	var name string
+	if err := row.Scan(&name); err != nil {
+		return "", mapSQLErrors(err)
+	}
+
+	return name, nil
+}
+
 func (r *repository) GetLatestPublishedVersionByContainer(ctx context.Context, namespace, container string) (string, error) {
 	row, err := selectQueryRow(ctx, r.db, psql.
 		Select("id").
@@ -127,6 +175,33 @@ func (r *repository) GetLatestPublishedVersionByContainer(ctx context.Context, n
 	return versionName, nil
 }
 
+func (r *repository) GetVersion(ctx context.Context, namespace, container, version string) (models.Version, error) {
+	row, err := selectQueryRow(ctx, r.db, psql.
+		Select("v.name", "v.is_published", "v.is_protected", "v.created_at").
+		From("versions v").
+		Join("containers c ON v.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": namespace,
+			"c.name": container,
+			"v.name": version,
+		}))
+	if err != nil {
+		return models.Version{}, mapSQLErrors(err)
+	}
+
+	var (
+		v         models.Version
+		createdAt time.Time
+	)
+	if err := row.Scan(&v.Name, &v.IsPublished, &v.IsProtected, &createdAt); err != nil {
+		return models.Version{}, mapSQLErrors(err)
+	}
+	v.CreatedAt = createdAt.UTC()
+
+	return v, nil
+}
+
 func (r *repository) ListPublishedVersionsByContainer(ctx context.Context, namespace, container string) ([]models.Version, error) {
 	_, versions, err := r.listVersionsByContainer(ctx, namespace, container, ptr.Bool(true), 0, 0)
 	return versions, err
@@ -202,7 +277,7 @@ func (r *repository) listVersionsByContainer(ctx context.Context, namespace, con
 	}
 
 	rows, err := selectQuery(ctx, r.db, psql.
-		Select("name", "is_published", "created_at").
+		Select("name", "is_published", "is_protected", "created_at").
 		From("versions").
 		Where(condition).
 		OrderBy("created_at DESC").
@@ -220,7 +295,7 @@ func (r *repository) listVersionsByContainer(ctx context.Context, namespace, con
 			createdAt time.Time
 		)
 
-		if err := rows.Scan(&r.Name, &r.IsPublished, &createdAt); err != nil {
+		if err := rows.Scan(&r.Name, &r.IsPublished, &r.IsProtected, &createdAt); err != nil {
 			return 0, nil, mapSQLErrors(err)
 		}
 		r.CreatedAt = time.Date(
@@ -278,6 +353,29 @@ func (r *repository) MarkVersionPublished(ctx context.Context, namespace, contai
 		return metadata.ErrNotFound
 	}
 
+	row, err = selectQueryRow(ctx, tx, psql.
+		Select("COUNT(*)").
+		From("objects o").
+		Join("versions v ON o.version_id = v.id").
+		Join("blobs b ON o.blob_id = b.id").
+		Where(sq.Eq{
+			"v.container_id": containerID,
+			"v.name":         version,
+			"b.is_confirmed": false,
+		}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	var unconfirmedCount uint64
+	if err := row.Scan(&unconfirmedCount); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if unconfirmedCount > 0 {
+		return metadata.ErrNotConfirmed
+	}
+
 	_, err = updateQuery(ctx, tx, psql.
 		Update("versions").
 		Set("is_published", true).
@@ -296,6 +394,55 @@ func (r *repository) MarkVersionPublished(ctx context.Context, namespace, contai
 	return nil
 }
 
+func (r *repository) SetVersionProtected(ctx context.Context, namespace, container, version string, isProtected bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("error rolling back")
+		}
+	}()
+
+	row, err := selectQueryRow(ctx, tx, psql.
+		Select("v.id").
+		From("versions v").
+		Join("containers c ON v.container_id = c.id").
+		Join("namespaces n ON c.namespace_id = n.id").
+		Where(sq.Eq{
+			"n.name": namespace,
+			"c.name": container,
+			"v.name": version,
+		}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	var versionID uint64
+	if err := row.Scan(&versionID); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	_, err = updateQuery(ctx, tx, psql.
+		Update("versions").
+		Set("is_protected", isProtected).
+		Where(sq.Eq{
+			"id": versionID,
+		}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return mapSQLErrors(err)
+	}
+	return nil
+}
+
 func (r *repository) DeleteVersion(ctx context.Context, namespace, container, version string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -324,20 +471,49 @@ func (r *repository) DeleteVersion(ctx context.Context, namespace, container, ve
 	}
 
 	row, err = selectQueryRow(ctx, tx, psql.
-		Select("v.id").
+		Select("v.id", "v.is_protected").
 		From("versions v").
 		Join("containers c ON v.container_id = c.id").
 		Where(sq.Eq{
 			"c.namespace_id": namespaceID,
 			"c.name":         container,
 			"v.name":         version,
-		}))
+		}).
+		Suffix("FOR UPDATE OF v"))
 	if err != nil {
 		return mapSQLErrors(err)
 	}
 
-	var versionID uint64
-	if err := row.Scan(&versionID); err != nil {
+	var (
+		versionID   uint64
+		isProtected bool
+	)
+	if err := row.Scan(&versionID, &isProtected); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if isProtected {
+		return metadata.ErrProtected
+	}
+
+	row, err = selectQueryRow(ctx, tx, psql.
+		Select("COUNT(*)").
+		From("aliases").
+		Where(sq.Eq{"version_id": versionID}))
+	if err != nil {
+		return mapSQLErrors(err)
+	}
+
+	var aliasesCount uint64
+	if err := row.Scan(&aliasesCount); err != nil {
+		return mapSQLErrors(err)
+	}
+
+	if aliasesCount > 0 {
+		return metadata.ErrAliased
+	}
+
+	if err := markBlobsUnreferenced(ctx, tx, r.tp().UTC(), sq.Eq{"version_id": versionID}); err != nil {
 		return mapSQLErrors(err)
 	}
 
@@ -382,31 +558,10 @@ func (r *repository) DeleteExpiredVersionsWithObjects(ctx context.Context, unpub
 
 	now := r.tp().UTC()
 
-	q := psql.
-		Select(
-			"v.id AS version_id",
-		).
-		From("containers c").
-		Join("versions v ON v.container_id = c.id").
-		Join("namespaces n ON n.id = c.namespace_id").
-		Where(
-			sq.Or{
-				sq.And{
-					sq.Gt{
-						"c.version_ttl_seconds": 0,
-					},
-					sq.Expr("v.created_at <= (?::timestamp - c.version_ttl_seconds * interval '1 second')", now.Format(time.RFC3339)),
-				},
-				sq.And{
-					sq.Eq{
-						"v.is_published": false,
-					},
-					sq.Expr("v.created_at <= ?::timestamp", now.Add(-1*unpublishedVersionsMaxAge).Format(time.RFC3339)),
-				},
-			},
-		)
-
-	rows, err := selectQuery(ctx, tx, q)
+	// versions are locked to prevent them from being protected or aliased
+	// until they're deleted
+	rows, err := selectQuery(ctx, tx, expiredVersionsQuery(now, unpublishedVersionsMaxAge, "v.id AS version_id").
+		Suffix("FOR UPDATE OF v"))
 	if err != nil {
 		return mapSQLErrors(err)
 	}
@@ -430,6 +585,10 @@ func (r *repository) DeleteExpiredVersionsWithObjects(ctx context.Context, unpub
 	// lib/pq (and probably PostgreSQL itself) has a limit of 65k arguments so let's batch 'em
 	//
 	if err := indexChunks(len(deleteCandidates), expiredVersionsBatchSize, func(start, end int) error {
+		if err := markBlobsUnreferenced(ctx, tx, now, sq.Eq{"version_id": deleteCandidates[start:end]}); err != nil {
+			return err
+		}
+
 		if _, err := deleteQuery(ctx, tx, psql.
 			Delete("objects").
 			Where(sq.Eq{
@@ -506,6 +665,139 @@ func (r *repository) DeleteExpiredVersionsWithObjects(ctx context.Context, unpub
 	return nil
 }
 
+func (r *repository) ListExpiredVersionsWithObjects(ctx context.Context, unpublishedVersionsMaxAge time.Duration) ([]models.ExpiredVersion, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() {
+		err := tx.Rollback()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("error rolling back")
+		}
+	}()
+
+	rows, err := selectQuery(ctx, tx, expiredVersionsQuery(
+		r.tp().UTC(), unpublishedVersionsMaxAge,
+		"v.id AS version_id",
+		"n.name AS namespace",
+		"c.name AS container",
+		"v.name AS version",
+		"v.is_published AS is_published",
+		"v.is_protected AS is_protected",
+		"v.created_at AS created_at",
+	).OrderBy("n.name", "c.name", "v.created_at"))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	versionIDs := []uint64{}
+	versions := []models.ExpiredVersion{}
+	versionIdx := map[uint64]int{}
+	for rows.Next() {
+		var (
+			versionID uint64
+			v         models.ExpiredVersion
+			createdAt time.Time
+		)
+		if err := rows.Scan(&versionID, &v.Namespace, &v.Container, &v.Version.Name, &v.Version.IsPublished, &v.Version.IsProtected, &createdAt); err != nil {
+			return nil, mapSQLErrors(err)
+		}
+		v.Version.CreatedAt = time.Date(
+			createdAt.Year(), createdAt.Month(), createdAt.Day(),
+			createdAt.Hour(), createdAt.Minute(), createdAt.Second(), createdAt.Nanosecond(),
+			time.UTC,
+		)
+		v.Objects = []models.Object{}
+
+		versionIdx[versionID] = len(versions)
+		versionIDs = append(versionIDs, versionID)
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, mapSQLErrors(err)
+	}
+
+	if len(versionIDs) == 0 {
+		return versions, nil
+	}
+
+	rows, err = selectQuery(ctx, tx, psql.
+		Select(
+			"o.version_id AS version_id",
+			"ok.key AS key",
+			"b.checksum AS checksum",
+			"b.size AS size",
+			"b.mime_type AS mime_type",
+			"o.created_at AS created_at",
+		).
+		From("objects o").
+		Join("object_keys ok ON ok.id = o.key_id").
+		Join("blobs b ON b.id = o.blob_id").
+		Where(sq.Expr("o.version_id = ANY(?)", pq.Array(versionIDs))).
+		OrderBy("o.version_id", "ok.key"))
+	if err != nil {
+		return nil, mapSQLErrors(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			versionID uint64
+			o         models.Object
+			createdAt time.Time
+		)
+		if err := rows.Scan(&versionID, &o.Key, &o.Checksum, &o.Size, &o.MimeType, &createdAt); err != nil {
+			return nil, mapSQLErrors(err)
+		}
+		o.CreatedAt = time.Date(
+			createdAt.Year(), createdAt.Month(), createdAt.Day(),
+			createdAt.Hour(), createdAt.Minute(), createdAt.Second(), createdAt.Nanosecond(),
+			time.UTC,
+		)
+
+		idx := versionIdx[versionID]
+		versions[idx].Objects = append(versions[idx].Objects, o)
+	}
+
+	return versions, mapSQLErrors(rows.Err())
+}
+
+func expiredVersionsQuery(now time.Time, unpublishedVersionsMaxAge time.Duration, columns ...string) sq.SelectBuilder {
+	return psql.
+		Select(columns...).
+		From("containers c").
+		Join("versions v ON v.container_id = c.id").
+		Join("namespaces n ON n.id = c.namespace_id").
+		Where(sq.Eq{
+			"v.is_protected": false,
+		}).
+		Where("NOT EXISTS (SELECT 1 FROM aliases a WHERE a.version_id = v.id)").
+		Where(
+			sq.Or{
+				sq.And{
+					sq.Gt{
+						"c.version_ttl_seconds": 0,
+					},
+					sq.Expr("v.created_at <= (?::timestamp - c.version_ttl_seconds * interval '1 second')", now.Format(time.RFC3339)),
+				},
+				sq.And{
+					sq.Eq{
+						"v.is_published": false,
+					},
+					sq.Expr("v.created_at <= ?::timestamp", now.Add(-1*unpublishedVersionsMaxAge).Format(time.RFC3339)),
+				},
+			},
+		)
+}
+
 func indexChunks(length, chuckLen int, fn func(start, end int) error) error {
 	if chuckLen <= 0 {
 		return errors.ErrUnsupported
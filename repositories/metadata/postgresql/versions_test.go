@@ -46,6 +46,17 @@ func (s *postgreSQLRepositoryTestSuite) TestVersionsOperations() {
 			CreatedAt: time.Date(2024, 7, 7, 11, 12, 13, 0, time.UTC),
 		},
 	}, listC2)
+
+	v, err := s.repo.GetVersion(s.ctx, defaultNamespace, "container1", "20240707101112")
+	s.Require().NoError(err)
+	s.Require().Equal(models.Version{
+		Name:      "20240707101112",
+		CreatedAt: time.Date(2024, 7, 7, 10, 11, 12, 0, time.UTC),
+	}, v)
+
+	_, err = s.repo.GetVersion(s.ctx, defaultNamespace, "container2", "20240707101112")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
 }
 
 func (s *postgreSQLRepositoryTestSuite) TestPublishVersion() {
@@ -98,6 +109,72 @@ func (s *postgreSQLRepositoryTestSuite) TestPublishVersion() {
 	}, list)
 }
 
+func (s *postgreSQLRepositoryTestSuite) TestCloneVersion() {
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Times(6)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Times(2)
+	s.tp.On("Now").Return("2024-07-07T10:11:14Z").Times(3)
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, "container1", -1)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateContainer(s.ctx, defaultNamespace, "container2", -1)
+	s.Require().NoError(err)
+
+	versionID, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "container1")
+	s.Require().NoError(err)
+
+	err = s.repo.CreateBLOB(s.ctx, "deadbeef", 10, "text/plain")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 10)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, "container1", versionID, "data/key1.txt", "deadbeef")
+	s.Require().NoError(err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, "container1", versionID, "data/key2.txt", "deadbeef")
+	s.Require().NoError(err)
+
+	err = s.repo.MarkVersionPublished(s.ctx, defaultNamespace, "container1", versionID)
+	s.Require().NoError(err)
+
+	clonedVersionID, err := s.repo.CloneVersion(s.ctx, defaultNamespace, "container1", versionID, defaultNamespace, "container1")
+	s.Require().NoError(err)
+	s.Require().Equal("20240707101113", clonedVersionID)
+
+	v, err := s.repo.GetVersion(s.ctx, defaultNamespace, "container1", clonedVersionID)
+	s.Require().NoError(err)
+	s.Require().False(v.IsPublished)
+
+	total, objects, err := s.repo.ListObjects(s.ctx, defaultNamespace, "container1", clonedVersionID, 0, 100)
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(2), total)
+	s.Require().Equal("data/key1.txt", objects[0].Key)
+	s.Require().Equal("deadbeef", objects[0].Checksum)
+	s.Require().Equal(time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC), objects[0].CreatedAt)
+
+	// Same version name in the same container
+	_, err = s.repo.CloneVersion(s.ctx, defaultNamespace, "container1", versionID, defaultNamespace, "container1")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrConflict, err)
+
+	clonedVersionID, err = s.repo.CloneVersion(s.ctx, defaultNamespace, "container1", versionID, defaultNamespace, "container2")
+	s.Require().NoError(err)
+	s.Require().Equal("20240707101114", clonedVersionID)
+
+	total, _, err = s.repo.ListObjects(s.ctx, defaultNamespace, "container2", clonedVersionID, 0, 100)
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(2), total)
+
+	_, err = s.repo.CloneVersion(s.ctx, defaultNamespace, "container1", "20000101000000", defaultNamespace, "container2")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	_, err = s.repo.CloneVersion(s.ctx, defaultNamespace, "container1", versionID, defaultNamespace, "not-existent")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+}
+
 func (s *postgreSQLRepositoryTestSuite) TestPublishVersionErrors() {
 	// Not existent container
 	err := s.repo.MarkVersionPublished(s.ctx, defaultNamespace, "not-existent", "version")
@@ -209,6 +286,9 @@ func (s *postgreSQLRepositoryTestSuite) TestDeleteVersion() {
 	// CreateObject (created_at)
 	s.tp.On("Now").Return("2024-07-07T10:11:16Z").Once()
 
+	// DeleteVersion (unreferenced_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:17Z").Once()
+
 	err := s.repo.CreateContainer(s.ctx, defaultNamespace, "container1", -1)
 	s.Require().NoError(err)
 
@@ -230,6 +310,9 @@ func (s *postgreSQLRepositoryTestSuite) TestDeleteVersion() {
 	err = s.repo.CreateBLOB(s.ctx, "deadbeef", 10, "application/octet-stream")
 	s.Require().NoError(err)
 
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 10)
+	s.Require().NoError(err)
+
 	err = s.repo.CreateObject(s.ctx, defaultNamespace, "container2", version4, "testkey", "deadbeef")
 	s.Require().NoError(err)
 
@@ -368,6 +451,9 @@ func (s *postgreSQLRepositoryTestSuite) TestDeleteExpiredVersionsWithObjects() {
 
 	err = s.repo.CreateBLOB(s.ctx, "deadbeef", 10, "text/plain")
 	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeef", 10)
+	s.Require().NoError(err)
 	for _, v := range []string{version1, version2, version3, version4} {
 		err = s.repo.CreateObject(s.ctx, defaultNamespace, "test-container", v, "some-key1.txt", "deadbeef")
 		s.Require().NoError(err)
@@ -422,3 +508,172 @@ func (s *postgreSQLRepositoryTestSuite) TestDeleteExpiredVersionsWithObjects() {
 		},
 	}, versions)
 }
+
+func (s *postgreSQLRepositoryTestSuite) TestListExpiredVersionsWithObjects() {
+	// CreateContainer (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-10-07T10:11:14Z").Once()
+
+	// CreateBLOB twice (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Times(2)
+
+	// CreateObject three times (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:15Z").Times(3)
+
+	// ListExpiredVersionsWithObjects (now())
+	s.tp.On("Now").Return("2024-10-08T11:11:11Z").Once()
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, "test-container", 48*time.Hour)
+	s.Require().NoError(err)
+
+	version1, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	version2, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	err = s.repo.CreateBLOB(s.ctx, "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", 10, "text/plain")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", 10)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateBLOB(s.ctx, "cafebabecafebabecafebabecafebabecafebabecafebabecafebabecafebabe", 20, "application/json")
+	s.Require().NoError(err)
+
+	err = s.repo.ConfirmBlob(s.ctx, "cafebabecafebabecafebabecafebabecafebabecafebabecafebabecafebabe", 20)
+	s.Require().NoError(err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, "test-container", version1, "some-key1.txt", "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+	s.Require().NoError(err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, "test-container", version1, "some-key2.json", "cafebabecafebabecafebabecafebabecafebabecafebabecafebabecafebabe")
+	s.Require().NoError(err)
+
+	err = s.repo.CreateObject(s.ctx, defaultNamespace, "test-container", version2, "some-key1.txt", "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+	s.Require().NoError(err)
+
+	err = s.repo.MarkVersionPublished(s.ctx, defaultNamespace, "test-container", version1)
+	s.Require().NoError(err)
+
+	err = s.repo.MarkVersionPublished(s.ctx, defaultNamespace, "test-container", version2)
+	s.Require().NoError(err)
+
+	versions, err := s.repo.ListExpiredVersionsWithObjects(s.ctx, 24*7*time.Hour)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.ExpiredVersion{
+		{
+			Namespace: defaultNamespace,
+			Container: "test-container",
+			Version: models.Version{
+				Name:        "20240707101113",
+				IsPublished: true,
+				CreatedAt:   time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC),
+			},
+			Objects: []models.Object{
+				{
+					Key:       "some-key1.txt",
+					Checksum:  "deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+					Size:      10,
+					MimeType:  "text/plain",
+					CreatedAt: time.Date(2024, 7, 7, 10, 11, 15, 0, time.UTC),
+				},
+				{
+					Key:       "some-key2.json",
+					Checksum:  "cafebabecafebabecafebabecafebabecafebabecafebabecafebabecafebabe",
+					Size:      20,
+					MimeType:  "application/json",
+					CreatedAt: time.Date(2024, 7, 7, 10, 11, 15, 0, time.UTC),
+				},
+			},
+		},
+	}, versions)
+
+	// Nothing is deleted by listing
+	allVersions, err := s.repo.ListAllVersionsByContainer(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+	s.Require().Len(allVersions, 2)
+}
+
+func (s *postgreSQLRepositoryTestSuite) TestProtectedVersions() {
+	// CreateContainer (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:12Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:13Z").Once()
+
+	// CreateVersion (created_at)
+	s.tp.On("Now").Return("2024-07-07T10:11:14Z").Once()
+
+	// DeleteExpiredVersionsWithObjects (now())
+	s.tp.On("Now").Return("2024-10-08T11:11:11Z").Once()
+
+	// DeleteVersion (unreferenced_at)
+	s.tp.On("Now").Return("2024-10-08T11:11:12Z").Once()
+
+	err := s.repo.CreateContainer(s.ctx, defaultNamespace, "test-container", -1)
+	s.Require().NoError(err)
+
+	version1, err := s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	_, err = s.repo.CreateVersion(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+
+	err = s.repo.SetVersionProtected(s.ctx, defaultNamespace, "test-container", version1, true)
+	s.Require().NoError(err)
+
+	err = s.repo.SetVersionProtected(s.ctx, defaultNamespace, "test-container", "20010101010101", true)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrNotFound, err)
+
+	versions, err := s.repo.ListAllVersionsByContainer(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Version{
+		{
+			Name:      "20240707101114",
+			CreatedAt: time.Date(2024, 7, 7, 10, 11, 14, 0, time.UTC),
+		},
+		{
+			Name:        "20240707101113",
+			IsProtected: true,
+			CreatedAt:   time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC),
+		},
+	}, versions)
+
+	err = s.repo.DeleteVersion(s.ctx, defaultNamespace, "test-container", version1)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrProtected, err)
+
+	err = s.repo.DeleteContainer(s.ctx, defaultNamespace, "test-container")
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrProtected, err)
+
+	err = s.repo.DeleteNamespace(s.ctx, defaultNamespace)
+	s.Require().Error(err)
+	s.Require().Equal(metadata.ErrProtected, err)
+
+	err = s.repo.DeleteExpiredVersionsWithObjects(s.ctx, 24*time.Hour)
+	s.Require().NoError(err)
+
+	versions, err = s.repo.ListAllVersionsByContainer(s.ctx, defaultNamespace, "test-container")
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Version{
+		{
+			Name:        "20240707101113",
+			IsProtected: true,
+			CreatedAt:   time.Date(2024, 7, 7, 10, 11, 13, 0, time.UTC),
+		},
+	}, versions)
+
+	err = s.repo.SetVersionProtected(s.ctx, defaultNamespace, "test-container", version1, false)
+	s.Require().NoError(err)
+
+	err = s.repo.DeleteVersion(s.ctx, defaultNamespace, "test-container", version1)
+	s.Require().NoError(err)
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/blob"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+// Check compares the keys stored in BLOB storage with the blobs recorded
+// in metadata and writes the report of leaked and lost blobs. Both sides
+// are listed in ascending order so they're merged in a single pass.
+func (s *service) Check(ctx context.Context) error {
+	if s.cfg.ReportWriter == nil {
+		return errors.New("report writer is required")
+	}
+
+	log.WithFields(log.Fields{
+		"repair": s.cfg.Repair,
+	}).Info("running consistency check ...")
+
+	r := &CheckReport{
+		Repair: s.cfg.Repair,
+		Leaks:  []CheckReportLeak{},
+		Lost:   []CheckReportLost{},
+	}
+
+	it := &blobIterator{
+		repo:      s.cfg.MdRepo,
+		batchSize: s.cfg.BatchSize,
+	}
+
+	lost := func(b models.Blob, actualSize uint64, reason string) {
+		r.Summary.MetadataBlobs++
+
+		// pending blobs are not uploaded yet
+		if !b.IsConfirmed {
+			return
+		}
+		r.addLost(b, actualSize, reason)
+	}
+
+	lastKey := ""
+	err := s.cfg.BlobRepo.ListBlobs(ctx, func(info blob.Info) error {
+		if info.Key <= lastKey {
+			return errors.Errorf("BLOB storage listing is not in ascending order: `%s` after `%s`", info.Key, lastKey)
+		}
+		lastKey = info.Key
+		r.Summary.StorageKeys++
+
+		for {
+			b, ok, err := it.peek(ctx)
+			if err != nil {
+				return err
+			}
+
+			if !ok || b.Checksum > info.Key {
+				break
+			}
+			it.advance()
+
+			if b.Checksum == info.Key {
+				if b.Size != info.Size {
+					lost(b, info.Size, lostReasonSizeMismatch)
+					return nil
+				}
+				r.Summary.MetadataBlobs++
+				return nil
+			}
+
+			lost(b, 0, lostReasonMissing)
+		}
+
+		// the key could be uploaded right after the metadata batch
+		// was listed so recently modified keys are not treated as leaks
+		if info.ModifiedAt.After(s.tp().Add(-1 * s.cfg.LeakGracePeriod)) {
+			log.WithFields(log.Fields{
+				"key":         info.Key,
+				"modified_at": info.ModifiedAt,
+			}).Debug("skipping recently modified key without metadata")
+			return nil
+		}
+
+		r.addLeak(info)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "error listing BLOB storage")
+	}
+
+	for {
+		b, ok, err := it.peek(ctx)
+		if err != nil {
+			return errors.Wrap(err, "error listing blobs")
+		}
+
+		if !ok {
+			break
+		}
+		it.advance()
+
+		lost(b, 0, lostReasonMissing)
+	}
+
+	if s.cfg.Repair {
+		if err := s.repair(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"storage_keys":   r.Summary.StorageKeys,
+		"metadata_blobs": r.Summary.MetadataBlobs,
+		"leaks":          r.Summary.LeaksCount,
+		"leaked_bytes":   r.Summary.LeakedBytes,
+		"lost":           r.Summary.LostCount,
+	}).Info("consistency check complete")
+
+	enc := json.NewEncoder(s.cfg.ReportWriter)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return errors.Wrap(err, "error writing report")
+	}
+
+	return nil
+}
+
+// repair rechecks every entry right before acting on it since the report
+// could be hours old at this moment
+func (s *service) repair(ctx context.Context, r *CheckReport) error {
+	for i, leak := range r.Leaks {
+		err := s.cfg.MdRepo.EnsureBlobKey(ctx, leak.Key, leak.Size)
+		if err == nil || errors.Is(err, metadata.ErrNotConfirmed) || errors.Is(err, metadata.ErrDeleting) {
+			log.WithFields(log.Fields{
+				"key":  leak.Key,
+				"size": leak.Size,
+			}).Info("leaked blob is recorded in metadata since the check, skipping ...")
+			continue
+		}
+
+		if !errors.Is(err, metadata.ErrNotFound) {
+			return errors.Wrapf(err, "error checking blob `%s`", leak.Key)
+		}
+
+		log.WithFields(log.Fields{
+			"key":  leak.Key,
+			"size": leak.Size,
+		}).Debug("deleting leaked blob ...")
+
+		if err := s.cfg.BlobRepo.DeleteBlob(ctx, leak.Key); err != nil {
+			return errors.Wrapf(err, "error deleting blob `%s`", leak.Key)
+		}
+		r.Leaks[i].Deleted = true
+	}
+
+	checksums := []string{}
+	lost := []int{}
+	for i, l := range r.Lost {
+		if l.IsBroken {
+			continue
+		}
+
+		size, err := s.cfg.BlobRepo.BlobSize(ctx, l.Checksum)
+		if err != nil && !errors.Is(err, blob.ErrNotFound) {
+			return errors.Wrapf(err, "error checking blob `%s`", l.Checksum)
+		}
+
+		if err == nil && size == l.Size {
+			log.WithFields(log.Fields{
+				"checksum": l.Checksum,
+				"size":     l.Size,
+			}).Info("lost blob is uploaded since the check, skipping ...")
+			continue
+		}
+
+		checksums = append(checksums, l.Checksum)
+		lost = append(lost, i)
+	}
+
+	if len(checksums) > 0 {
+		if err := s.cfg.MdRepo.MarkBlobsBroken(ctx, checksums...); err != nil {
+			return errors.Wrap(err, "error marking blobs as broken")
+		}
+	}
+
+	for _, i := range lost {
+		r.Lost[i].IsBroken = true
+	}
+	return nil
+}
+
+// blobIterator reads blobs from metadata in batches
+type blobIterator struct {
+	repo      metadata.Repository
+	batchSize uint64
+
+	buf    []models.Blob
+	cursor string
+	done   bool
+}
+
+func (it *blobIterator) peek(ctx context.Context) (models.Blob, bool, error) {
+	if len(it.buf) == 0 && !it.done {
+		blobs, err := it.repo.ListBlobsAfter(ctx, it.cursor, it.batchSize)
+		if err != nil {
+			return models.Blob{}, false, errors.Wrap(err, "error listing blobs")
+		}
+
+		for _, b := range blobs {
+			if b.Checksum <= it.cursor {
+				return models.Blob{}, false, errors.Errorf("blobs listing is not in ascending order: `%s` after `%s`", b.Checksum, it.cursor)
+			}
+			it.cursor = b.Checksum
+		}
+
+		it.buf = blobs
+		it.done = uint64(len(blobs)) < it.batchSize
+	}
+
+	if len(it.buf) == 0 {
+		return models.Blob{}, false, nil
+	}
+	return it.buf[0], true, nil
+}
+
+func (it *blobIterator) advance() {
+	it.buf = it.buf[1:]
+}
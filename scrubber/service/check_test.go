@@ -0,0 +1,148 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/blob"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+func (s *serviceTestSuite) TestCheck() {
+	s.mockCheckListings()
+
+	err := s.svc.Check(s.ctx)
+	s.Require().NoError(err)
+
+	s.Require().Equal(CheckReport{
+		Summary: CheckReportSummary{
+			StorageKeys:   5,
+			MetadataBlobs: 5,
+			LeaksCount:    2,
+			LeakedBytes:   5,
+			LostCount:     3,
+		},
+		Leaks: []CheckReportLeak{
+			{Key: "aaaa", Size: 1, ModifiedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Key: "eeee", Size: 4, ModifiedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		Lost: []CheckReportLost{
+			{Checksum: "0aaa", Size: 3, Reason: "missing"},
+			{Checksum: "bbbb", Size: 4, ActualSize: 5, Reason: "size_mismatch"},
+			{Checksum: "ffff", Size: 7, Reason: "missing", IsBroken: true},
+		},
+	}, s.decodeReport())
+}
+
+func (s *serviceTestSuite) TestCheckRepair() {
+	s.cfg.Repair = true
+	s.mockCheckListings()
+
+	s.repoMock.On("EnsureBlobKey", "aaaa", uint64(1)).Return(metadata.ErrNotFound).Once()
+	s.blobRepoMock.On("DeleteBlob", "aaaa").Return(nil).Once()
+	s.repoMock.On("EnsureBlobKey", "eeee", uint64(4)).Return(metadata.ErrNotFound).Once()
+	s.blobRepoMock.On("DeleteBlob", "eeee").Return(nil).Once()
+	s.blobRepoMock.On("BlobSize", "0aaa").Return(uint64(0), blob.ErrNotFound).Once()
+	s.blobRepoMock.On("BlobSize", "bbbb").Return(uint64(5), nil).Once()
+	s.repoMock.On("MarkBlobsBroken", []string{"0aaa", "bbbb"}).Return(nil).Once()
+
+	err := s.svc.Check(s.ctx)
+	s.Require().NoError(err)
+
+	r := s.decodeReport()
+	s.Require().True(r.Repair)
+	s.Require().Equal([]CheckReportLeak{
+		{Key: "aaaa", Size: 1, ModifiedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Deleted: true},
+		{Key: "eeee", Size: 4, ModifiedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Deleted: true},
+	}, r.Leaks)
+	s.Require().Equal([]CheckReportLost{
+		{Checksum: "0aaa", Size: 3, Reason: "missing", IsBroken: true},
+		{Checksum: "bbbb", Size: 4, ActualSize: 5, Reason: "size_mismatch", IsBroken: true},
+		{Checksum: "ffff", Size: 7, Reason: "missing", IsBroken: true},
+	}, r.Lost)
+}
+
+func (s *serviceTestSuite) TestCheckRepairRechecksEntries() {
+	s.cfg.Repair = true
+	s.mockCheckListings()
+
+	// metadata of the leaked blob is created since the check
+	s.repoMock.On("EnsureBlobKey", "aaaa", uint64(1)).Return(metadata.ErrNotConfirmed).Once()
+	s.repoMock.On("EnsureBlobKey", "eeee", uint64(4)).Return(nil).Once()
+	// lost blob is uploaded since the check
+	s.blobRepoMock.On("BlobSize", "0aaa").Return(uint64(3), nil).Once()
+	s.blobRepoMock.On("BlobSize", "bbbb").Return(uint64(5), nil).Once()
+	s.repoMock.On("MarkBlobsBroken", []string{"bbbb"}).Return(nil).Once()
+
+	err := s.svc.Check(s.ctx)
+	s.Require().NoError(err)
+
+	r := s.decodeReport()
+	s.Require().Equal([]CheckReportLeak{
+		{Key: "aaaa", Size: 1, ModifiedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Key: "eeee", Size: 4, ModifiedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, r.Leaks)
+	s.Require().Equal([]CheckReportLost{
+		{Checksum: "0aaa", Size: 3, Reason: "missing"},
+		{Checksum: "bbbb", Size: 4, ActualSize: 5, Reason: "size_mismatch", IsBroken: true},
+		{Checksum: "ffff", Size: 7, Reason: "missing", IsBroken: true},
+	}, r.Lost)
+}
+
+func (s *serviceTestSuite) TestCheckUnorderedListing() {
+	s.repoMock.On("ListBlobsAfter", "", uint64(2)).Return([]models.Blob{}, nil).Once()
+	s.blobRepoMock.On("ListBlobs").Return([]blob.Info{
+		{Key: "bbbb"},
+		{Key: "aaaa"},
+	}, nil).Once()
+
+	err := s.svc.Check(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("error listing BLOB storage: BLOB storage listing is not in ascending order: `aaaa` after `bbbb`", err.Error())
+	s.Require().Empty(s.report.String())
+}
+
+func (s *serviceTestSuite) TestCheckListingError() {
+	s.repoMock.On("ListBlobsAfter", "", uint64(2)).Return([]models.Blob{}, errors.New("connection reset")).Once()
+	s.blobRepoMock.On("ListBlobs").Return([]blob.Info{
+		{Key: "aaaa"},
+	}, nil).Once()
+
+	err := s.svc.Check(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("error listing BLOB storage: error listing blobs: connection reset", err.Error())
+}
+
+func (s *serviceTestSuite) mockCheckListings() {
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.repoMock.On("ListBlobsAfter", "", uint64(2)).Return([]models.Blob{
+		{Checksum: "0aaa", Size: 3, IsConfirmed: true},
+		{Checksum: "0bbb", Size: 3},
+	}, nil).Once()
+	s.repoMock.On("ListBlobsAfter", "0bbb", uint64(2)).Return([]models.Blob{
+		{Checksum: "bbbb", Size: 4, IsConfirmed: true},
+		{Checksum: "cccc", Size: 2, IsConfirmed: true},
+	}, nil).Once()
+	s.repoMock.On("ListBlobsAfter", "cccc", uint64(2)).Return([]models.Blob{
+		{Checksum: "ffff", Size: 7, IsConfirmed: true, IsBroken: true},
+	}, nil).Once()
+
+	s.blobRepoMock.On("ListBlobs").Return([]blob.Info{
+		{Key: "aaaa", Size: 1, ModifiedAt: old},
+		{Key: "bbbb", Size: 5, ModifiedAt: old},
+		{Key: "cccc", Size: 2, ModifiedAt: old},
+		{Key: "dddd", Size: 3, ModifiedAt: time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)},
+		{Key: "eeee", Size: 4, ModifiedAt: old},
+	}, nil).Once()
+}
+
+func (s *serviceTestSuite) decodeReport() CheckReport {
+	var r CheckReport
+	err := json.Unmarshal(s.report.Bytes(), &r)
+	s.Require().NoError(err)
+	return r
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"io"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/teran/archived/repositories/blob"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+type Config struct {
+	MdRepo     metadata.Repository
+	BlobRepo   blob.Repository
+	Registerer prometheus.Registerer
+	BatchSize  uint64
+	// RateLimit is the maximum amount of bytes read from BLOB storage per
+	// second, zero means no limit
+	RateLimit uint64
+	// Interval is the pause between two full passes over the blobs
+	Interval time.Duration
+
+	// Repair makes consistency check delete leaked keys from BLOB storage
+	// and mark blobs with lost data as broken
+	Repair bool
+	// LeakGracePeriod is the age of the key without metadata after which
+	// it's considered as leaked
+	LeakGracePeriod time.Duration
+	ReportWriter    io.Writer
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MdRepo, validation.Required),
+		validation.Field(&c.BlobRepo, validation.Required),
+		validation.Field(&c.Registerer, validation.Required),
+		validation.Field(&c.BatchSize, validation.Required),
+		validation.Field(&c.Interval, validation.Required, validation.Min(time.Minute)),
+	)
+}
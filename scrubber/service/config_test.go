@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	blobRepoMock "github.com/teran/archived/repositories/blob/mock"
+	mockRepo "github.com/teran/archived/repositories/metadata/mock"
+)
+
+func TestConfigValidate(t *testing.T) {
+	type testCase struct {
+		name   string
+		in     *Config
+		expOut error
+	}
+
+	tcs := []testCase{
+		{
+			name: "valid config",
+			in: &Config{
+				MdRepo:     mockRepo.New(),
+				BlobRepo:   blobRepoMock.New(),
+				Registerer: prometheus.NewRegistry(),
+				BatchSize:  100,
+				Interval:   time.Hour,
+			},
+		},
+		{
+			name: "too short interval",
+			in: &Config{
+				MdRepo:     mockRepo.New(),
+				BlobRepo:   blobRepoMock.New(),
+				Registerer: prometheus.NewRegistry(),
+				BatchSize:  100,
+				Interval:   time.Second,
+			},
+			expOut: errors.New(
+				"Interval: must be no less than 1m0s.",
+			),
+		},
+		{
+			name: "empty config",
+			in:   &Config{},
+			expOut: errors.New(
+				"BatchSize: cannot be blank; BlobRepo: cannot be blank; Interval: cannot be blank; MdRepo: cannot be blank; Registerer: cannot be blank.",
+			),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+
+			err := tc.in.Validate()
+			if tc.expOut != nil {
+				r.Error(err)
+				r.Equal(tc.expOut.Error(), err.Error())
+			} else {
+				r.NoError(err)
+			}
+		})
+	}
+}
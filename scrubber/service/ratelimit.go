@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+type rateLimitedReader struct {
+	ctx     context.Context
+	rd      io.Reader
+	limiter *rate.Limiter
+}
+
+func newRateLimitedReader(ctx context.Context, rd io.Reader, limiter *rate.Limiter) io.Reader {
+	if limiter == nil {
+		return rd
+	}
+
+	return &rateLimitedReader{
+		ctx:     ctx,
+		rd:      rd,
+		limiter: limiter,
+	}
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	// WaitN fails for n exceeding the burst so reads are split
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err := r.rd.Read(p)
+	if n > 0 {
+		if werr := r.limiter.WaitN(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
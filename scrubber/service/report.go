@@ -0,0 +1,67 @@
+package service
+
+import (
+	"time"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/blob"
+)
+
+const (
+	lostReasonMissing      = "missing"
+	lostReasonSizeMismatch = "size_mismatch"
+)
+
+type CheckReport struct {
+	Repair  bool               `json:"repair"`
+	Summary CheckReportSummary `json:"summary"`
+	Leaks   []CheckReportLeak  `json:"leaks"`
+	Lost    []CheckReportLost  `json:"lost"`
+}
+
+type CheckReportSummary struct {
+	StorageKeys   uint64 `json:"storage_keys"`
+	MetadataBlobs uint64 `json:"metadata_blobs"`
+	LeaksCount    uint64 `json:"leaks_count"`
+	LeakedBytes   uint64 `json:"leaked_bytes"`
+	LostCount     uint64 `json:"lost_count"`
+}
+
+// CheckReportLeak is the key present in BLOB storage without metadata
+type CheckReportLeak struct {
+	Key        string    `json:"key"`
+	Size       uint64    `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Deleted    bool      `json:"deleted"`
+}
+
+// CheckReportLost is the blob recorded in metadata without the data
+// in BLOB storage
+type CheckReportLost struct {
+	Checksum   string `json:"checksum"`
+	Size       uint64 `json:"size"`
+	ActualSize uint64 `json:"actual_size,omitempty"`
+	Reason     string `json:"reason"`
+	IsBroken   bool   `json:"is_broken"`
+}
+
+func (r *CheckReport) addLeak(b blob.Info) {
+	r.Leaks = append(r.Leaks, CheckReportLeak{
+		Key:        b.Key,
+		Size:       b.Size,
+		ModifiedAt: b.ModifiedAt,
+	})
+	r.Summary.LeaksCount++
+	r.Summary.LeakedBytes += b.Size
+}
+
+func (r *CheckReport) addLost(b models.Blob, actualSize uint64, reason string) {
+	r.Lost = append(r.Lost, CheckReportLost{
+		Checksum:   b.Checksum,
+		Size:       b.Size,
+		ActualSize: actualSize,
+		Reason:     reason,
+		IsBroken:   b.IsBroken,
+	})
+	r.Summary.LostCount++
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/blob"
+	"github.com/teran/archived/repositories/metadata"
+)
+
+// emptyChecksum is SHA256 of zero-length data: empty blobs are not read
+// from the storage since S3 rejects ranged reads of empty objects
+const emptyChecksum = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+type Service interface {
+	Run(ctx context.Context) error
+	Check(ctx context.Context) error
+}
+
+type service struct {
+	cfg     *Config
+	limiter *rate.Limiter
+	tp      func() time.Time
+
+	blobsChecked      prometheus.Counter
+	bytesRead         prometheus.Counter
+	blobsFailed       *prometheus.CounterVec
+	lastPassCompleted prometheus.Gauge
+}
+
+func New(cfg *Config) (Service, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "error validating scrubber service configuration")
+	}
+
+	log.Infof("initializing scrubber service ...")
+
+	svc := &service{
+		cfg: cfg,
+		tp:  time.Now,
+
+		blobsChecked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "archived",
+			Subsystem: "scrubber",
+			Name:      "blobs_checked_total",
+			Help:      "Total amount of blobs checked",
+		}),
+
+		bytesRead: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "archived",
+			Subsystem: "scrubber",
+			Name:      "bytes_read_total",
+			Help:      "Total amount of bytes read from BLOB storage",
+		}),
+
+		blobsFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "archived",
+			Subsystem: "scrubber",
+			Name:      "blobs_failed_total",
+			Help:      "Total amount of blobs missing in BLOB storage or not matching recorded checksum or size",
+		}, []string{"status"}),
+
+		lastPassCompleted: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "archived",
+			Subsystem: "scrubber",
+			Name:      "last_pass_completed_timestamp_seconds",
+			Help:      "Timestamp of the last complete pass over all the blobs",
+		}),
+	}
+
+	if cfg.RateLimit > 0 {
+		svc.limiter = rate.NewLimiter(rate.Limit(cfg.RateLimit), int(min(cfg.RateLimit, 1<<20)))
+	}
+
+	for _, c := range []prometheus.Collector{
+		svc.blobsChecked,
+		svc.bytesRead,
+		svc.blobsFailed,
+		svc.lastPassCompleted,
+	} {
+		if err := cfg.Registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return svc, nil
+}
+
+func (s *service) Run(ctx context.Context) error {
+	for {
+		if err := s.scrub(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("error scrubbing blobs, the pass will be resumed later")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.cfg.Interval):
+		}
+	}
+}
+
+// scrub checks blobs starting from the stored cursor and resets the cursor
+// once all the blobs are checked so the next pass starts over
+func (s *service) scrub(ctx context.Context) error {
+	cursor, err := s.cfg.MdRepo.GetScrubCursor(ctx)
+	if err != nil && !errors.Is(err, metadata.ErrNotFound) {
+		return errors.Wrap(err, "error getting scrub cursor")
+	}
+
+	log.WithFields(log.Fields{
+		"cursor": cursor,
+	}).Info("running blobs scrubbing ...")
+
+	for {
+		blobs, err := s.cfg.MdRepo.ListBlobsAfter(ctx, cursor, s.cfg.BatchSize)
+		if err != nil {
+			return errors.Wrap(err, "error listing blobs")
+		}
+
+		for _, b := range blobs {
+			// pending blobs are not uploaded yet
+			if !b.IsConfirmed {
+				continue
+			}
+
+			if err := s.check(ctx, b); err != nil {
+				return errors.Wrapf(err, "error checking blob `%s`", b.Checksum)
+			}
+		}
+
+		if uint64(len(blobs)) < s.cfg.BatchSize {
+			break
+		}
+
+		cursor = blobs[len(blobs)-1].Checksum
+		if err := s.cfg.MdRepo.SetScrubCursor(ctx, cursor); err != nil {
+			return errors.Wrap(err, "error storing scrub cursor")
+		}
+	}
+
+	if err := s.cfg.MdRepo.SetScrubCursor(ctx, ""); err != nil {
+		return errors.Wrap(err, "error resetting scrub cursor")
+	}
+
+	s.lastPassCompleted.SetToCurrentTime()
+
+	log.Info("blobs scrubbing complete")
+
+	return nil
+}
+
+func (s *service) check(ctx context.Context, b models.Blob) error {
+	log.WithFields(log.Fields{
+		"checksum": b.Checksum,
+		"size":     b.Size,
+	}).Trace("checking blob ...")
+
+	size, err := s.cfg.BlobRepo.BlobSize(ctx, b.Checksum)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			// gc could remove the blob after it was listed so it's rechecked
+			// to be still present in metadata
+			err := s.cfg.MdRepo.EnsureBlobKey(ctx, b.Checksum, b.Size)
+			if errors.Is(err, metadata.ErrNotFound) || errors.Is(err, metadata.ErrDeleting) {
+				log.WithFields(log.Fields{
+					"checksum": b.Checksum,
+					"size":     b.Size,
+				}).Info("blob is deleted since it was listed, skipping ...")
+				return nil
+			}
+
+			if err != nil && !errors.Is(err, metadata.ErrNotConfirmed) {
+				return errors.Wrap(err, "error checking blob metadata")
+			}
+
+			return s.fail(ctx, models.ScrubResult{
+				Checksum:     b.Checksum,
+				Status:       models.ScrubStatusMissing,
+				ExpectedSize: b.Size,
+			})
+		}
+		return errors.Wrap(err, "error getting blob size")
+	}
+
+	checksum := emptyChecksum
+	if size > 0 {
+		checksum, size, err = s.hash(ctx, b.Checksum)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.blobsChecked.Inc()
+
+	if checksum != b.Checksum || size != b.Size {
+		return s.fail(ctx, models.ScrubResult{
+			Checksum:       b.Checksum,
+			Status:         models.ScrubStatusMismatch,
+			ExpectedSize:   b.Size,
+			ActualSize:     size,
+			ActualChecksum: checksum,
+		})
+	}
+	return nil
+}
+
+func (s *service) hash(ctx context.Context, key string) (string, uint64, error) {
+	rd, err := s.cfg.BlobRepo.GetBlob(ctx, key, 0, -1)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "error getting blob")
+	}
+	defer func() { _ = rd.Close() }()
+
+	h := sha256.New()
+	n, err := io.Copy(h, newRateLimitedReader(ctx, rd, s.limiter))
+	s.bytesRead.Add(float64(n))
+	if err != nil {
+		return "", 0, errors.Wrap(err, "error reading blob")
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), uint64(n), nil
+}
+
+func (s *service) fail(ctx context.Context, result models.ScrubResult) error {
+	log.WithFields(log.Fields{
+		"checksum":        result.Checksum,
+		"status":          result.Status,
+		"expected_size":   result.ExpectedSize,
+		"actual_size":     result.ActualSize,
+		"actual_checksum": result.ActualChecksum,
+	}).Error("blob integrity check failed")
+
+	s.blobsFailed.WithLabelValues(string(result.Status)).Inc()
+
+	if err := s.cfg.MdRepo.CreateScrubResult(ctx, result); err != nil {
+		return errors.Wrap(err, "error recording scrub result")
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/blob"
+	blobRepoMock "github.com/teran/archived/repositories/blob/mock"
+	"github.com/teran/archived/repositories/metadata"
+	repoMock "github.com/teran/archived/repositories/metadata/mock"
+)
+
+func init() {
+	log.SetLevel(log.TraceLevel)
+}
+
+func (s *serviceTestSuite) TestScrub() {
+	good := checksumOf("good data")
+
+	s.repoMock.On("GetScrubCursor").Return("", metadata.ErrNotFound).Once()
+	s.repoMock.On("ListBlobsAfter", "", uint64(2)).Return([]models.Blob{
+		{Checksum: emptyChecksum, Size: 0, IsConfirmed: true},
+		{Checksum: good, Size: 9, IsConfirmed: true},
+	}, nil).Once()
+	s.repoMock.On("SetScrubCursor", good).Return(nil).Once()
+	s.repoMock.On("ListBlobsAfter", good, uint64(2)).Return([]models.Blob{
+		{Checksum: "deadbeef", Size: 9, IsConfirmed: true},
+		{Checksum: "cafebabe", Size: 5, IsConfirmed: true},
+	}, nil).Once()
+	s.repoMock.On("SetScrubCursor", "cafebabe").Return(nil).Once()
+	s.repoMock.On("ListBlobsAfter", "cafebabe", uint64(2)).Return([]models.Blob{
+		{Checksum: "f00dbabe", Size: 5},
+	}, nil).Once()
+	s.repoMock.On("SetScrubCursor", "").Return(nil).Once()
+
+	s.blobRepoMock.On("BlobSize", emptyChecksum).Return(uint64(0), nil).Once()
+	s.blobRepoMock.On("BlobSize", good).Return(uint64(9), nil).Once()
+	s.blobRepoMock.On("GetBlob", good, int64(0), int64(-1)).Return(io.NopCloser(strings.NewReader("good data")), nil).Once()
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(9), nil).Once()
+	s.blobRepoMock.On("GetBlob", "deadbeef", int64(0), int64(-1)).Return(io.NopCloser(strings.NewReader("good data")), nil).Once()
+	s.blobRepoMock.On("BlobSize", "cafebabe").Return(uint64(0), blob.ErrNotFound).Once()
+	s.repoMock.On("EnsureBlobKey", "cafebabe", uint64(5)).Return(nil).Once()
+
+	s.repoMock.On("CreateScrubResult", models.ScrubResult{
+		Checksum:       "deadbeef",
+		Status:         models.ScrubStatusMismatch,
+		ExpectedSize:   9,
+		ActualSize:     9,
+		ActualChecksum: good,
+	}).Return(nil).Once()
+	s.repoMock.On("CreateScrubResult", models.ScrubResult{
+		Checksum:     "cafebabe",
+		Status:       models.ScrubStatusMissing,
+		ExpectedSize: 5,
+	}).Return(nil).Once()
+
+	err := s.svc.(*service).scrub(s.ctx)
+	s.Require().NoError(err)
+
+	s.Require().Equal(float64(3), testutil.ToFloat64(s.svc.(*service).blobsChecked))
+	s.Require().Equal(float64(18), testutil.ToFloat64(s.svc.(*service).bytesRead))
+	s.Require().Equal(float64(1), testutil.ToFloat64(s.svc.(*service).blobsFailed.WithLabelValues("mismatch")))
+	s.Require().Equal(float64(1), testutil.ToFloat64(s.svc.(*service).blobsFailed.WithLabelValues("missing")))
+}
+
+func (s *serviceTestSuite) TestScrubDeletedBlob() {
+	s.repoMock.On("GetScrubCursor").Return("", nil).Once()
+	s.repoMock.On("ListBlobsAfter", "", uint64(2)).Return([]models.Blob{
+		{Checksum: "deadbeef", Size: 9, IsConfirmed: true},
+		{Checksum: "cafebabe", Size: 5, IsConfirmed: true},
+	}, nil).Once()
+	s.repoMock.On("SetScrubCursor", "cafebabe").Return(nil).Once()
+	s.repoMock.On("ListBlobsAfter", "cafebabe", uint64(2)).Return([]models.Blob{}, nil).Once()
+	s.repoMock.On("SetScrubCursor", "").Return(nil).Once()
+
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(0), blob.ErrNotFound).Once()
+	s.blobRepoMock.On("BlobSize", "cafebabe").Return(uint64(0), blob.ErrNotFound).Once()
+	s.repoMock.On("EnsureBlobKey", "deadbeef", uint64(9)).Return(metadata.ErrNotFound).Once()
+	s.repoMock.On("EnsureBlobKey", "cafebabe", uint64(5)).Return(metadata.ErrDeleting).Once()
+
+	err := s.svc.(*service).scrub(s.ctx)
+	s.Require().NoError(err)
+
+	s.Require().Equal(float64(0), testutil.ToFloat64(s.svc.(*service).blobsFailed.WithLabelValues("missing")))
+}
+
+func (s *serviceTestSuite) TestScrubResume() {
+	s.repoMock.On("GetScrubCursor").Return("deadbeef", nil).Once()
+	s.repoMock.On("ListBlobsAfter", "deadbeef", uint64(2)).Return([]models.Blob{}, nil).Once()
+	s.repoMock.On("SetScrubCursor", "").Return(nil).Once()
+
+	err := s.svc.(*service).scrub(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestScrubStorageError() {
+	s.repoMock.On("GetScrubCursor").Return("", nil).Once()
+	s.repoMock.On("ListBlobsAfter", "", uint64(2)).Return([]models.Blob{
+		{Checksum: "deadbeef", Size: 9, IsConfirmed: true},
+	}, nil).Once()
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(0), errors.New("connection reset")).Once()
+
+	err := s.svc.(*service).scrub(s.ctx)
+	s.Require().Error(err)
+	s.Require().Equal("error checking blob `deadbeef`: error getting blob size: connection reset", err.Error())
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	r := require.New(t)
+
+	svc, err := New(&Config{
+		MdRepo:     repoMock.New(),
+		BlobRepo:   blobRepoMock.New(),
+		Registerer: prometheus.NewRegistry(),
+		BatchSize:  2,
+		RateLimit:  10,
+		Interval:   time.Hour,
+	})
+	r.NoError(err)
+
+	// burst allows the first 10 bytes right away, the rest takes a second
+	start := time.Now()
+	data, err := io.ReadAll(newRateLimitedReader(context.Background(), strings.NewReader("0123456789abcde"), svc.(*service).limiter))
+	r.NoError(err)
+	r.Equal("0123456789abcde", string(data))
+	r.GreaterOrEqual(time.Since(start), 400*time.Millisecond)
+}
+
+func checksumOf(data string) string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+// Definitions ...
+type serviceTestSuite struct {
+	suite.Suite
+
+	ctx          context.Context
+	svc          Service
+	cfg          *Config
+	report       *bytes.Buffer
+	repoMock     *repoMock.Mock
+	blobRepoMock *blobRepoMock.Mock
+}
+
+func (s *serviceTestSuite) SetupTest() {
+	s.ctx = context.TODO()
+
+	s.repoMock = repoMock.New()
+	s.blobRepoMock = blobRepoMock.New()
+
+	s.report = &bytes.Buffer{}
+	s.cfg = &Config{
+		MdRepo:          s.repoMock,
+		BlobRepo:        s.blobRepoMock,
+		Registerer:      prometheus.NewRegistry(),
+		BatchSize:       2,
+		Interval:        time.Hour,
+		LeakGracePeriod: time.Hour,
+		ReportWriter:    s.report,
+	}
+
+	var err error
+	s.svc, err = New(s.cfg)
+	s.Require().NoError(err)
+
+	s.svc.(*service).tp = func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+}
+
+func (s *serviceTestSuite) TearDownTest() {
+	s.repoMock.AssertExpectations(s.T())
+	s.blobRepoMock.AssertExpectations(s.T())
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, &serviceTestSuite{})
+}
@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/stretchr/testify/mock"
 
+	"github.com/teran/archived/archive"
 	"github.com/teran/archived/models"
 )
 
@@ -32,6 +34,16 @@ func (m *Mock) ListNamespaces(ctx context.Context) ([]string, error) {
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *Mock) ListPrivateNamespaces(ctx context.Context) ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *Mock) SetNamespacePrivate(_ context.Context, name string, isPrivate bool) error {
+	args := m.Called(name, isPrivate)
+	return args.Error(0)
+}
+
 func (m *Mock) RenameNamespace(ctx context.Context, oldName, newName string) error {
 	args := m.Called(oldName, newName)
 	return args.Error(0)
@@ -57,8 +69,8 @@ func (m *Mock) RenameContainer(_ context.Context, namespace, oldName, newName st
 	return args.Error(0)
 }
 
-func (m *Mock) SetContainerParameters(_ context.Context, namespace, name string, ttl time.Duration) error {
-	args := m.Called(namespace, name, ttl)
+func (m *Mock) SetContainerParameters(_ context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error {
+	args := m.Called(namespace, name, ttl, retention)
 	return args.Error(0)
 }
 
@@ -82,6 +94,11 @@ func (m *Mock) CreateVersion(_ context.Context, namespace, container string) (id
 	return args.String(0), args.Error(1)
 }
 
+func (m *Mock) CloneVersion(_ context.Context, namespace, container, versionID, destNamespace, destContainer string) (id string, err error) {
+	args := m.Called(namespace, container, versionID, destNamespace, destContainer)
+	return args.String(0), args.Error(1)
+}
+
 func (m *Mock) ListAllVersions(_ context.Context, namespace, container string) ([]models.Version, error) {
 	args := m.Called(namespace, container)
 	return args.Get(0).([]models.Version), args.Error(1)
@@ -92,6 +109,16 @@ func (m *Mock) ListPublishedVersions(_ context.Context, namespace, container str
 	return args.Get(0).([]models.Version), args.Error(1)
 }
 
+func (m *Mock) GetVersion(_ context.Context, namespace, container, versionID string) (models.Version, error) {
+	args := m.Called(namespace, container, versionID)
+	return args.Get(0).(models.Version), args.Error(1)
+}
+
+func (m *Mock) DiffVersions(_ context.Context, namespace, container, fromVersionID, toVersionID string) (models.VersionDiff, error) {
+	args := m.Called(namespace, container, fromVersionID, toVersionID)
+	return args.Get(0).(models.VersionDiff), args.Error(1)
+}
+
 func (m *Mock) ListPublishedVersionsByPage(_ context.Context, namespace, container string, pageNum uint64) (uint64, []models.Version, error) {
 	args := m.Called(namespace, container, pageNum)
 	return args.Get(0).(uint64), args.Get(1).([]models.Version), args.Error(2)
@@ -107,24 +134,84 @@ func (m *Mock) DeleteVersion(_ context.Context, namespace, container, id string)
 	return args.Error(0)
 }
 
+func (m *Mock) ProtectVersion(_ context.Context, namespace, container, id string) error {
+	args := m.Called(namespace, container, id)
+	return args.Error(0)
+}
+
+func (m *Mock) UnprotectVersion(_ context.Context, namespace, container, id string) error {
+	args := m.Called(namespace, container, id)
+	return args.Error(0)
+}
+
+func (m *Mock) CreateAlias(_ context.Context, namespace, container, name, versionID string) error {
+	args := m.Called(namespace, container, name, versionID)
+	return args.Error(0)
+}
+
+func (m *Mock) MoveAlias(_ context.Context, namespace, container, name, versionID string) error {
+	args := m.Called(namespace, container, name, versionID)
+	return args.Error(0)
+}
+
+func (m *Mock) DeleteAlias(_ context.Context, namespace, container, name string) error {
+	args := m.Called(namespace, container, name)
+	return args.Error(0)
+}
+
+func (m *Mock) ListAliases(_ context.Context, namespace, container string) ([]models.Alias, error) {
+	args := m.Called(namespace, container)
+	return args.Get(0).([]models.Alias), args.Error(1)
+}
+
 func (m *Mock) AddObject(_ context.Context, namespace, container, versionID, key, casKey string) error {
 	args := m.Called(namespace, container, versionID, key, casKey)
 	return args.Error(0)
 }
 
+func (m *Mock) CreateObjects(_ context.Context, namespace, container, versionID string, objects []models.Object) (map[string]string, error) {
+	args := m.Called(namespace, container, versionID, objects)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *Mock) EnsureBLOBPresenceOrGetUploadURL(ctx context.Context, checksum string, size uint64, mimeType string) (string, error) {
 	args := m.Called(checksum, size, mimeType)
 	return args.String(0), args.Error(1)
 }
 
-func (m *Mock) ListObjects(_ context.Context, namespace, container, versionID string) ([]string, error) {
+func (m *Mock) ConfirmBLOB(_ context.Context, checksum string) error {
+	args := m.Called(checksum)
+	return args.Error(0)
+}
+
+func (m *Mock) CreateMultipartUpload(_ context.Context, checksum string, size uint64) (models.MultipartUpload, error) {
+	args := m.Called(checksum, size)
+	return args.Get(0).(models.MultipartUpload), args.Error(1)
+}
+
+func (m *Mock) CompleteMultipartUpload(_ context.Context, checksum, uploadID string, parts []models.UploadPart) error {
+	args := m.Called(checksum, uploadID, parts)
+	return args.Error(0)
+}
+
+func (m *Mock) AbortMultipartUpload(_ context.Context, checksum, uploadID string) error {
+	args := m.Called(checksum, uploadID)
+	return args.Error(0)
+}
+
+func (m *Mock) ListObjects(_ context.Context, namespace, container, versionID string) ([]models.Object, error) {
 	args := m.Called(namespace, container, versionID)
-	return args.Get(0).([]string), args.Error(1)
+	return args.Get(0).([]models.Object), args.Error(1)
+}
+
+func (m *Mock) VerifyBLOBs(_ context.Context, namespace, container, versionID string) (map[string]string, error) {
+	args := m.Called(namespace, container, versionID)
+	return args.Get(0).(map[string]string), args.Error(1)
 }
 
-func (m *Mock) ListObjectsByPage(_ context.Context, namespace, container, versionID string, pageNum uint64) (uint64, []string, error) {
+func (m *Mock) ListObjectsByPage(_ context.Context, namespace, container, versionID string, pageNum uint64) (uint64, []models.Object, error) {
 	args := m.Called(namespace, container, versionID, pageNum)
-	return args.Get(0).(uint64), args.Get(1).([]string), args.Error(2)
+	return args.Get(0).(uint64), args.Get(1).([]models.Object), args.Error(2)
 }
 
 func (m *Mock) GetObjectURL(ctx context.Context, namespace, container, versionID, key string) (string, error) {
@@ -132,7 +219,28 @@ func (m *Mock) GetObjectURL(ctx context.Context, namespace, container, versionID
 	return args.String(0), args.Error(1)
 }
 
+func (m *Mock) GetObject(_ context.Context, namespace, container, versionID, key string) (models.Blob, io.ReadSeekCloser, error) {
+	args := m.Called(namespace, container, versionID, key)
+	rsc, _ := args.Get(1).(io.ReadSeekCloser)
+	return args.Get(0).(models.Blob), rsc, args.Error(2)
+}
+
+func (m *Mock) GetBlobURL(_ context.Context, blob models.Blob, key string) (string, error) {
+	args := m.Called(blob, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *Mock) ExportVersion(_ context.Context, namespace, container, versionID string, w archive.Writer) error {
+	args := m.Called(namespace, container, versionID, w)
+	return args.Error(0)
+}
+
 func (m *Mock) DeleteObject(_ context.Context, namespace, container, versionID, key string) error {
 	args := m.Called(namespace, container, versionID, key)
 	return args.Error(0)
 }
+
+func (m *Mock) ListAuditEvents(_ context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/teran/archived/repositories/blob"
+)
+
+var _ io.ReadSeekCloser = (*blobReader)(nil)
+
+// blobReader implements io.ReadSeekCloser on top of blob repository
+// to allow serving HTTP range requests. The underlying blob reader is
+// opened lazily at current offset on first read after seek
+type blobReader struct {
+	ctx    context.Context
+	repo   blob.Repository
+	key    string
+	size   int64
+	offset int64
+	rc     io.ReadCloser
+}
+
+func newBlobReader(ctx context.Context, repo blob.Repository, key string, size int64) *blobReader {
+	return &blobReader{
+		ctx:  ctx,
+		repo: repo,
+		key:  key,
+		size: size,
+	}
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.rc == nil {
+		rc, err := r.repo.GetBlob(r.ctx, r.key, r.offset, r.size-r.offset)
+		if err != nil {
+			return 0, errors.Wrap(err, "error getting blob")
+		}
+		r.rc = rc
+	}
+
+	n, err := r.rc.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *blobReader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.offset + offset
+	case io.SeekEnd:
+		pos = r.size + offset
+	default:
+		return 0, errors.Errorf("invalid whence: %d", whence)
+	}
+
+	if pos < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if pos != r.offset {
+		if err := r.Close(); err != nil {
+			return 0, err
+		}
+		r.offset = pos
+	}
+	return pos, nil
+}
+
+func (r *blobReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
@@ -2,17 +2,45 @@ package service
 
 import (
 	"context"
+	"io"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/teran/archived/archive"
 	"github.com/teran/archived/models"
 	"github.com/teran/archived/repositories/blob"
 	"github.com/teran/archived/repositories/metadata"
 )
 
-var ErrNotFound = errors.New("entity not found")
+const (
+	latestVersionName = "latest"
+
+	listObjectsPageSize uint64 = 1000
+
+	// S3 allows up to 10000 parts per multipart upload so the part size is
+	// increased for the BLOBs which don't fit into that with the default one
+	multipartPartSize uint64 = 64 << 20
+	multipartMaxParts uint64 = 10000
+)
+
+var (
+	ErrNotFound        = errors.New("entity not found")
+	ErrProtected       = errors.New("entity is protected")
+	ErrAliased         = errors.New("version is referenced by alias")
+	ErrNotPublished    = errors.New("version is not published")
+	ErrConflict        = errors.New("entity already exists")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrNotSupported    = errors.New("operation is not supported")
+	ErrNotConfirmed    = errors.New("BLOB upload is not confirmed")
+	ErrBroken          = errors.New("BLOB data is lost")
+	ErrDeleting        = errors.New("BLOB is being deleted, retry later")
+
+	versionNameRegexp = regexp.MustCompile(`^[0-9]{14}$`)
+	aliasNameRegexp   = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,254}$`)
+)
 
 type Manager interface {
 	Publisher
@@ -20,36 +48,72 @@ type Manager interface {
 	CreateNamespace(ctx context.Context, name string) error
 	RenameNamespace(ctx context.Context, oldName, newName string) error
 	DeleteNamespace(ctx context.Context, name string) error
+	SetNamespacePrivate(ctx context.Context, name string, isPrivate bool) error
 
 	CreateContainer(ctx context.Context, namespace, name string, ttl time.Duration) error
 	MoveContainer(ctx context.Context, namespace, container, destNamespace string) error
 	RenameContainer(ctx context.Context, namespace, oldName, newName string) error
 	DeleteContainer(ctx context.Context, namespace, name string) error
-	SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration) error
+	SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error
 
 	CreateVersion(ctx context.Context, namespace, container string) (id string, err error)
+	CloneVersion(ctx context.Context, namespace, container, versionID, destNamespace, destContainer string) (id string, err error)
 	ListAllVersions(ctx context.Context, namespace, container string) ([]models.Version, error)
 	PublishVersion(ctx context.Context, namespace, container, id string) error
 	DeleteVersion(ctx context.Context, namespace, container, id string) error
+	ProtectVersion(ctx context.Context, namespace, container, id string) error
+	UnprotectVersion(ctx context.Context, namespace, container, id string) error
+
+	CreateAlias(ctx context.Context, namespace, container, name, versionID string) error
+	MoveAlias(ctx context.Context, namespace, container, name, versionID string) error
+	DeleteAlias(ctx context.Context, namespace, container, name string) error
+	ListAliases(ctx context.Context, namespace, container string) ([]models.Alias, error)
 
 	AddObject(ctx context.Context, namespace, container, versionID, key string, casKey string) error
-	ListObjects(ctx context.Context, namespace, container, versionID string) ([]string, error)
+	// CreateObjects creates objects in batch and returns upload URLs by
+	// checksum for the blobs which are not stored yet
+	CreateObjects(ctx context.Context, namespace, container, versionID string, objects []models.Object) (map[string]string, error)
+	ListObjects(ctx context.Context, namespace, container, versionID string) ([]models.Object, error)
+	// VerifyBLOBs checks the blobs referenced by the version objects are
+	// actually stored, confirms the ones which are and returns upload URLs
+	// by checksum for missing ones
+	VerifyBLOBs(ctx context.Context, namespace, container, versionID string) (map[string]string, error)
 	DeleteObject(ctx context.Context, namespace, container, versionID, key string) error
 
 	EnsureBLOBPresenceOrGetUploadURL(ctx context.Context, checksum string, size uint64, mimeType string) (string, error)
+	// ConfirmBLOB checks the BLOB data is stored with the expected size and
+	// marks the BLOB as available to be referenced by published versions
+	ConfirmBLOB(ctx context.Context, checksum string) error
+	// CreateMultipartUpload initiates multipart upload for the BLOB which
+	// metadata is already created and returns upload URLs for its parts
+	CreateMultipartUpload(ctx context.Context, checksum string, size uint64) (models.MultipartUpload, error)
+	CompleteMultipartUpload(ctx context.Context, checksum, uploadID string, parts []models.UploadPart) error
+	AbortMultipartUpload(ctx context.Context, checksum, uploadID string) error
+
+	ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, error)
 }
 
 type Publisher interface {
 	ListNamespaces(ctx context.Context) ([]string, error)
+	ListPrivateNamespaces(ctx context.Context) ([]string, error)
 
 	ListContainers(ctx context.Context, namespace string) ([]models.Container, error)
 	ListContainersByPage(ctx context.Context, namespace string, pageNum uint64) (uint64, []models.Container, error)
 
 	ListPublishedVersions(ctx context.Context, namespace, container string) ([]models.Version, error)
 	ListPublishedVersionsByPage(ctx context.Context, namespace, container string, pageNum uint64) (uint64, []models.Version, error)
+	GetVersion(ctx context.Context, namespace, container, versionID string) (models.Version, error)
+	DiffVersions(ctx context.Context, namespace, container, fromVersionID, toVersionID string) (models.VersionDiff, error)
 
-	ListObjectsByPage(ctx context.Context, namespace, container, versionID string, pageNum uint64) (uint64, []string, error)
+	ListObjectsByPage(ctx context.Context, namespace, container, versionID string, pageNum uint64) (uint64, []models.Object, error)
 	GetObjectURL(ctx context.Context, namespace, container, versionID, key string) (string, error)
+	GetObject(ctx context.Context, namespace, container, versionID, key string) (models.Blob, io.ReadSeekCloser, error)
+	// GetBlobURL returns link to the data of the blob returned by GetObject
+	// without looking it up in metadata again
+	GetBlobURL(ctx context.Context, blob models.Blob, key string) (string, error)
+	// ExportVersion writes all the version objects to the archive reading
+	// their data from BLOB storage one by one
+	ExportVersion(ctx context.Context, namespace, container, versionID string, w archive.Writer) error
 }
 
 type service struct {
@@ -99,11 +163,21 @@ func (s *service) ListNamespaces(ctx context.Context) ([]string, error) {
 	return containers, mapMetadataErrors(err)
 }
 
+func (s *service) ListPrivateNamespaces(ctx context.Context) ([]string, error) {
+	namespaces, err := s.mdRepo.ListPrivateNamespaces(ctx)
+	return namespaces, mapMetadataErrors(err)
+}
+
 func (s *service) DeleteNamespace(ctx context.Context, name string) error {
 	err := s.mdRepo.DeleteNamespace(ctx, name)
 	return mapMetadataErrors(err)
 }
 
+func (s *service) SetNamespacePrivate(ctx context.Context, name string, isPrivate bool) error {
+	err := s.mdRepo.SetNamespacePrivate(ctx, name, isPrivate)
+	return mapMetadataErrors(err)
+}
+
 func (s *service) CreateContainer(ctx context.Context, namespace, name string, ttl time.Duration) error {
 	err := s.mdRepo.CreateContainer(ctx, namespace, name, ttl)
 	if err != nil {
@@ -128,8 +202,8 @@ func (s *service) RenameContainer(ctx context.Context, namespace, oldName, newNa
 	return nil
 }
 
-func (s *service) SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration) error {
-	err := s.mdRepo.SetContainerParameters(ctx, namespace, name, ttl)
+func (s *service) SetContainerParameters(ctx context.Context, namespace, name string, ttl time.Duration, retention *models.RetentionPolicy) error {
+	err := s.mdRepo.SetContainerParameters(ctx, namespace, name, ttl, retention)
 	if err != nil {
 		return mapMetadataErrors(err)
 	}
@@ -171,6 +245,24 @@ func (s *service) CreateVersion(ctx context.Context, namespace, container string
 	return version, mapMetadataErrors(err)
 }
 
+func (s *service) CloneVersion(ctx context.Context, namespace, container, versionID, destNamespace, destContainer string) (id string, err error) {
+	versionID, err = s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return "", err
+	}
+
+	if destNamespace == "" {
+		destNamespace = namespace
+	}
+
+	if destContainer == "" {
+		destContainer = container
+	}
+
+	version, err := s.mdRepo.CloneVersion(ctx, namespace, container, versionID, destNamespace, destContainer)
+	return version, mapMetadataErrors(err)
+}
+
 func (s *service) ListPublishedVersions(ctx context.Context, namespace, container string) ([]models.Version, error) {
 	versions, err := s.mdRepo.ListPublishedVersionsByContainer(ctx, namespace, container)
 	return versions, mapMetadataErrors(err)
@@ -196,6 +288,31 @@ func (s *service) ListPublishedVersionsByPage(ctx context.Context, namespace, co
 	return totalPages, versions, mapMetadataErrors(err)
 }
 
+func (s *service) GetVersion(ctx context.Context, namespace, container, versionID string) (models.Version, error) {
+	versionID, err := s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return models.Version{}, err
+	}
+
+	v, err := s.mdRepo.GetVersion(ctx, namespace, container, versionID)
+	return v, mapMetadataErrors(err)
+}
+
+func (s *service) DiffVersions(ctx context.Context, namespace, container, fromVersionID, toVersionID string) (models.VersionDiff, error) {
+	fromVersionID, err := s.resolveVersion(ctx, namespace, container, fromVersionID)
+	if err != nil {
+		return models.VersionDiff{}, err
+	}
+
+	toVersionID, err = s.resolveVersion(ctx, namespace, container, toVersionID)
+	if err != nil {
+		return models.VersionDiff{}, err
+	}
+
+	diff, err := s.mdRepo.DiffVersions(ctx, namespace, container, fromVersionID, toVersionID)
+	return diff, mapMetadataErrors(err)
+}
+
 func (s *service) ListAllVersions(ctx context.Context, namespace, container string) ([]models.Version, error) {
 	versions, err := s.mdRepo.ListAllVersionsByContainer(ctx, namespace, container)
 	return versions, mapMetadataErrors(err)
@@ -203,6 +320,17 @@ func (s *service) ListAllVersions(ctx context.Context, namespace, container stri
 
 func (s *service) PublishVersion(ctx context.Context, namespace, container, id string) error {
 	err := s.mdRepo.MarkVersionPublished(ctx, namespace, container, id)
+	if !errors.Is(err, metadata.ErrNotConfirmed) {
+		return mapMetadataErrors(err)
+	}
+
+	// Clients without BLOB confirmation support never call ConfirmBLOB so
+	// the BLOBs already present in the storage are confirmed by their size
+	if _, err := s.VerifyBLOBs(ctx, namespace, container, id); err != nil {
+		return err
+	}
+
+	err = s.mdRepo.MarkVersionPublished(ctx, namespace, container, id)
 	return mapMetadataErrors(err)
 }
 
@@ -211,24 +339,142 @@ func (s *service) DeleteVersion(ctx context.Context, namespace, container, id st
 	return mapMetadataErrors(err)
 }
 
+func (s *service) ProtectVersion(ctx context.Context, namespace, container, id string) error {
+	err := s.mdRepo.SetVersionProtected(ctx, namespace, container, id, true)
+	return mapMetadataErrors(err)
+}
+
+func (s *service) UnprotectVersion(ctx context.Context, namespace, container, id string) error {
+	err := s.mdRepo.SetVersionProtected(ctx, namespace, container, id, false)
+	return mapMetadataErrors(err)
+}
+
+func (s *service) CreateAlias(ctx context.Context, namespace, container, name, versionID string) error {
+	if err := validateAliasName(name); err != nil {
+		return err
+	}
+
+	versionID, err := s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return err
+	}
+
+	err = s.mdRepo.CreateAlias(ctx, namespace, container, name, versionID)
+	return mapMetadataErrors(err)
+}
+
+func (s *service) MoveAlias(ctx context.Context, namespace, container, name, versionID string) error {
+	if err := validateAliasName(name); err != nil {
+		return err
+	}
+
+	versionID, err := s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return err
+	}
+
+	err = s.mdRepo.MoveAlias(ctx, namespace, container, name, versionID)
+	return mapMetadataErrors(err)
+}
+
+func (s *service) DeleteAlias(ctx context.Context, namespace, container, name string) error {
+	err := s.mdRepo.DeleteAlias(ctx, namespace, container, name)
+	return mapMetadataErrors(err)
+}
+
+func (s *service) ListAliases(ctx context.Context, namespace, container string) ([]models.Alias, error) {
+	aliases, err := s.mdRepo.ListAliases(ctx, namespace, container)
+	return aliases, mapMetadataErrors(err)
+}
+
 func (s *service) AddObject(ctx context.Context, namespace, container, versionID, key, casKey string) error {
 	err := s.mdRepo.CreateObject(ctx, namespace, container, versionID, strings.TrimPrefix(key, "/"), casKey)
 	return mapMetadataErrors(err)
 }
 
-func (s *service) ListObjects(ctx context.Context, namespace, container, versionID string) ([]string, error) {
-	_, objects, err := s.mdRepo.ListObjects(ctx, namespace, container, versionID, 0, 1000)
-	return objects, mapMetadataErrors(err)
+func (s *service) CreateObjects(ctx context.Context, namespace, container, versionID string, objects []models.Object) (map[string]string, error) {
+	objs := make([]models.Object, 0, len(objects))
+	for _, o := range objects {
+		o.Key = strings.TrimPrefix(o.Key, "/")
+		objs = append(objs, o)
+	}
+
+	created, err := s.mdRepo.CreateObjects(ctx, namespace, container, versionID, objs)
+	if err != nil {
+		return nil, mapMetadataErrors(err)
+	}
+
+	urls := map[string]string{}
+	for _, checksum := range created {
+		url, err := s.blobRepo.PutBlobURL(ctx, checksum)
+		if err != nil {
+			return nil, err
+		}
+		urls[checksum] = url
+	}
+	return urls, nil
 }
 
-func (s *service) ListObjectsByPage(ctx context.Context, namespace, container, versionID string, pageNum uint64) (uint64, []string, error) {
-	var err error
-	if versionID == "latest" {
-		versionID, err = s.mdRepo.GetLatestPublishedVersionByContainer(ctx, namespace, container)
+func (s *service) ListObjects(ctx context.Context, namespace, container, versionID string) ([]models.Object, error) {
+	versionID, err := s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []models.Object{}
+	for {
+		_, page, err := s.mdRepo.ListObjects(ctx, namespace, container, versionID, uint64(len(objects)), listObjectsPageSize)
 		if err != nil {
-			return 0, nil, mapMetadataErrors(err)
+			return nil, mapMetadataErrors(err)
+		}
+
+		objects = append(objects, page...)
+		if uint64(len(page)) < listObjectsPageSize {
+			return objects, nil
 		}
 	}
+}
+
+func (s *service) VerifyBLOBs(ctx context.Context, namespace, container, versionID string) (map[string]string, error) {
+	objects, err := s.ListObjects(ctx, namespace, container, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := map[string]string{}
+	checked := map[string]struct{}{}
+	for _, o := range objects {
+		if _, ok := checked[o.Checksum]; ok {
+			continue
+		}
+		checked[o.Checksum] = struct{}{}
+
+		size, err := s.blobRepo.BlobSize(ctx, o.Checksum)
+		if err != nil && !errors.Is(err, blob.ErrNotFound) {
+			return nil, errors.Wrap(err, "error checking BLOB presence")
+		}
+
+		if err == nil && size == o.Size {
+			if err := s.mdRepo.ConfirmBlob(ctx, o.Checksum, size); err != nil {
+				return nil, mapMetadataErrors(err)
+			}
+			continue
+		}
+
+		url, err := s.blobRepo.PutBlobURL(ctx, o.Checksum)
+		if err != nil {
+			return nil, err
+		}
+		urls[o.Checksum] = url
+	}
+	return urls, nil
+}
+
+func (s *service) ListObjectsByPage(ctx context.Context, namespace, container, versionID string, pageNum uint64) (uint64, []models.Object, error) {
+	versionID, err := s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return 0, nil, err
+	}
 
 	if pageNum < 1 {
 		pageNum = 1
@@ -250,12 +496,9 @@ func (s *service) ListObjectsByPage(ctx context.Context, namespace, container, v
 }
 
 func (s *service) GetObjectURL(ctx context.Context, namespace, container, versionID, key string) (string, error) {
-	var err error
-	if versionID == "latest" {
-		versionID, err = s.mdRepo.GetLatestPublishedVersionByContainer(ctx, namespace, container)
-		if err != nil {
-			return "", mapMetadataErrors(err)
-		}
+	versionID, err := s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return "", err
 	}
 
 	blob, err := s.mdRepo.GetBlobByObject(ctx, namespace, container, versionID, key)
@@ -263,15 +506,68 @@ func (s *service) GetObjectURL(ctx context.Context, namespace, container, versio
 		return "", mapMetadataErrors(err)
 	}
 
+	if blob.IsBroken {
+		return "", ErrBroken
+	}
+
+	return s.GetBlobURL(ctx, blob, key)
+}
+
+func (s *service) GetBlobURL(ctx context.Context, blob models.Blob, key string) (string, error) {
 	return s.blobRepo.GetBlobURL(ctx, blob.Checksum, blob.MimeType, key)
 }
 
+func (s *service) GetObject(ctx context.Context, namespace, container, versionID, key string) (models.Blob, io.ReadSeekCloser, error) {
+	versionID, err := s.resolveVersion(ctx, namespace, container, versionID)
+	if err != nil {
+		return models.Blob{}, nil, err
+	}
+
+	blob, err := s.mdRepo.GetBlobByObject(ctx, namespace, container, versionID, key)
+	if err != nil {
+		return models.Blob{}, nil, mapMetadataErrors(err)
+	}
+
+	if blob.IsBroken {
+		return models.Blob{}, nil, ErrBroken
+	}
+
+	return blob, newBlobReader(ctx, s.blobRepo, blob.Checksum, int64(blob.Size)), nil
+}
+
+func (s *service) ExportVersion(ctx context.Context, namespace, container, versionID string, w archive.Writer) error {
+	objects, err := s.ListObjects(ctx, namespace, container, versionID)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objects {
+		rd := newBlobReader(ctx, s.blobRepo, o.Checksum, int64(o.Size))
+		err := w.WriteFile(archive.File{
+			Name:    o.Key,
+			Size:    o.Size,
+			ModTime: o.CreatedAt,
+		}, rd)
+		_ = rd.Close()
+		if err != nil {
+			return errors.Wrapf(err, "error exporting object `%s`", o.Key)
+		}
+	}
+	return nil
+}
+
 func (s *service) EnsureBLOBPresenceOrGetUploadURL(ctx context.Context, checksum string, size uint64, mimeType string) (string, error) {
 	err := s.mdRepo.EnsureBlobKey(ctx, checksum, size)
 	if err == nil {
 		return "", nil
 	}
 
+	// previous upload attempt has never been confirmed so the data
+	// has to be uploaded again
+	if errors.Is(err, metadata.ErrNotConfirmed) {
+		return s.blobRepo.PutBlobURL(ctx, checksum)
+	}
+
 	if errors.Is(err, metadata.ErrNotFound) {
 		url, err := s.blobRepo.PutBlobURL(ctx, checksum)
 		if err != nil {
@@ -280,7 +576,65 @@ func (s *service) EnsureBLOBPresenceOrGetUploadURL(ctx context.Context, checksum
 		return url, s.mdRepo.CreateBLOB(ctx, checksum, size, mimeType)
 	}
 
-	return "", err
+	return "", mapMetadataErrors(err)
+}
+
+func (s *service) ConfirmBLOB(ctx context.Context, checksum string) error {
+	size, err := s.blobRepo.BlobSize(ctx, checksum)
+	if err != nil {
+		return mapBlobErrors(err)
+	}
+
+	return mapMetadataErrors(s.mdRepo.ConfirmBlob(ctx, checksum, size))
+}
+
+func (s *service) CreateMultipartUpload(ctx context.Context, checksum string, size uint64) (models.MultipartUpload, error) {
+	if size == 0 {
+		return models.MultipartUpload{}, errors.Wrap(ErrInvalidArgument, "multipart upload of empty BLOB")
+	}
+
+	if err := s.mdRepo.EnsureBlobKey(ctx, checksum, size); err != nil && !errors.Is(err, metadata.ErrNotConfirmed) {
+		return models.MultipartUpload{}, mapMetadataErrors(err)
+	}
+
+	uploadID, err := s.blobRepo.CreateMultipartUpload(ctx, checksum)
+	if err != nil {
+		return models.MultipartUpload{}, mapBlobErrors(err)
+	}
+
+	partSize := multipartPartSize
+	if size > partSize*multipartMaxParts {
+		partSize = (size + multipartMaxParts - 1) / multipartMaxParts
+	}
+
+	upload := models.MultipartUpload{
+		UploadID: uploadID,
+		PartSize: partSize,
+	}
+	for partNumber := int32(1); uint64(partNumber-1)*partSize < size; partNumber++ {
+		url, err := s.blobRepo.PutBlobPartURL(ctx, checksum, uploadID, partNumber)
+		if err != nil {
+			return models.MultipartUpload{}, mapBlobErrors(err)
+		}
+		upload.PartURLs = append(upload.PartURLs, url)
+	}
+	return upload, nil
+}
+
+func (s *service) CompleteMultipartUpload(ctx context.Context, checksum, uploadID string, parts []models.UploadPart) error {
+	if len(parts) == 0 {
+		return errors.Wrap(ErrInvalidArgument, "no parts to complete multipart upload with")
+	}
+
+	if err := s.blobRepo.CompleteMultipartUpload(ctx, checksum, uploadID, parts); err != nil {
+		return mapBlobErrors(err)
+	}
+
+	return s.ConfirmBLOB(ctx, checksum)
+}
+
+func (s *service) AbortMultipartUpload(ctx context.Context, checksum, uploadID string) error {
+	return mapBlobErrors(s.blobRepo.AbortMultipartUpload(ctx, checksum, uploadID))
 }
 
 func (s *service) DeleteObject(ctx context.Context, namespace, container, versionID, key string) error {
@@ -288,10 +642,58 @@ func (s *service) DeleteObject(ctx context.Context, namespace, container, versio
 	return mapMetadataErrors(err)
 }
 
+func (s *service) ListAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEvent, error) {
+	events, err := s.mdRepo.ListAuditEvents(ctx, filter)
+	return events, mapMetadataErrors(err)
+}
+
+func (s *service) resolveVersion(ctx context.Context, namespace, container, versionID string) (string, error) {
+	switch {
+	case versionID == latestVersionName:
+		v, err := s.mdRepo.GetLatestPublishedVersionByContainer(ctx, namespace, container)
+		return v, mapMetadataErrors(err)
+	case versionNameRegexp.MatchString(versionID):
+		return versionID, nil
+	default:
+		v, err := s.mdRepo.ResolveAlias(ctx, namespace, container, versionID)
+		return v, mapMetadataErrors(err)
+	}
+}
+
+func validateAliasName(name string) error {
+	if name == latestVersionName || versionNameRegexp.MatchString(name) || !aliasNameRegexp.MatchString(name) {
+		return errors.Wrapf(ErrInvalidArgument, "invalid alias name `%s`", name)
+	}
+	return nil
+}
+
+func mapBlobErrors(err error) error {
+	switch {
+	case errors.Is(err, blob.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, blob.ErrNotSupported):
+		return ErrNotSupported
+	default:
+		return err
+	}
+}
+
 func mapMetadataErrors(err error) error {
 	switch {
 	case errors.Is(err, metadata.ErrNotFound):
 		return ErrNotFound
+	case errors.Is(err, metadata.ErrProtected):
+		return ErrProtected
+	case errors.Is(err, metadata.ErrAliased):
+		return ErrAliased
+	case errors.Is(err, metadata.ErrNotPublished):
+		return ErrNotPublished
+	case errors.Is(err, metadata.ErrConflict):
+		return ErrConflict
+	case errors.Is(err, metadata.ErrNotConfirmed):
+		return ErrNotConfirmed
+	case errors.Is(err, metadata.ErrDeleting):
+		return ErrDeleting
 	default:
 		return err
 	}
@@ -1,14 +1,21 @@
 package service
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/teran/archived/archive"
 	"github.com/teran/archived/models"
+	"github.com/teran/archived/repositories/blob"
 	blobRepoMock "github.com/teran/archived/repositories/blob/mock"
 	"github.com/teran/archived/repositories/metadata"
 	mdRepoMock "github.com/teran/archived/repositories/metadata/mock"
@@ -51,6 +58,37 @@ func (s *serviceTestSuite) TestListNamespaces() {
 	s.Require().Equal("test error", err.Error())
 }
 
+func (s *serviceTestSuite) TestPrivateNamespaces() {
+	s.mdRepoMock.On("SetNamespacePrivate", "private-namespace", true).Return(nil).Once()
+
+	err := s.svc.SetNamespacePrivate(s.ctx, "private-namespace", true)
+	s.Require().NoError(err)
+
+	s.mdRepoMock.On("SetNamespacePrivate", "not-existent", true).Return(metadata.ErrNotFound).Once()
+
+	err = s.svc.SetNamespacePrivate(s.ctx, "not-existent", true)
+	s.Require().Error(err)
+	s.Require().ErrorIs(err, ErrNotFound)
+
+	s.mdRepoMock.On("ListPrivateNamespaces").Return([]string{"private-namespace"}, nil).Once()
+
+	namespaces, err := s.svc.ListPrivateNamespaces(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"private-namespace"}, namespaces)
+}
+
+func (s *serviceTestSuite) TestListAuditEvents() {
+	s.mdRepoMock.On("ListAuditEvents", models.AuditEventFilter{Actor: "john"}).Return([]models.AuditEvent{
+		{ID: 1, Actor: "john", Operation: "CreateNamespace", Namespace: "test-namespace"},
+	}, nil).Once()
+
+	events, err := s.svc.ListAuditEvents(s.ctx, models.AuditEventFilter{Actor: "john"})
+	s.Require().NoError(err)
+	s.Require().Equal([]models.AuditEvent{
+		{ID: 1, Actor: "john", Operation: "CreateNamespace", Namespace: "test-namespace"},
+	}, events)
+}
+
 func (s *serviceTestSuite) TestRenameNamespace() {
 	// Happy path
 	s.mdRepoMock.On("RenameNamespace", "old-name", "new-name").Return(nil).Once()
@@ -126,9 +164,9 @@ func (s *serviceTestSuite) TestDeleteContainer() {
 }
 
 func (s *serviceTestSuite) TestSetContainerParameters() {
-	s.mdRepoMock.On("SetContainerParameters", defaultNamespace, "container", 1*time.Hour).Return(nil).Once()
+	s.mdRepoMock.On("SetContainerParameters", defaultNamespace, "container", 1*time.Hour, &models.RetentionPolicy{KeepDaily: 7}).Return(nil).Once()
 
-	err := s.svc.SetContainerParameters(s.ctx, defaultNamespace, "container", 1*time.Hour)
+	err := s.svc.SetContainerParameters(s.ctx, defaultNamespace, "container", 1*time.Hour, &models.RetentionPolicy{KeepDaily: 7})
 	s.Require().NoError(err)
 }
 
@@ -140,11 +178,51 @@ func (s *serviceTestSuite) TestCreateVersion() {
 	s.Require().Equal("versionID", id)
 }
 
+func (s *serviceTestSuite) TestCloneVersion() {
+	s.mdRepoMock.On("ResolveAlias", defaultNamespace, "container", "stable").Return("20240102030405", nil).Once()
+	s.mdRepoMock.On("CloneVersion", defaultNamespace, "container", "20240102030405", defaultNamespace, "container").Return("20240102030406", nil).Once()
+
+	id, err := s.svc.CloneVersion(s.ctx, defaultNamespace, "container", "stable", "", "")
+	s.Require().NoError(err)
+	s.Require().Equal("20240102030406", id)
+
+	s.mdRepoMock.On("CloneVersion", defaultNamespace, "container", "20240102030405", "other", "container2").Return("", metadata.ErrNotFound).Once()
+
+	_, err = s.svc.CloneVersion(s.ctx, defaultNamespace, "container", "20240102030405", "other", "container2")
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotFound, err)
+}
+
 func (s *serviceTestSuite) TestPublishVersion() {
 	s.mdRepoMock.On("MarkVersionPublished", defaultNamespace, "container", "version").Return(nil).Once()
 
 	err := s.svc.PublishVersion(s.ctx, defaultNamespace, "container", "version")
 	s.Require().NoError(err)
+
+	// pending BLOB is uploaded but not confirmed
+	s.mdRepoMock.On("MarkVersionPublished", defaultNamespace, "container", "20240102030405").Return(metadata.ErrNotConfirmed).Once()
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(1), []models.Object{
+		{Key: "file.txt", Checksum: "checksum", Size: 10},
+	}, nil).Once()
+	s.blobRepoMock.On("BlobSize", "checksum").Return(uint64(10), nil).Once()
+	s.mdRepoMock.On("ConfirmBlob", "checksum", uint64(10)).Return(nil).Once()
+	s.mdRepoMock.On("MarkVersionPublished", defaultNamespace, "container", "20240102030405").Return(nil).Once()
+
+	err = s.svc.PublishVersion(s.ctx, defaultNamespace, "container", "20240102030405")
+	s.Require().NoError(err)
+
+	// pending BLOB is not uploaded
+	s.mdRepoMock.On("MarkVersionPublished", defaultNamespace, "container", "20240102030405").Return(metadata.ErrNotConfirmed).Once()
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(1), []models.Object{
+		{Key: "file.txt", Checksum: "checksum", Size: 10},
+	}, nil).Once()
+	s.blobRepoMock.On("BlobSize", "checksum").Return(uint64(0), blob.ErrNotFound).Once()
+	s.blobRepoMock.On("PutBlobURL", "checksum").Return("https://example.com", nil).Once()
+	s.mdRepoMock.On("MarkVersionPublished", defaultNamespace, "container", "20240102030405").Return(metadata.ErrNotConfirmed).Once()
+
+	err = s.svc.PublishVersion(s.ctx, defaultNamespace, "container", "20240102030405")
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotConfirmed, err)
 }
 
 func (s *serviceTestSuite) TestDeleteVersion() {
@@ -154,6 +232,28 @@ func (s *serviceTestSuite) TestDeleteVersion() {
 	s.Require().NoError(err)
 }
 
+func (s *serviceTestSuite) TestDeleteProtectedVersion() {
+	s.mdRepoMock.On("DeleteVersion", defaultNamespace, "test_container", "test_version").Return(metadata.ErrProtected).Once()
+
+	err := s.svc.DeleteVersion(s.ctx, defaultNamespace, "test_container", "test_version")
+	s.Require().Error(err)
+	s.Require().ErrorIs(err, ErrProtected)
+}
+
+func (s *serviceTestSuite) TestProtectVersion() {
+	s.mdRepoMock.On("SetVersionProtected", defaultNamespace, "test_container", "test_version", true).Return(nil).Once()
+
+	err := s.svc.ProtectVersion(s.ctx, defaultNamespace, "test_container", "test_version")
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestUnprotectVersion() {
+	s.mdRepoMock.On("SetVersionProtected", defaultNamespace, "test_container", "test_version", false).Return(nil).Once()
+
+	err := s.svc.UnprotectVersion(s.ctx, defaultNamespace, "test_container", "test_version")
+	s.Require().NoError(err)
+}
+
 func (s *serviceTestSuite) TestAddObject() {
 	s.mdRepoMock.On("CreateObject", defaultNamespace, "container", "versionID", "key", "cas_key").Return(nil).Once()
 
@@ -168,6 +268,151 @@ func (s *serviceTestSuite) TestAddObjectWithLeadingSlash() {
 	s.Require().NoError(err)
 }
 
+func (s *serviceTestSuite) TestCreateObjects() {
+	s.mdRepoMock.On("CreateObjects", defaultNamespace, "container", "versionID", []models.Object{
+		{Key: "key1", Checksum: "deadbeef", Size: 10, MimeType: "text/plain"},
+		{Key: "key2", Checksum: "cafebabe", Size: 20, MimeType: "text/plain"},
+	}).Return([]string{"cafebabe"}, nil).Once()
+	s.blobRepoMock.On("PutBlobURL", "cafebabe").Return("https://example.com/cafebabe", nil).Once()
+
+	urls, err := s.svc.CreateObjects(s.ctx, defaultNamespace, "container", "versionID", []models.Object{
+		{Key: "/key1", Checksum: "deadbeef", Size: 10, MimeType: "text/plain"},
+		{Key: "key2", Checksum: "cafebabe", Size: 20, MimeType: "text/plain"},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]string{
+		"cafebabe": "https://example.com/cafebabe",
+	}, urls)
+}
+
+func (s *serviceTestSuite) TestVerifyBLOBs() {
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(3), []models.Object{
+		{Key: "key1", Checksum: "deadbeef", Size: 10},
+		{Key: "key2", Checksum: "cafebabe", Size: 20},
+		{Key: "key3", Checksum: "cafebabe", Size: 20},
+		{Key: "key4", Checksum: "badc0ffee", Size: 30},
+	}, nil).Once()
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(10), nil).Once()
+	s.mdRepoMock.On("ConfirmBlob", "deadbeef", uint64(10)).Return(nil).Once()
+	s.blobRepoMock.On("BlobSize", "cafebabe").Return(uint64(0), blob.ErrNotFound).Once()
+	s.blobRepoMock.On("PutBlobURL", "cafebabe").Return("https://example.com/cafebabe", nil).Once()
+	s.blobRepoMock.On("BlobSize", "badc0ffee").Return(uint64(15), nil).Once()
+	s.blobRepoMock.On("PutBlobURL", "badc0ffee").Return("https://example.com/badc0ffee", nil).Once()
+
+	urls, err := s.svc.VerifyBLOBs(s.ctx, defaultNamespace, "container", "20240102030405")
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]string{
+		"cafebabe":  "https://example.com/cafebabe",
+		"badc0ffee": "https://example.com/badc0ffee",
+	}, urls)
+}
+
+func (s *serviceTestSuite) TestConfirmBLOB() {
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(10), nil).Once()
+	s.mdRepoMock.On("ConfirmBlob", "deadbeef", uint64(10)).Return(nil).Once()
+
+	err := s.svc.ConfirmBLOB(s.ctx, "deadbeef")
+	s.Require().NoError(err)
+
+	// BLOB data is not uploaded
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(0), blob.ErrNotFound).Once()
+
+	err = s.svc.ConfirmBLOB(s.ctx, "deadbeef")
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotFound, err)
+
+	// Uploaded BLOB size mismatch
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(5), nil).Once()
+	s.mdRepoMock.On("ConfirmBlob", "deadbeef", uint64(5)).Return(metadata.ErrConflict).Once()
+
+	err = s.svc.ConfirmBLOB(s.ctx, "deadbeef")
+	s.Require().Error(err)
+	s.Require().Equal(ErrConflict, err)
+}
+
+func (s *serviceTestSuite) TestListObjectsMultiplePages() {
+	page := []models.Object{}
+	for i := 0; i < 1000; i++ {
+		page = append(page, models.Object{Key: fmt.Sprintf("object%04d", i)})
+	}
+
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(1001), page, nil).Once()
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(1000), uint64(1000)).Return(uint64(1001), []models.Object{
+		{Key: "object1000"},
+	}, nil).Once()
+
+	objects, err := s.svc.ListObjects(s.ctx, defaultNamespace, "container", "20240102030405")
+	s.Require().NoError(err)
+	s.Require().Len(objects, 1001)
+	s.Require().Equal("object1000", objects[1000].Key)
+}
+
+func (s *serviceTestSuite) TestCreateMultipartUpload() {
+	s.mdRepoMock.On("EnsureBlobKey", "deadbeef", uint64(150<<20)).Return(nil).Once()
+	s.blobRepoMock.On("CreateMultipartUpload", "deadbeef").Return("upload-id", nil).Once()
+	s.blobRepoMock.On("PutBlobPartURL", "deadbeef", "upload-id", int32(1)).Return("https://example.com/part1", nil).Once()
+	s.blobRepoMock.On("PutBlobPartURL", "deadbeef", "upload-id", int32(2)).Return("https://example.com/part2", nil).Once()
+	s.blobRepoMock.On("PutBlobPartURL", "deadbeef", "upload-id", int32(3)).Return("https://example.com/part3", nil).Once()
+
+	upload, err := s.svc.CreateMultipartUpload(s.ctx, "deadbeef", 150<<20)
+	s.Require().NoError(err)
+	s.Require().Equal(models.MultipartUpload{
+		UploadID: "upload-id",
+		PartSize: 64 << 20,
+		PartURLs: []string{
+			"https://example.com/part1",
+			"https://example.com/part2",
+			"https://example.com/part3",
+		},
+	}, upload)
+}
+
+func (s *serviceTestSuite) TestCreateMultipartUploadNotSupported() {
+	s.mdRepoMock.On("EnsureBlobKey", "deadbeef", uint64(1234)).Return(nil).Once()
+	s.blobRepoMock.On("CreateMultipartUpload", "deadbeef").Return("", blob.ErrNotSupported).Once()
+
+	_, err := s.svc.CreateMultipartUpload(s.ctx, "deadbeef", 1234)
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotSupported, err)
+}
+
+func (s *serviceTestSuite) TestCreateMultipartUploadNotConfirmed() {
+	s.mdRepoMock.On("EnsureBlobKey", "deadbeef", uint64(1234)).Return(metadata.ErrNotConfirmed).Once()
+	s.blobRepoMock.On("CreateMultipartUpload", "deadbeef").Return("upload-id", nil).Once()
+	s.blobRepoMock.On("PutBlobPartURL", "deadbeef", "upload-id", int32(1)).Return("https://example.com/part1", nil).Once()
+
+	upload, err := s.svc.CreateMultipartUpload(s.ctx, "deadbeef", 1234)
+	s.Require().NoError(err)
+	s.Require().Equal("upload-id", upload.UploadID)
+}
+
+func (s *serviceTestSuite) TestCreateMultipartUploadNotFound() {
+	s.mdRepoMock.On("EnsureBlobKey", "deadbeef", uint64(1234)).Return(metadata.ErrNotFound).Once()
+
+	_, err := s.svc.CreateMultipartUpload(s.ctx, "deadbeef", 1234)
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotFound, err)
+}
+
+func (s *serviceTestSuite) TestCompleteMultipartUpload() {
+	s.blobRepoMock.On("CompleteMultipartUpload", "deadbeef", "upload-id", []models.UploadPart{
+		{Number: 1, ETag: "etag1"},
+		{Number: 2, ETag: "etag2"},
+	}).Return(nil).Once()
+	s.blobRepoMock.On("BlobSize", "deadbeef").Return(uint64(150<<20), nil).Once()
+	s.mdRepoMock.On("ConfirmBlob", "deadbeef", uint64(150<<20)).Return(nil).Once()
+
+	err := s.svc.CompleteMultipartUpload(s.ctx, "deadbeef", "upload-id", []models.UploadPart{
+		{Number: 1, ETag: "etag1"},
+		{Number: 2, ETag: "etag2"},
+	})
+	s.Require().NoError(err)
+
+	err = s.svc.CompleteMultipartUpload(s.ctx, "deadbeef", "upload-id", nil)
+	s.Require().Error(err)
+	s.Require().True(errors.Is(err, ErrInvalidArgument))
+}
+
 func (s *serviceTestSuite) TestDeleteObject() {
 	s.mdRepoMock.On("DeleteObject", defaultNamespace, "test-container", "test-version", []string{"test-key"}).Return(nil).Once()
 
@@ -245,38 +490,199 @@ func (s *serviceTestSuite) TestListPublishedVersionsByPage() {
 
 func (s *serviceTestSuite) TestListObjects() {
 	// Happy path
-	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "versionID", uint64(0), uint64(1000)).Return(uint64(100), []string{
-		"object1", "object2",
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(100), []models.Object{
+		{Key: "object1"}, {Key: "object2"},
 	}, nil).Once()
 
-	objects, err := s.svc.ListObjects(s.ctx, defaultNamespace, "container", "versionID")
+	objects, err := s.svc.ListObjects(s.ctx, defaultNamespace, "container", "20240102030405")
 	s.Require().NoError(err)
-	s.Require().Equal([]string{
-		"object1", "object2",
+	s.Require().Equal([]models.Object{
+		{Key: "object1"}, {Key: "object2"},
 	}, objects)
 
 	// return error
-	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "versionID", uint64(0), uint64(1000)).Return(uint64(100), []string(nil), errors.New("test error")).Once()
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(100), []models.Object(nil), errors.New("test error")).Once()
 
-	_, err = s.svc.ListObjects(s.ctx, defaultNamespace, "container", "versionID")
+	_, err = s.svc.ListObjects(s.ctx, defaultNamespace, "container", "20240102030405")
 	s.Require().Error(err)
 	s.Require().Equal("test error", err.Error())
 }
 
 func (s *serviceTestSuite) TestGetObjectURL() {
 	// Happy path
-	s.mdRepoMock.On("GetBlobByObject", defaultNamespace, "container", "versionID", "key").Return(models.Blob{
+	s.mdRepoMock.On("GetBlobByObject", defaultNamespace, "container", "20240102030405", "key").Return(models.Blob{
+		Checksum: "deadbeef",
+		Size:     1234,
+		MimeType: "application/json",
+	}, nil).Once()
+	s.blobRepoMock.On("GetBlobURL", "deadbeef", "application/json", "key").Return("url", nil).Once()
+
+	url, err := s.svc.GetObjectURL(s.ctx, defaultNamespace, "container", "20240102030405", "key")
+	s.Require().NoError(err)
+	s.Require().Equal("url", url)
+
+	// BLOB data is lost
+	s.mdRepoMock.On("GetBlobByObject", defaultNamespace, "container", "20240102030405", "key").Return(models.Blob{
 		Checksum: "deadbeef",
 		Size:     1234,
 		MimeType: "application/json",
+		IsBroken: true,
 	}, nil).Once()
+
+	_, err = s.svc.GetObjectURL(s.ctx, defaultNamespace, "container", "20240102030405", "key")
+	s.Require().Error(err)
+	s.Require().Equal(ErrBroken, err)
+}
+
+func (s *serviceTestSuite) TestGetBlobURL() {
 	s.blobRepoMock.On("GetBlobURL", "deadbeef", "application/json", "key").Return("url", nil).Once()
 
-	url, err := s.svc.GetObjectURL(s.ctx, defaultNamespace, "container", "versionID", "key")
+	url, err := s.svc.GetBlobURL(s.ctx, models.Blob{
+		Checksum: "deadbeef",
+		Size:     1234,
+		MimeType: "application/json",
+	}, "key")
 	s.Require().NoError(err)
 	s.Require().Equal("url", url)
 }
 
+func (s *serviceTestSuite) TestGetVersion() {
+	s.mdRepoMock.On("ResolveAlias", defaultNamespace, "container1", "stable").Return("20240102030405", nil).Once()
+	s.mdRepoMock.On("GetVersion", defaultNamespace, "container1", "20240102030405").Return(models.Version{
+		Name:        "20240102030405",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, nil).Once()
+
+	v, err := s.svc.GetVersion(s.ctx, defaultNamespace, "container1", "stable")
+	s.Require().NoError(err)
+	s.Require().Equal(models.Version{
+		Name:        "20240102030405",
+		IsPublished: true,
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, v)
+}
+
+func (s *serviceTestSuite) TestDiffVersions() {
+	s.mdRepoMock.On("GetLatestPublishedVersionByContainer", defaultNamespace, "container1").Return("20240102030406", nil).Once()
+	s.mdRepoMock.On("DiffVersions", defaultNamespace, "container1", "20240102030405", "20240102030406").Return(models.VersionDiff{
+		FromVersion: "20240102030405",
+		ToVersion:   "20240102030406",
+		Added: []models.Object{
+			{Key: "key1", Checksum: "deadbeef", Size: 10, MimeType: "text/plain"},
+		},
+		AddedBytes: 10,
+	}, nil).Once()
+
+	diff, err := s.svc.DiffVersions(s.ctx, defaultNamespace, "container1", "20240102030405", "latest")
+	s.Require().NoError(err)
+	s.Require().Equal("20240102030406", diff.ToVersion)
+	s.Require().Equal(uint64(10), diff.AddedBytes)
+	s.Require().Len(diff.Added, 1)
+
+	s.mdRepoMock.On("ResolveAlias", defaultNamespace, "container1", "stable").Return("", metadata.ErrNotFound).Once()
+
+	_, err = s.svc.DiffVersions(s.ctx, defaultNamespace, "container1", "stable", "20240102030405")
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotFound, err)
+}
+
+func (s *serviceTestSuite) TestGetObject() {
+	s.mdRepoMock.On("GetBlobByObject", defaultNamespace, "container", "20240102030405", "key").Return(models.Blob{
+		Checksum: "deadbeef",
+		Size:     9,
+		MimeType: "text/plain",
+	}, nil).Once()
+	s.blobRepoMock.On("GetBlob", "deadbeef", int64(0), int64(9)).Return(io.NopCloser(strings.NewReader("test data")), nil).Once()
+	s.blobRepoMock.On("GetBlob", "deadbeef", int64(5), int64(4)).Return(io.NopCloser(strings.NewReader("data")), nil).Once()
+
+	blob, rsc, err := s.svc.GetObject(s.ctx, defaultNamespace, "container", "20240102030405", "key")
+	s.Require().NoError(err)
+	defer func() { _ = rsc.Close() }()
+
+	s.Require().Equal("deadbeef", blob.Checksum)
+
+	size, err := rsc.Seek(0, io.SeekEnd)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(9), size)
+
+	_, err = rsc.Seek(0, io.SeekStart)
+	s.Require().NoError(err)
+
+	data, err := io.ReadAll(rsc)
+	s.Require().NoError(err)
+	s.Require().Equal("test data", string(data))
+
+	_, err = rsc.Seek(5, io.SeekStart)
+	s.Require().NoError(err)
+
+	data, err = io.ReadAll(rsc)
+	s.Require().NoError(err)
+	s.Require().Equal("data", string(data))
+}
+
+func (s *serviceTestSuite) TestExportVersion() {
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(2), []models.Object{
+		{Key: "dir/file1.txt", Checksum: "deadbeef", Size: 9, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Key: "empty.txt", Checksum: "e3b0c442", Size: 0, CreatedAt: time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC)},
+	}, nil).Once()
+	s.blobRepoMock.On("GetBlob", "deadbeef", int64(0), int64(9)).Return(io.NopCloser(strings.NewReader("test data")), nil).Once()
+
+	buf := &bytes.Buffer{}
+	w, err := archive.NewWriter(buf, archive.FormatTar)
+	s.Require().NoError(err)
+
+	err = s.svc.ExportVersion(s.ctx, defaultNamespace, "container", "20240102030405", w)
+	s.Require().NoError(err)
+	s.Require().NoError(w.Close())
+
+	tr := tar.NewReader(buf)
+
+	hdr, err := tr.Next()
+	s.Require().NoError(err)
+	s.Require().Equal("dir/file1.txt", hdr.Name)
+	s.Require().Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), hdr.ModTime.UTC())
+
+	data, err := io.ReadAll(tr)
+	s.Require().NoError(err)
+	s.Require().Equal("test data", string(data))
+
+	hdr, err = tr.Next()
+	s.Require().NoError(err)
+	s.Require().Equal("empty.txt", hdr.Name)
+	s.Require().Equal(int64(0), hdr.Size)
+
+	_, err = tr.Next()
+	s.Require().Equal(io.EOF, err)
+}
+
+func (s *serviceTestSuite) TestExportVersionBLOBError() {
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container", "20240102030405", uint64(0), uint64(1000)).Return(uint64(1), []models.Object{
+		{Key: "file1.txt", Checksum: "deadbeef", Size: 9},
+	}, nil).Once()
+	s.blobRepoMock.On("GetBlob", "deadbeef", int64(0), int64(9)).Return(nil, blob.ErrNotFound).Once()
+
+	w, err := archive.NewWriter(io.Discard, archive.FormatTar)
+	s.Require().NoError(err)
+
+	err = s.svc.ExportVersion(s.ctx, defaultNamespace, "container", "20240102030405", w)
+	s.Require().Error(err)
+	s.Require().Equal("error exporting object `file1.txt`: error writing data for `file1.txt`: error getting blob: blob not found", err.Error())
+}
+
+func (s *serviceTestSuite) TestGetObjectBroken() {
+	s.mdRepoMock.On("GetBlobByObject", defaultNamespace, "container", "20240102030405", "key").Return(models.Blob{
+		Checksum: "deadbeef",
+		Size:     9,
+		MimeType: "text/plain",
+		IsBroken: true,
+	}, nil).Once()
+
+	_, _, err := s.svc.GetObject(s.ctx, defaultNamespace, "container", "20240102030405", "key")
+	s.Require().Error(err)
+	s.Require().Equal(ErrBroken, err)
+}
+
 func (s *serviceTestSuite) TestEnsureBLOBPresenceOrGetUploadURL() {
 	// Blob exists
 	s.mdRepoMock.On("EnsureBlobKey", "checksum", uint64(1234)).Return(nil).Once()
@@ -293,21 +699,36 @@ func (s *serviceTestSuite) TestEnsureBLOBPresenceOrGetUploadURL() {
 	url, err = s.svc.EnsureBLOBPresenceOrGetUploadURL(s.ctx, "checksum", 1234, "application/x-rpm")
 	s.Require().NoError(err)
 	s.Require().Equal("https://example.com", url)
+
+	// Blob upload is not confirmed
+	s.mdRepoMock.On("EnsureBlobKey", "checksum", uint64(1234)).Return(metadata.ErrNotConfirmed).Once()
+	s.blobRepoMock.On("PutBlobURL", "checksum").Return("https://example.com", nil).Once()
+
+	url, err = s.svc.EnsureBLOBPresenceOrGetUploadURL(s.ctx, "checksum", 1234, "application/x-rpm")
+	s.Require().NoError(err)
+	s.Require().Equal("https://example.com", url)
+
+	// Blob is being deleted by GC
+	s.mdRepoMock.On("EnsureBlobKey", "checksum", uint64(1234)).Return(metadata.ErrDeleting).Once()
+
+	_, err = s.svc.EnsureBLOBPresenceOrGetUploadURL(s.ctx, "checksum", 1234, "application/x-rpm")
+	s.Require().Error(err)
+	s.Require().Equal(ErrDeleting, err)
 }
 
 func (s *serviceTestSuite) TestListObjectsByLatestVersion() {
 	s.mdRepoMock.On("GetLatestPublishedVersionByContainer", defaultNamespace, "container1").Return("versionID", nil).Once()
-	s.mdRepoMock.On("ListObjects", defaultNamespace, "container1", "versionID", uint64(0), uint64(50)).Return(uint64(100), []string{"obj1", "obj2"}, nil).Once()
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container1", "versionID", uint64(0), uint64(50)).Return(uint64(100), []models.Object{{Key: "obj1"}, {Key: "obj2"}}, nil).Once()
 
 	_, objects, err := s.svc.ListObjectsByPage(s.ctx, defaultNamespace, "container1", "latest", 1)
 	s.Require().NoError(err)
-	s.Require().Equal([]string{"obj1", "obj2"}, objects)
+	s.Require().Equal([]models.Object{{Key: "obj1"}, {Key: "obj2"}}, objects)
 }
 
 func (s *serviceTestSuite) TestListObjectsErrNotFound() {
-	s.mdRepoMock.On("ListObjects", defaultNamespace, "container1", "versionID", uint64(0), uint64(50)).Return(uint64(100), []string{"obj1", "obj2"}, metadata.ErrNotFound).Once()
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container1", "20240102030405", uint64(0), uint64(50)).Return(uint64(100), []models.Object{{Key: "obj1"}, {Key: "obj2"}}, metadata.ErrNotFound).Once()
 
-	_, _, err := s.svc.ListObjectsByPage(s.ctx, defaultNamespace, "container1", "versionID", 1)
+	_, _, err := s.svc.ListObjectsByPage(s.ctx, defaultNamespace, "container1", "20240102030405", 1)
 	s.Require().Error(err)
 	s.Require().Equal(ErrNotFound, err)
 }
@@ -326,6 +747,81 @@ func (s *serviceTestSuite) TestGetObjectURLWithLatestVersion() {
 	s.Require().Equal("url", url)
 }
 
+func (s *serviceTestSuite) TestListObjectsByAlias() {
+	s.mdRepoMock.On("ResolveAlias", defaultNamespace, "container1", "stable").Return("20240102030405", nil).Once()
+	s.mdRepoMock.On("ListObjects", defaultNamespace, "container1", "20240102030405", uint64(0), uint64(50)).Return(uint64(2), []models.Object{{Key: "obj1"}, {Key: "obj2"}}, nil).Once()
+
+	_, objects, err := s.svc.ListObjectsByPage(s.ctx, defaultNamespace, "container1", "stable", 1)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Object{{Key: "obj1"}, {Key: "obj2"}}, objects)
+}
+
+func (s *serviceTestSuite) TestGetObjectURLWithUnknownAlias() {
+	s.mdRepoMock.On("ResolveAlias", defaultNamespace, "container1", "stable").Return("", metadata.ErrNotFound).Once()
+
+	_, err := s.svc.GetObjectURL(s.ctx, defaultNamespace, "container1", "stable", "key")
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotFound, err)
+}
+
+func (s *serviceTestSuite) TestCreateAlias() {
+	s.mdRepoMock.On("CreateAlias", defaultNamespace, "container1", "stable", "20240102030405").Return(nil).Once()
+
+	err := s.svc.CreateAlias(s.ctx, defaultNamespace, "container1", "stable", "20240102030405")
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestCreateAliasToLatestVersion() {
+	s.mdRepoMock.On("GetLatestPublishedVersionByContainer", defaultNamespace, "container1").Return("20240102030405", nil).Once()
+	s.mdRepoMock.On("CreateAlias", defaultNamespace, "container1", "stable", "20240102030405").Return(nil).Once()
+
+	err := s.svc.CreateAlias(s.ctx, defaultNamespace, "container1", "stable", "latest")
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestCreateAliasInvalidName() {
+	for _, name := range []string{"latest", "20240102030405", "", "-stable", "sta/ble"} {
+		err := s.svc.CreateAlias(s.ctx, defaultNamespace, "container1", name, "20240102030405")
+		s.Require().Error(err, name)
+		s.Require().ErrorIs(err, ErrInvalidArgument, name)
+	}
+}
+
+func (s *serviceTestSuite) TestCreateAliasNotPublished() {
+	s.mdRepoMock.On("CreateAlias", defaultNamespace, "container1", "stable", "20240102030405").Return(metadata.ErrNotPublished).Once()
+
+	err := s.svc.CreateAlias(s.ctx, defaultNamespace, "container1", "stable", "20240102030405")
+	s.Require().Error(err)
+	s.Require().Equal(ErrNotPublished, err)
+}
+
+func (s *serviceTestSuite) TestMoveAlias() {
+	s.mdRepoMock.On("ResolveAlias", defaultNamespace, "container1", "testing").Return("20240102030405", nil).Once()
+	s.mdRepoMock.On("MoveAlias", defaultNamespace, "container1", "stable", "20240102030405").Return(nil).Once()
+
+	err := s.svc.MoveAlias(s.ctx, defaultNamespace, "container1", "stable", "testing")
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestDeleteAlias() {
+	s.mdRepoMock.On("DeleteAlias", defaultNamespace, "container1", "stable").Return(nil).Once()
+
+	err := s.svc.DeleteAlias(s.ctx, defaultNamespace, "container1", "stable")
+	s.Require().NoError(err)
+}
+
+func (s *serviceTestSuite) TestListAliases() {
+	s.mdRepoMock.On("ListAliases", defaultNamespace, "container1").Return([]models.Alias{
+		{Name: "stable", Version: "20240102030405"},
+	}, nil).Once()
+
+	aliases, err := s.svc.ListAliases(s.ctx, defaultNamespace, "container1")
+	s.Require().NoError(err)
+	s.Require().Equal([]models.Alias{
+		{Name: "stable", Version: "20240102030405"},
+	}, aliases)
+}
+
 // Definitions
 type serviceTestSuite struct {
 	suite.Suite